@@ -0,0 +1,164 @@
+// Package graphql provides a GraphQL client built on a retryable HTTP client,
+// which retries responses with throttling errors even though GraphQL APIs
+// return them with a successful status code.
+package graphql
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/cholland1989/go-retryable/pkg/retryable"
+)
+
+// DefaultRetryCodes contains the default error codes that are retryable.
+var DefaultRetryCodes = []string{"THROTTLED", "RATE_LIMITED"}
+
+// Client is a GraphQL client that retries transport errors, retryable status
+// codes, and throttling errors.
+type Client struct {
+	// Endpoint specifies the URL of the GraphQL endpoint.
+	Endpoint string
+
+	// Client specifies the retryable HTTP client. If the client is nil,
+	// [retryable.Default] is used.
+	Client *retryable.Client
+
+	// RetryCodes specifies the values of errors[].extensions.code that are
+	// retryable. If the retry codes are nil, [DefaultRetryCodes] is used.
+	RetryCodes []string
+}
+
+// Request defines a GraphQL request.
+type Request struct {
+	// Query specifies the GraphQL document.
+	Query string `json:"query"`
+
+	// Variables specifies the values of the variables of the query.
+	Variables map[string]any `json:"variables,omitempty"`
+
+	// OperationName specifies the operation to execute, if the query contains
+	// multiple operations.
+	OperationName string `json:"operationName,omitempty"`
+}
+
+// Error defines a GraphQL error.
+type Error struct {
+	// Message specifies the description of the error.
+	Message string `json:"message"`
+
+	// Path specifies the path of the field that caused the error.
+	Path []any `json:"path,omitempty"`
+
+	// Extensions specifies additional information, such as the error code.
+	Extensions map[string]any `json:"extensions,omitempty"`
+}
+
+// Code returns the value of extensions.code, if any.
+func (err Error) Code() string {
+	code, _ := err.Extensions["code"].(string)
+	return code
+}
+
+// Errors defines the errors of a GraphQL response.
+type Errors []Error
+
+// Error returns the messages of the errors.
+func (errs Errors) Error() string {
+	messages := make([]string, len(errs))
+	for index, err := range errs {
+		messages[index] = err.Message
+	}
+	return "graphql: " + strings.Join(messages, "; ")
+}
+
+// response defines a GraphQL response.
+type response struct {
+	// Data specifies the result of the operation.
+	Data json.RawMessage `json:"data"`
+
+	// Errors specifies the errors of the operation.
+	Errors Errors `json:"errors"`
+}
+
+// Do posts the request to the endpoint, retrying throttling errors with the
+// backoff of the retryable client, and decodes the data of the response into
+// the specified value. If the response contains errors that are not
+// retryable, they are returned as [Errors].
+func (client *Client) Do(ctx context.Context, request Request, data any) (err error) {
+	// Encode request
+	body, err := json.Marshal(request)
+	if err != nil {
+		return fmt.Errorf("%w: unable to encode request: %w", retryable.ErrNonRetryable, err)
+	}
+
+	// Send request with response check
+	base := client.client()
+	ctx = retryable.WithResponseCheck(ctx, client.check)
+	httpRequest, err := http.NewRequestWithContext(ctx, http.MethodPost, client.Endpoint, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("%w: unable to construct request: %w", retryable.ErrNonRetryable, err)
+	}
+	httpRequest.Header.Set("Content-Type", "application/json")
+	httpRequest.Header.Set("Accept", "application/json")
+	httpResponse, err := base.Do(httpRequest)
+	if err != nil {
+		return err
+	}
+	defer func(body io.Closer) {
+		_ = body.Close()
+	}(httpResponse.Body)
+
+	// Decode response
+	var result response
+	err = json.NewDecoder(httpResponse.Body).Decode(&result)
+	if err != nil {
+		return fmt.Errorf("%w: unable to decode response: %w", retryable.ErrNonRetryable, err)
+	}
+	if data != nil && len(result.Data) > 0 && string(result.Data) != "null" {
+		err = json.Unmarshal(result.Data, data)
+		if err != nil {
+			return fmt.Errorf("%w: unable to decode data: %w", retryable.ErrNonRetryable, err)
+		}
+	}
+	if len(result.Errors) > 0 {
+		return result.Errors
+	}
+	return nil
+}
+
+// client returns the retryable HTTP client.
+func (client *Client) client() *retryable.Client {
+	if client.Client == nil {
+		return retryable.Default()
+	}
+	return client.Client
+}
+
+// check returns a retryable error if the response contains a retryable error
+// code.
+func (client *Client) check(_ *http.Response, body []byte) (err error) {
+	// Decode errors, leaving invalid responses to the caller
+	var result response
+	if json.Unmarshal(body, &result) != nil {
+		return nil
+	}
+
+	// Check for retryable error code
+	codes := client.RetryCodes
+	if codes == nil {
+		codes = DefaultRetryCodes
+	}
+	for _, graphErr := range result.Errors {
+		for _, code := range codes {
+			if graphErr.Code() == code {
+				return fmt.Errorf("%w: %w", retryable.ErrRetryable, result.Errors)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,69 @@
+package graphql
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/cholland1989/go-retryable/pkg/retryable"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClient_Do(test *testing.T) {
+	test.Parallel()
+
+	var attempts atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		var body Request
+		_ = json.NewDecoder(request.Body).Decode(&body)
+		switch {
+		case body.Query == "{ invalid }":
+			_, _ = writer.Write([]byte(`{"data": null, "errors": [{"message": "syntax error", "extensions": {"code": "GRAPHQL_PARSE_FAILED"}}]}`))
+		case attempts.Add(1) < 3:
+			_, _ = writer.Write([]byte(`{"errors": [{"message": "throttled", "extensions": {"code": "THROTTLED"}}]}`))
+		default:
+			_, _ = writer.Write([]byte(`{"data": {"viewer": {"login": "octocat"}}}`))
+		}
+	}))
+	defer server.Close()
+
+	client := &Client{Endpoint: server.URL, Client: &retryable.Client{RetryCount: 5}}
+	var data struct {
+		Viewer struct {
+			Login string `json:"login"`
+		} `json:"viewer"`
+	}
+	err := client.Do(context.Background(), Request{Query: "{ viewer { login } }"}, &data)
+	require.NoError(test, err)
+	require.Equal(test, "octocat", data.Viewer.Login)
+	require.Equal(test, int32(3), attempts.Load())
+
+	err = client.Do(context.Background(), Request{Query: "{ invalid }"}, &data)
+	var errs Errors
+	require.ErrorAs(test, err, &errs)
+	require.Equal(test, "GRAPHQL_PARSE_FAILED", errs[0].Code())
+	require.EqualError(test, err, "graphql: syntax error")
+
+	attempts.Store(0)
+	client = &Client{Endpoint: server.URL, Client: &retryable.Client{RetryCount: 1}}
+	err = client.Do(context.Background(), Request{Query: "{ viewer { login } }"}, nil)
+	require.ErrorIs(test, err, retryable.ErrRetryable)
+	require.ErrorAs(test, err, &errs)
+	require.Equal(test, "THROTTLED", errs[0].Code())
+
+	client.RetryCodes = []string{}
+	attempts.Store(0)
+	err = client.Do(context.Background(), Request{Query: "{ viewer { login } }"}, nil)
+	require.ErrorAs(test, err, &errs)
+	require.Equal(test, int32(1), attempts.Load())
+
+	attempts.Store(0)
+	client.RetryCodes = nil
+	client.Client = &retryable.Client{RetryCount: 5}
+	err = client.Do(context.Background(), Request{Query: "{ viewer { login } }"}, nil)
+	require.NoError(test, err)
+	require.Equal(test, int32(3), attempts.Load())
+}
@@ -0,0 +1,157 @@
+// Package presets provides retryable HTTP clients tuned for well-known APIs,
+// with appropriate retryable status codes, rate limit headers, and backoff
+// limits.
+package presets
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/cholland1989/go-retryable/pkg/retryable"
+	"github.com/cholland1989/go-retryable/pkg/unofficial"
+)
+
+// GitHub returns a client for the GitHub REST and GraphQL APIs, which waits
+// for the rate limit to reset when the X-RateLimit-Remaining header is zero.
+// Since GitHub responds to exceeded rate limits with 403 Forbidden, such
+// responses are retried if they are rate limited, but other 403 Forbidden
+// responses are not.
+func GitHub() *retryable.Client {
+	client := newClient()
+	client.RetryStatus = []int{
+		http.StatusTooManyRequests,
+		http.StatusInternalServerError,
+		http.StatusBadGateway,
+		http.StatusServiceUnavailable,
+		http.StatusGatewayTimeout,
+	}
+	client.RetryCount = 5
+	client.RetryDelay = time.Second
+	client.RetryMultiplier = 2.0
+	client.MaxRetryDelay = time.Minute
+	client.RetryDelayParsers = []retryable.RetryDelayParser{
+		retryable.ParseRateLimitReset("X-RateLimit-Remaining", "X-RateLimit-Reset"),
+	}
+	client.AttemptMiddleware = []retryable.Middleware{retryRateLimitedForbidden}
+	return client
+}
+
+// retryRateLimitedForbidden is attempt middleware that treats 403 Forbidden
+// responses as retryable if the X-RateLimit-Remaining header is zero or the
+// Retry-After header is present.
+func retryRateLimitedForbidden(next retryable.Doer) retryable.Doer {
+	return retryable.DoerFunc(func(request *http.Request) (*http.Response, error) {
+		response, err := next.Do(request)
+		if response == nil || response.StatusCode != http.StatusForbidden || !errors.Is(err, retryable.ErrNonRetryable) {
+			return response, err
+		}
+		if response.Header.Get("X-RateLimit-Remaining") != "0" && response.Header.Get("Retry-After") == "" {
+			return response, err
+		}
+		return response, fmt.Errorf("%w: rate limit exceeded (%d)", retryable.ErrRetryable, response.StatusCode)
+	})
+}
+
+// AWS returns a client for AWS service APIs, following the standard retry
+// mode of the AWS SDKs with three attempts and a maximum backoff of twenty
+// seconds.
+func AWS() *retryable.Client {
+	client := newClient()
+	client.RetryStatus = []int{
+		http.StatusTooManyRequests,
+		http.StatusInternalServerError,
+		http.StatusBadGateway,
+		http.StatusServiceUnavailable,
+		http.StatusGatewayTimeout,
+		unofficial.StatusBandwidthLimitExceeded,
+	}
+	client.RetryCount = 2
+	client.RetryDelay = 500 * time.Millisecond
+	client.RetryMultiplier = 2.0
+	client.RetryJitter = 1.0
+	client.MaxRetryDelay = 20 * time.Second
+	return client
+}
+
+// Cloudflare returns a client for the Cloudflare API, and for origins behind
+// Cloudflare, which retries Cloudflare-specific status codes and respects the
+// RateLimit-Reset header.
+func Cloudflare() *retryable.Client {
+	client := newClient()
+	client.RetryStatus = []int{
+		http.StatusTooManyRequests,
+		http.StatusInternalServerError,
+		http.StatusBadGateway,
+		http.StatusServiceUnavailable,
+		http.StatusGatewayTimeout,
+		unofficial.StatusWebServerReturnedAnUnknownError,
+		unofficial.StatusWebServerIsDown,
+		unofficial.StatusConnectionTimedOut,
+		unofficial.StatusOriginIsUnreachable,
+		unofficial.StatusTimeoutOccurred,
+		unofficial.StatusRailgunError,
+		unofficial.StatusCloudflareError,
+	}
+	client.RetryCount = 5
+	client.RetryDelay = time.Second
+	client.RetryMultiplier = 2.0
+	client.MaxRetryDelay = 30 * time.Second
+	client.RetryDelayParsers = []retryable.RetryDelayParser{
+		retryable.ParseRateLimitSeconds("RateLimit-Reset"),
+	}
+	return client
+}
+
+// Stripe returns a client for the Stripe API, which sends an Idempotency-Key
+// header with each request so that retried requests are not duplicated.
+func Stripe() *retryable.Client {
+	client := newClient()
+	client.RetryStatus = []int{
+		http.StatusConflict,
+		http.StatusTooManyRequests,
+		http.StatusInternalServerError,
+		http.StatusServiceUnavailable,
+	}
+	client.RetryCount = 2
+	client.RetryDelay = 250 * time.Millisecond
+	client.RetryMultiplier = 2.0
+	client.MaxRetryDelay = 2 * time.Second
+	client.AttemptHeaders.IdempotencyKey = true
+	return client
+}
+
+// Lean returns a client for proxies and high-throughput fan-out services,
+// which streams successful responses instead of reading them into memory,
+// omits stack traces from recovered panics, does not record host status, and
+// applies no fixed request delay.
+func Lean() *retryable.Client {
+	client := newClient()
+	client.RetryStatus = retryable.DefaultStatus
+	client.RetryCount = 3
+	client.RetryDelay = 100 * time.Millisecond
+	client.RetryMultiplier = 2.0
+	client.MaxRetryDelay = 5 * time.Second
+	client.RequestDelay = 0
+	client.RequestJitter = 0
+	client.StreamResponse = true
+	client.OmitStackTraces = true
+	client.DisableHostStatus = true
+	return client
+}
+
+// newClient returns a client with the timeouts, delays, and size limits of
+// the default client.
+func newClient() *retryable.Client {
+	return &retryable.Client{
+		Client:         http.Client{},
+		RetryJitter:    0.5,
+		RetryTimeout:   60 * time.Minute,
+		RequestDelay:   10 * time.Millisecond,
+		RequestJitter:  0.5,
+		RequestTimeout: 5 * time.Minute,
+		RequestSize:    2 * 1024 * 1024 * 1024,
+		ResponseSize:   2 * 1024 * 1024 * 1024,
+	}
+}
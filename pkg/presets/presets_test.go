@@ -0,0 +1,132 @@
+package presets
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/cholland1989/go-retryable/pkg/retryable"
+	"github.com/cholland1989/go-retryable/pkg/retrytest"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPresets(test *testing.T) {
+	test.Parallel()
+
+	for name, params := range map[string]struct {
+		client *retryable.Client
+		status int
+	}{
+		"GitHub":     {GitHub(), http.StatusTooManyRequests},
+		"AWS":        {AWS(), http.StatusServiceUnavailable},
+		"Cloudflare": {Cloudflare(), 522},
+		"Stripe":     {Stripe(), http.StatusConflict},
+		"Lean":       {Lean(), http.StatusServiceUnavailable},
+	} {
+		params := params
+		test.Run(name, func(test *testing.T) {
+			test.Parallel()
+
+			require.Contains(test, params.client.RetryStatus, params.status)
+			require.Positive(test, params.client.RetryCount)
+			require.Positive(test, params.client.MaxRetryDelay)
+			for _, delay := range params.client.Schedule(params.client.RetryCount) {
+				require.LessOrEqual(test, delay, params.client.MaxRetryDelay)
+			}
+
+			transport := retrytest.NewTransport(retrytest.Fail(params.status))
+			params.client.Transport = transport
+			params.client.RetryDelay = time.Millisecond
+			params.client.RequestDelay = 0
+			response, err := params.client.Get("https://example.com/")
+			require.NoError(test, err)
+			require.Equal(test, http.StatusOK, response.StatusCode)
+			require.True(test, transport.AssertAttempts(test, 2))
+		})
+	}
+}
+
+func TestGitHub(test *testing.T) {
+	test.Parallel()
+
+	reset := time.Now().Add(time.Hour).Unix()
+	response := &http.Response{Header: http.Header{
+		"X-Ratelimit-Remaining": {"0"},
+		"X-Ratelimit-Reset":     {strconv.FormatInt(reset, 10)},
+	}}
+	delay := GitHub().RetryDelayParsers[0](response, time.Unix(reset, 0).Add(-time.Minute))
+	require.Equal(test, time.Minute, delay)
+
+	// Retry rate limited 403 Forbidden responses only
+	limited := retrytest.Fail(http.StatusForbidden)
+	limited.Header = http.Header{
+		"X-Ratelimit-Remaining": {"0"},
+		"X-Ratelimit-Reset":     {strconv.FormatInt(time.Now().Add(-time.Minute).Unix(), 10)},
+	}
+	transport := retrytest.NewTransport(retrytest.RetryAfter(http.StatusForbidden, 0), limited, retrytest.Succeed(nil))
+	client := GitHub()
+	client.Transport = transport
+	client.RetryDelay = time.Millisecond
+	client.RequestDelay = 0
+	_, err := client.Get("https://api.github.com/user")
+	require.NoError(test, err)
+	require.Len(test, transport.Attempts(), 3)
+
+	transport = retrytest.NewTransport(retrytest.Fail(http.StatusForbidden))
+	client.Transport = transport
+	_, err = client.Get("https://api.github.com/user")
+	require.ErrorIs(test, err, retryable.ErrNonRetryable)
+	require.Len(test, transport.Attempts(), 1)
+}
+
+func TestStripe(test *testing.T) {
+	test.Parallel()
+
+	transport := retrytest.NewTransport(retrytest.Fail(http.StatusConflict))
+	client := Stripe()
+	client.Transport = transport
+	client.RetryDelay = time.Millisecond
+	_, err := client.Post("https://api.stripe.com/v1/charges", "application/x-www-form-urlencoded", nil)
+	require.NoError(test, err)
+
+	attempts := transport.Attempts()
+	require.Len(test, attempts, 2)
+	require.NotEmpty(test, attempts[0].Header.Get("Idempotency-Key"))
+	require.Equal(test, attempts[0].Header.Get("Idempotency-Key"), attempts[1].Header.Get("Idempotency-Key"))
+}
+
+func BenchmarkClient(bench *testing.B) {
+	body := bytes.Repeat([]byte("x"), 4096)
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, _ *http.Request) {
+		_, _ = writer.Write(body)
+	}))
+	defer server.Close()
+
+	defaultClient, err := retryable.FromConfig(retryable.DefaultConfig())
+	require.NoError(bench, err)
+	defaultClient.RequestDelay = 0
+	for name, client := range map[string]interface {
+		Get(url string) (*http.Response, error)
+	}{
+		"NetHTTP": new(http.Client),
+		"Default": defaultClient,
+		"Lean":    Lean(),
+	} {
+		client := client
+		bench.Run(name, func(bench *testing.B) {
+			bench.ReportAllocs()
+			for index := 0; index < bench.N; index++ {
+				response, err := client.Get(server.URL)
+				if err != nil {
+					bench.Fatal(err)
+				}
+				_, _ = io.Copy(io.Discard, response.Body)
+				_ = response.Body.Close()
+			}
+		})
+	}
+}
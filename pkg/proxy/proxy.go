@@ -0,0 +1,188 @@
+// Package proxy provides a reverse proxy that sends upstream requests with a
+// retryable HTTP client, so that retries and backoff can be placed in front
+// of flaky origins.
+package proxy
+
+import (
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/cholland1989/go-retryable/pkg/retryable"
+)
+
+// hopHeaders contains the hop-by-hop headers, which are removed when proxying
+// requests and responses (RFC 9110 section 7.6.1).
+var hopHeaders = []string{
+	"Connection",
+	"Keep-Alive",
+	"Proxy-Authenticate",
+	"Proxy-Authorization",
+	"Proxy-Connection",
+	"Te",
+	"Trailer",
+	"Transfer-Encoding",
+	"Upgrade",
+}
+
+// ReverseProxy is an [net/http.Handler] that forwards requests to an upstream
+// origin with a retryable HTTP client, and copies the upstream response back
+// to the client. Responses with error status codes that are not retried, such
+// as 404, are passed through unchanged.
+type ReverseProxy struct {
+	// Target specifies the URL of the upstream origin. The path of the
+	// request is appended to the path of the target, and the query of the
+	// target is merged with the query of the request.
+	Target *url.URL
+
+	// Client specifies the retryable HTTP client used for upstream requests.
+	// If the client is nil, [retryable.Default] is used. Request bodies
+	// are buffered so that they can be resent; set StreamResponse on the
+	// client to stream response bodies.
+	Client *retryable.Client
+
+	// Rewrite specifies an optional function that modifies the upstream
+	// request before it is sent, such as to add credentials.
+	Rewrite func(request *http.Request)
+
+	// ErrorHandler specifies an optional function that handles upstream
+	// requests that failed without a response. If the error handler is nil,
+	// a 502 Bad Gateway response is written.
+	ErrorHandler func(writer http.ResponseWriter, request *http.Request, err error)
+}
+
+// NewSingleHostReverseProxy returns a reverse proxy that forwards requests to
+// the specified target with [retryable.Default].
+func NewSingleHostReverseProxy(target *url.URL) *ReverseProxy {
+	return &ReverseProxy{Target: target}
+}
+
+// ServeHTTP forwards the request to the upstream origin, retrying failed
+// attempts, and writes the upstream response.
+func (proxy *ReverseProxy) ServeHTTP(writer http.ResponseWriter, request *http.Request) {
+	// Construct upstream request
+	outbound := proxy.outboundRequest(request)
+	if proxy.Rewrite != nil {
+		proxy.Rewrite(outbound)
+	}
+
+	// Send upstream request, passing through responses with error status codes
+	response, err := proxy.client().Do(outbound)
+	if response == nil || response.Body == nil {
+		proxy.handleError(writer, request, err)
+		return
+	}
+	defer func(body io.Closer) {
+		_ = body.Close()
+	}(response.Body)
+
+	// Copy upstream response
+	removeHopHeaders(response.Header)
+	for name, values := range response.Header {
+		for _, value := range values {
+			writer.Header().Add(name, value)
+		}
+	}
+	writer.WriteHeader(response.StatusCode)
+	_, err = io.Copy(writer, response.Body)
+	if err != nil {
+		return
+	}
+	for name, values := range response.Trailer {
+		for _, value := range values {
+			writer.Header().Add(http.TrailerPrefix+name, value)
+		}
+	}
+}
+
+// client returns the retryable HTTP client.
+func (proxy *ReverseProxy) client() *retryable.Client {
+	if proxy.Client == nil {
+		return retryable.Default()
+	}
+	return proxy.Client
+}
+
+// outboundRequest returns a copy of the inbound request addressed to the
+// upstream origin, without hop-by-hop headers and with forwarding headers.
+func (proxy *ReverseProxy) outboundRequest(request *http.Request) (outbound *http.Request) {
+	// Address request to target
+	outbound = request.Clone(request.Context())
+	outbound.RequestURI = ""
+	outbound.Host = ""
+	outbound.URL.Scheme = proxy.Target.Scheme
+	outbound.URL.Host = proxy.Target.Host
+	outbound.URL.Path, outbound.URL.RawPath = joinPath(proxy.Target, request.URL)
+	if proxy.Target.RawQuery == "" || request.URL.RawQuery == "" {
+		outbound.URL.RawQuery = proxy.Target.RawQuery + request.URL.RawQuery
+	} else {
+		outbound.URL.RawQuery = proxy.Target.RawQuery + "&" + request.URL.RawQuery
+	}
+	if request.ContentLength == 0 {
+		outbound.Body = http.NoBody
+	}
+
+	// Remove hop-by-hop headers
+	removeHopHeaders(outbound.Header)
+
+	// Add forwarding headers
+	if host, _, err := net.SplitHostPort(request.RemoteAddr); err == nil {
+		if prior := outbound.Header.Values("X-Forwarded-For"); len(prior) > 0 {
+			host = strings.Join(prior, ", ") + ", " + host
+		}
+		outbound.Header.Set("X-Forwarded-For", host)
+	}
+	outbound.Header.Set("X-Forwarded-Host", request.Host)
+	if request.TLS != nil {
+		outbound.Header.Set("X-Forwarded-Proto", "https")
+	} else {
+		outbound.Header.Set("X-Forwarded-Proto", "http")
+	}
+	return outbound
+}
+
+// handleError handles an upstream request that failed without a response.
+func (proxy *ReverseProxy) handleError(writer http.ResponseWriter, request *http.Request, err error) {
+	if proxy.ErrorHandler != nil {
+		proxy.ErrorHandler(writer, request, err)
+		return
+	}
+	writer.WriteHeader(http.StatusBadGateway)
+}
+
+// joinPath appends the path of the request to the path of the target,
+// joining them with a single slash.
+func joinPath(target *url.URL, request *url.URL) (path string, rawPath string) {
+	if target.RawPath == "" && request.RawPath == "" {
+		return joinSlash(target.Path, request.Path), ""
+	}
+	return joinSlash(target.Path, request.Path), joinSlash(target.EscapedPath(), request.EscapedPath())
+}
+
+// joinSlash joins the two paths with a single slash.
+func joinSlash(first string, second string) string {
+	switch {
+	case strings.HasSuffix(first, "/") && strings.HasPrefix(second, "/"):
+		return first + second[1:]
+	case !strings.HasSuffix(first, "/") && !strings.HasPrefix(second, "/") && second != "":
+		return first + "/" + second
+	}
+	return first + second
+}
+
+// removeHopHeaders removes the hop-by-hop headers, including the headers
+// listed in the Connection header.
+func removeHopHeaders(header http.Header) {
+	for _, value := range header.Values("Connection") {
+		for _, name := range strings.Split(value, ",") {
+			if name = strings.TrimSpace(name); name != "" {
+				header.Del(name)
+			}
+		}
+	}
+	for _, name := range hopHeaders {
+		header.Del(name)
+	}
+}
@@ -0,0 +1,110 @@
+package proxy
+
+import (
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/cholland1989/go-retryable/pkg/retryable"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReverseProxy(test *testing.T) {
+	test.Parallel()
+
+	var attempts atomic.Int32
+	origin := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		body, _ := io.ReadAll(request.Body)
+		switch {
+		case request.URL.Path == "/api/missing":
+			writer.WriteHeader(http.StatusNotFound)
+			_, _ = writer.Write([]byte("not found"))
+		case attempts.Add(1) == 1:
+			writer.WriteHeader(http.StatusServiceUnavailable)
+		default:
+			writer.Header().Set("Connection", "X-Hop")
+			writer.Header().Set("X-Hop", "removed")
+			writer.Header().Set("X-Origin", request.Header.Get("X-Forwarded-For")+"|"+request.Header.Get("X-Custom"))
+			_, _ = writer.Write([]byte(request.Method + " " + request.URL.RequestURI() + " " + string(body)))
+		}
+	}))
+	defer origin.Close()
+	target, err := url.Parse(origin.URL + "/api?key=value")
+	require.NoError(test, err)
+
+	proxy := NewSingleHostReverseProxy(target)
+	proxy.Client = &retryable.Client{RetryCount: 2, RetryStatus: []int{http.StatusServiceUnavailable}}
+	proxy.Rewrite = func(request *http.Request) {
+		request.Header.Set("X-Custom", "rewritten")
+	}
+	server := httptest.NewServer(proxy)
+	defer server.Close()
+
+	response, err := http.Post(server.URL+"/items?page=2", "text/plain", strings.NewReader("payload"))
+	require.NoError(test, err)
+	body, err := io.ReadAll(response.Body)
+	require.NoError(test, err)
+	require.NoError(test, response.Body.Close())
+	require.Equal(test, http.StatusOK, response.StatusCode)
+	require.Equal(test, "POST /api/items?key=value&page=2 payload", string(body))
+	require.Equal(test, "127.0.0.1|rewritten", response.Header.Get("X-Origin"))
+	require.Empty(test, response.Header.Get("X-Hop"))
+	require.Equal(test, int32(2), attempts.Load())
+
+	response, err = http.Get(server.URL + "/missing")
+	require.NoError(test, err)
+	body, err = io.ReadAll(response.Body)
+	require.NoError(test, err)
+	require.NoError(test, response.Body.Close())
+	require.Equal(test, http.StatusNotFound, response.StatusCode)
+	require.Equal(test, "not found", string(body))
+}
+
+func TestReverseProxy_ErrorHandler(test *testing.T) {
+	test.Parallel()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(test, err)
+	target, err := url.Parse("http://" + listener.Addr().String())
+	require.NoError(test, err)
+	require.NoError(test, listener.Close())
+
+	proxy := NewSingleHostReverseProxy(target)
+	proxy.Client = new(retryable.Client)
+	recorder := httptest.NewRecorder()
+	proxy.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/", nil))
+	require.Equal(test, http.StatusBadGateway, recorder.Code)
+
+	var handled error
+	proxy.ErrorHandler = func(writer http.ResponseWriter, request *http.Request, err error) {
+		handled = err
+		writer.WriteHeader(http.StatusServiceUnavailable)
+	}
+	recorder = httptest.NewRecorder()
+	proxy.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/", nil))
+	require.Equal(test, http.StatusServiceUnavailable, recorder.Code)
+	require.True(test, errors.Is(handled, retryable.ErrRetryable))
+}
+
+func TestJoinSlash(test *testing.T) {
+	test.Parallel()
+
+	require.Equal(test, "/a/b", joinSlash("/a/", "/b"))
+	require.Equal(test, "/a/b", joinSlash("/a", "b"))
+	require.Equal(test, "/a/b", joinSlash("/a", "/b"))
+	require.Equal(test, "/a", joinSlash("/a", ""))
+}
+
+func TestRemoveHopHeaders(test *testing.T) {
+	test.Parallel()
+
+	header := http.Header{"Connection": {"X-One, X-Two"}, "X-One": {"1"}, "X-Two": {"2"}, "Upgrade": {"h2c"}, "X-Keep": {"3"}}
+	removeHopHeaders(header)
+	require.Equal(test, http.Header{"X-Keep": {"3"}}, header)
+}
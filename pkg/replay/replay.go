@@ -0,0 +1,322 @@
+// Package replay replays recorded production traffic against alternative
+// retry policies in virtual time, so that retry tuning decisions can be made
+// from data instead of guesswork.
+package replay
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cholland1989/go-retryable/pkg/retryable"
+)
+
+// ErrFailed defines the error returned for recorded attempts that failed
+// without a response, such as connection failures.
+var ErrFailed = errors.New("replay: recorded attempt failed")
+
+// Entry defines a recorded attempt and its real outcome.
+type Entry struct {
+	// Time specifies when the attempt started.
+	Time time.Time
+
+	// Method specifies the request method.
+	Method string
+
+	// URL specifies the request URL.
+	URL string
+
+	// StatusCode specifies the status code of the response, or zero if the
+	// attempt failed without a response.
+	StatusCode int
+
+	// Header specifies the response headers, such as Retry-After.
+	Header http.Header
+
+	// Duration specifies how long the attempt took.
+	Duration time.Duration
+}
+
+// Report contains the outcome of replaying recorded traffic with a policy.
+type Report struct {
+	// Requests specifies the number of replayed requests.
+	Requests int
+
+	// Successes specifies the number of requests that eventually succeeded.
+	Successes int
+
+	// Attempts specifies the number of attempts sent upstream, including
+	// retries, which measures the upstream load.
+	Attempts int
+
+	// Latency specifies the total latency of the requests in virtual time,
+	// including retry delays.
+	Latency time.Duration
+
+	// AddedLatency specifies the total latency added by retries, compared to
+	// the recorded duration of the first attempt of each request.
+	AddedLatency time.Duration
+}
+
+// SuccessRate returns the fraction of requests that eventually succeeded.
+func (report Report) SuccessRate() float64 {
+	if report.Requests == 0 {
+		return 0
+	}
+	return float64(report.Successes) / float64(report.Requests)
+}
+
+// LoadFactor returns the average number of upstream attempts per request.
+func (report Report) LoadFactor() float64 {
+	if report.Requests == 0 {
+		return 0
+	}
+	return float64(report.Attempts) / float64(report.Requests)
+}
+
+// MeanAddedLatency returns the average latency added by retries per request.
+func (report Report) MeanAddedLatency() time.Duration {
+	if report.Requests == 0 {
+		return 0
+	}
+	return report.AddedLatency / time.Duration(report.Requests)
+}
+
+// Replay replays each entry as a request with the specified policy, where the
+// outcome of each attempt is the most recently recorded outcome for the host
+// at that point in virtual time. Delays and timeouts elapse in virtual time,
+// so replaying a day of traffic takes milliseconds. Random jitter uses a
+// fixed seed, so that replays of the same entries are reproducible.
+func Replay(entries []Entry, policy retryable.Policy) (report Report) {
+	// Index recorded outcomes by host in chronological order
+	timeline := newTimeline(entries)
+	source := rand.NewSource(1)
+
+	// Replay each entry as a request
+	for _, entry := range entries {
+		clock := &virtualClock{now: entry.Time}
+		if policy.RetryTimeout > 0 {
+			clock.deadline = entry.Time.Add(policy.RetryTimeout)
+		}
+		transport := &transport{timeline: timeline, clock: clock, timeout: policy.RequestTimeout}
+		client := &retryable.Client{Clock: clock, DisableHostStatus: true, RandSource: source}
+		client.Transport = transport
+		client.UpdatePolicy(replayPolicy(policy))
+
+		// Send request in virtual time
+		request, err := http.NewRequest(entry.Method, entry.URL, nil)
+		if err != nil {
+			continue
+		}
+		response, err := client.Do(request)
+		if err == nil {
+			_ = response.Body.Close()
+			report.Successes++
+		}
+		report.Requests++
+		report.Attempts += transport.attempts
+		latency := clock.Now().Sub(entry.Time)
+		report.Latency += latency
+		if latency > entry.Duration {
+			report.AddedLatency += latency - entry.Duration
+		}
+	}
+	return report
+}
+
+// replayPolicy returns the policy with the timeouts removed, since they are
+// enforced in virtual time instead.
+func replayPolicy(policy retryable.Policy) retryable.Policy {
+	policy.RetryTimeout = 0
+	policy.RequestTimeout = 0
+	return policy
+}
+
+// timeline contains the recorded outcomes per host in chronological order.
+type timeline map[string][]Entry
+
+// newTimeline returns the recorded outcomes per host in chronological order.
+func newTimeline(entries []Entry) timeline {
+	result := make(timeline)
+	for _, entry := range entries {
+		host := entryHost(entry.URL)
+		result[host] = append(result[host], entry)
+	}
+	for _, outcomes := range result {
+		sort.SliceStable(outcomes, func(i int, j int) bool {
+			return outcomes[i].Time.Before(outcomes[j].Time)
+		})
+	}
+	return result
+}
+
+// outcome returns the most recently recorded outcome for the host at the
+// specified time, or the earliest outcome if none was recorded before it.
+func (timeline timeline) outcome(host string, now time.Time) (entry Entry, ok bool) {
+	outcomes := timeline[host]
+	if len(outcomes) == 0 {
+		return entry, false
+	}
+	index := sort.Search(len(outcomes), func(index int) bool {
+		return outcomes[index].Time.After(now)
+	})
+	if index == 0 {
+		return outcomes[0], true
+	}
+	return outcomes[index-1], true
+}
+
+// entryHost returns the host of the URL, or the URL if it cannot be parsed.
+func entryHost(rawURL string) string {
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return rawURL
+	}
+	return parsed.Host
+}
+
+// virtualClock is a [retryable.Clock] that advances instantly when sleeping,
+// and fails sleeps that would exceed the retry timeout.
+type virtualClock struct {
+	mutex    sync.Mutex
+	now      time.Time
+	deadline time.Time
+}
+
+// Now returns the current virtual time.
+func (clock *virtualClock) Now() time.Time {
+	clock.mutex.Lock()
+	defer clock.mutex.Unlock()
+	return clock.now
+}
+
+// Sleep advances the virtual time, returning an error if the retry timeout
+// elapses first.
+func (clock *virtualClock) Sleep(_ context.Context, duration time.Duration) (err error) {
+	return clock.advance(duration)
+}
+
+// advance advances the virtual time, returning an error if the retry timeout
+// elapses first.
+func (clock *virtualClock) advance(duration time.Duration) (err error) {
+	clock.mutex.Lock()
+	defer clock.mutex.Unlock()
+	if !clock.deadline.IsZero() && clock.now.Add(duration).After(clock.deadline) {
+		clock.now = clock.deadline
+		return context.DeadlineExceeded
+	}
+	clock.now = clock.now.Add(duration)
+	return nil
+}
+
+// transport is an [net/http.RoundTripper] that responds with the recorded
+// outcomes in virtual time.
+type transport struct {
+	timeline timeline
+	clock    *virtualClock
+	timeout  time.Duration
+	attempts int
+}
+
+// RoundTrip responds with the recorded outcome for the host of the request at
+// the current virtual time, advancing the virtual time by its duration.
+func (transport *transport) RoundTrip(request *http.Request) (response *http.Response, err error) {
+	// Look up recorded outcome
+	transport.attempts++
+	entry, ok := transport.timeline.outcome(request.URL.Host, transport.clock.Now())
+	if !ok {
+		return nil, fmt.Errorf("%w: no outcome for host (%s)", ErrFailed, request.URL.Host)
+	}
+
+	// Advance virtual time, enforcing timeouts
+	if transport.timeout > 0 && entry.Duration > transport.timeout {
+		err = transport.clock.advance(transport.timeout)
+		if err != nil {
+			return nil, err
+		}
+		return nil, context.DeadlineExceeded
+	}
+	err = transport.clock.advance(entry.Duration)
+	if err != nil {
+		return nil, err
+	}
+
+	// Respond with recorded outcome
+	if entry.StatusCode == 0 {
+		return nil, ErrFailed
+	}
+	header := entry.Header.Clone()
+	if header == nil {
+		header = make(http.Header)
+	}
+	return &http.Response{
+		Status:     strconv.Itoa(entry.StatusCode) + " " + http.StatusText(entry.StatusCode),
+		StatusCode: entry.StatusCode,
+		Proto:      "HTTP/1.1",
+		ProtoMajor: 1,
+		ProtoMinor: 1,
+		Header:     header,
+		Body:       io.NopCloser(strings.NewReader("")),
+		Request:    request,
+	}, nil
+}
+
+// har defines the subset of the HTTP Archive format used for replay.
+type har struct {
+	Log struct {
+		Entries []struct {
+			StartedDateTime time.Time `json:"startedDateTime"`
+			Time            float64   `json:"time"`
+			Request         struct {
+				Method string `json:"method"`
+				URL    string `json:"url"`
+			} `json:"request"`
+			Response struct {
+				Status  int `json:"status"`
+				Headers []struct {
+					Name  string `json:"name"`
+					Value string `json:"value"`
+				} `json:"headers"`
+			} `json:"response"`
+		} `json:"entries"`
+	} `json:"log"`
+}
+
+// LoadHAR returns the entries of an HTTP Archive (HAR), such as one exported
+// from a browser or a proxy. Entries with a zero status code are treated as
+// attempts that failed without a response.
+func LoadHAR(reader io.Reader) (entries []Entry, err error) {
+	// Decode archive
+	var archive har
+	err = json.NewDecoder(reader).Decode(&archive)
+	if err != nil {
+		return nil, fmt.Errorf("replay: unable to decode HAR: %w", err)
+	}
+
+	// Convert entries
+	for _, recorded := range archive.Log.Entries {
+		header := make(http.Header)
+		for _, field := range recorded.Response.Headers {
+			header.Add(field.Name, field.Value)
+		}
+		entries = append(entries, Entry{
+			Time:       recorded.StartedDateTime,
+			Method:     recorded.Request.Method,
+			URL:        recorded.Request.URL,
+			StatusCode: recorded.Response.Status,
+			Header:     header,
+			Duration:   time.Duration(recorded.Time * float64(time.Millisecond)),
+		})
+	}
+	return entries, nil
+}
@@ -0,0 +1,92 @@
+package replay
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cholland1989/go-retryable/pkg/retryable"
+	"github.com/stretchr/testify/require"
+)
+
+func entries() []Entry {
+	start := time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC)
+	return []Entry{
+		{Time: start, Method: http.MethodGet, URL: "https://api.example.com/a", StatusCode: http.StatusServiceUnavailable, Duration: 100 * time.Millisecond},
+		{Time: start.Add(2 * time.Second), Method: http.MethodGet, URL: "https://api.example.com/b", StatusCode: http.StatusOK, Duration: 100 * time.Millisecond},
+	}
+}
+
+func TestReplay(test *testing.T) {
+	test.Parallel()
+
+	report := Replay(entries(), retryable.Policy{RetryStatus: []int{http.StatusServiceUnavailable}})
+	require.Equal(test, Report{Requests: 2, Successes: 1, Attempts: 2, Latency: 200 * time.Millisecond}, report)
+	require.Equal(test, 0.5, report.SuccessRate())
+	require.Equal(test, 1.0, report.LoadFactor())
+
+	policy := retryable.Policy{
+		RetryStatus:     []int{http.StatusServiceUnavailable},
+		RetryCount:      5,
+		RetryDelay:      time.Second,
+		RetryMultiplier: 1,
+	}
+	report = Replay(entries(), policy)
+	require.Equal(test, 2, report.Successes)
+	require.Equal(test, 4, report.Attempts)
+	require.Equal(test, 2400*time.Millisecond, report.Latency)
+	require.Equal(test, 2200*time.Millisecond, report.AddedLatency)
+	require.Equal(test, 1100*time.Millisecond, report.MeanAddedLatency())
+
+	policy.RetryTimeout = 1500 * time.Millisecond
+	report = Replay(entries(), policy)
+	require.Equal(test, 1, report.Successes)
+	require.Equal(test, 3, report.Attempts)
+
+	policy.RetryTimeout = 0
+	policy.RequestTimeout = 50 * time.Millisecond
+	report = Replay(entries(), policy)
+	require.Equal(test, 0, report.Successes)
+	require.Equal(test, 2, report.Attempts)
+	require.Equal(test, 100*time.Millisecond, report.Latency)
+}
+
+func TestReport(test *testing.T) {
+	test.Parallel()
+
+	var report Report
+	require.Zero(test, report.SuccessRate())
+	require.Zero(test, report.LoadFactor())
+	require.Zero(test, report.MeanAddedLatency())
+}
+
+func TestLoadHAR(test *testing.T) {
+	test.Parallel()
+
+	archive := `{"log": {"entries": [
+		{"startedDateTime": "2024-01-01T00:00:00Z", "time": 12.5,
+		 "request": {"method": "GET", "url": "https://api.example.com/a"},
+		 "response": {"status": 429, "headers": [{"name": "Retry-After", "value": "3"}]}},
+		{"startedDateTime": "2024-01-01T00:00:05Z", "time": 30000,
+		 "request": {"method": "POST", "url": "https://api.example.com/b"},
+		 "response": {"status": 0, "headers": [], "_error": "net::ERR_CONNECTION_RESET"}}
+	]}}`
+	loaded, err := LoadHAR(strings.NewReader(archive))
+	require.NoError(test, err)
+	require.Len(test, loaded, 2)
+	require.Equal(test, time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC), loaded[0].Time)
+	require.Equal(test, 12500*time.Microsecond, loaded[0].Duration)
+	require.Equal(test, "3", loaded[0].Header.Get("Retry-After"))
+	require.Equal(test, http.MethodPost, loaded[1].Method)
+	require.Zero(test, loaded[1].StatusCode)
+
+	report := Replay(loaded, retryable.Policy{RetryStatus: []int{http.StatusTooManyRequests}, RetryCount: 1})
+	require.Equal(test, 2, report.Requests)
+	require.Zero(test, report.Successes)
+	require.Equal(test, 4, report.Attempts)
+	require.Equal(test, 63025*time.Millisecond, report.Latency)
+
+	_, err = LoadHAR(strings.NewReader("invalid"))
+	require.Error(test, err)
+}
@@ -0,0 +1,106 @@
+package retryable
+
+import (
+	"time"
+)
+
+// defaultAdaptiveLimit is the maximum adaptive base delay used when neither
+// the adaptive limit nor the maximum retry delay is specified.
+const defaultAdaptiveLimit = time.Minute
+
+// defaultAdaptiveMinimum is the adaptive base delay after a retryable failure
+// while the base delay is zero, if the adaptive minimum is not specified.
+const defaultAdaptiveMinimum = 100 * time.Millisecond
+
+// AdaptiveBackoff specifies an adaptive base delay that is shared by all
+// requests sent by a client, similar to the congestion control of TCP. The
+// base delay starts at the retry delay, grows multiplicatively after each
+// retryable failure, and shrinks additively after each success, but never
+// falls below the retry delay. If the base delay is zero, such as when the
+// retry delay is zero, a retryable failure sets it to the minimum instead.
+// The exponential backoff of each request is calculated from the adaptive
+// base delay instead of the retry delay.
+type AdaptiveBackoff struct {
+	// Increase specifies the multiplier applied to the base delay after each
+	// retryable failure. If the increase is not greater than one, the base
+	// delay is not adapted.
+	Increase float64
+
+	// Decrease specifies the duration subtracted from the base delay after
+	// each successful request.
+	Decrease time.Duration
+
+	// Limit specifies the maximum base delay. If the limit is zero, the
+	// maximum retry delay is used, or one minute if that is also zero.
+	Limit time.Duration
+
+	// Minimum specifies the base delay after a retryable failure while the
+	// base delay is zero. If the minimum is zero, 100 milliseconds is used.
+	Minimum time.Duration
+}
+
+// baseDelay returns the current base delay for exponential backoff.
+func (client *Client) baseDelay() time.Duration {
+	// Check for adaptive backoff
+	if client.AdaptiveBackoff.Increase <= 1.0 {
+		return client.RetryDelay
+	}
+
+	// Apply lower bound to adaptive base delay
+	state := client.state()
+	state.mutex.Lock()
+	defer state.mutex.Unlock()
+	if state.adaptive < client.RetryDelay {
+		return client.RetryDelay
+	}
+	return state.adaptive
+}
+
+// adaptBackoff adapts the base delay after a successful request or a
+// retryable failure.
+func (client *Client) adaptBackoff(success bool) {
+	// Check for adaptive backoff
+	adaptive := client.AdaptiveBackoff
+	if adaptive.Increase <= 1.0 {
+		return
+	}
+
+	// Determine upper bound
+	limit := adaptive.Limit
+	if limit <= 0 {
+		limit = client.MaxRetryDelay
+	}
+	if limit <= 0 {
+		limit = defaultAdaptiveLimit
+	}
+
+	// Apply additive decrease or multiplicative increase
+	state := client.state()
+	state.mutex.Lock()
+	defer state.mutex.Unlock()
+	base := state.adaptive
+	if base < client.RetryDelay {
+		base = client.RetryDelay
+	}
+	switch {
+	case success && base > adaptive.Decrease:
+		base -= adaptive.Decrease
+	case success:
+		base = 0
+	case base <= 0 && adaptive.Minimum > 0:
+		base = adaptive.Minimum
+	case base <= 0:
+		base = defaultAdaptiveMinimum
+	default:
+		base = time.Duration(float64(base) * adaptive.Increase)
+	}
+
+	// Apply bounds
+	if base > limit || base < 0 {
+		base = limit
+	}
+	if base < client.RetryDelay {
+		base = client.RetryDelay
+	}
+	state.adaptive = base
+}
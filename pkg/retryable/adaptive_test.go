@@ -0,0 +1,96 @@
+package retryable
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestClient_AdaptBackoff(test *testing.T) {
+	test.Parallel()
+
+	client := new(Client)
+	client.RetryCount = 1
+	client.RetryDelay = time.Second
+	client.adaptBackoff(false)
+	require.Equal(test, time.Second, client.baseDelay())
+
+	client.AdaptiveBackoff = AdaptiveBackoff{Increase: 2.0, Decrease: 500 * time.Millisecond, Limit: 5 * time.Second}
+	client.adaptBackoff(false)
+	require.Equal(test, 2*time.Second, client.baseDelay())
+	client.adaptBackoff(false)
+	require.Equal(test, 4*time.Second, client.baseDelay())
+	client.adaptBackoff(false)
+	require.Equal(test, 5*time.Second, client.baseDelay())
+	require.Equal(test, []time.Duration{5 * time.Second}, client.Schedule(1))
+
+	client.adaptBackoff(true)
+	require.Equal(test, 4500*time.Millisecond, client.baseDelay())
+	for index := 0; index < 10; index++ {
+		client.adaptBackoff(true)
+	}
+	require.Equal(test, time.Second, client.baseDelay())
+
+	client.AdaptiveBackoff.Limit = 0
+	client.MaxRetryDelay = 3 * time.Second
+	for index := 0; index < 10; index++ {
+		client.adaptBackoff(false)
+	}
+	require.Equal(test, 3*time.Second, client.baseDelay())
+
+	client.MaxRetryDelay = 0
+	for index := 0; index < 10; index++ {
+		client.adaptBackoff(false)
+	}
+	require.Equal(test, defaultAdaptiveLimit, client.baseDelay())
+}
+
+func TestClient_AdaptBackoff_ZeroRetryDelay(test *testing.T) {
+	test.Parallel()
+
+	client := new(Client)
+	client.AdaptiveBackoff = AdaptiveBackoff{Increase: 2.0, Decrease: time.Second}
+	require.Zero(test, client.baseDelay())
+	client.adaptBackoff(false)
+	require.Equal(test, defaultAdaptiveMinimum, client.baseDelay())
+	client.adaptBackoff(false)
+	require.Equal(test, 2*defaultAdaptiveMinimum, client.baseDelay())
+	client.adaptBackoff(true)
+	require.Zero(test, client.baseDelay())
+
+	client.AdaptiveBackoff.Minimum = time.Second
+	client.adaptBackoff(false)
+	require.Equal(test, time.Second, client.baseDelay())
+	client.adaptBackoff(false)
+	require.Equal(test, 2*time.Second, client.baseDelay())
+}
+
+func TestClient_AdaptiveBackoff(test *testing.T) {
+	test.Parallel()
+
+	var attempts atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, _ *http.Request) {
+		if attempts.Add(1)%2 == 1 {
+			writer.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer server.Close()
+
+	clock := &MockClock{now: time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC)}
+	client := new(Client)
+	client.Clock = clock
+	client.RetryCount = 1
+	client.RetryDelay = time.Second
+	client.RetryStatus = []int{http.StatusServiceUnavailable}
+	client.AdaptiveBackoff = AdaptiveBackoff{Increase: 2.0, Decrease: 500 * time.Millisecond}
+	_, err := client.Get(server.URL)
+	require.NoError(test, err)
+	_, err = client.Get(server.URL)
+	require.NoError(test, err)
+	require.Equal(test, []time.Duration{0, 2 * time.Second, 0, 0, 3 * time.Second, 0}, clock.sleeps)
+	require.Equal(test, 2500*time.Millisecond, client.baseDelay())
+}
@@ -0,0 +1,307 @@
+package retryable
+
+import (
+	"context"
+	"crypto/tls"
+	"net"
+	"net/http"
+	"net/http/httptrace"
+	"net/textproto"
+	"sync"
+	"time"
+)
+
+// Attempt describes a single attempt of a request.
+type Attempt struct {
+	// Number specifies the attempt number, starting from zero.
+	Number int
+
+	// Start specifies when the attempt started.
+	Start time.Time
+
+	// RetryReason specifies why the previous attempt failed, such as
+	// "status 503" or "timeout", or the empty string for the first attempt.
+	RetryReason string
+
+	// Deadline specifies when the retry timeout of the request expires, or
+	// zero if the request has no deadline.
+	Deadline time.Time
+
+	// Duration specifies how long the attempt took, including reading the
+	// response body.
+	Duration time.Duration
+
+	// StatusCode specifies the status code of the response, or zero if no
+	// response was received.
+	StatusCode int
+
+	// Err specifies the error of the attempt, if any.
+	Err error
+
+	// Endpoint specifies the redacted URL of the endpoint selected for the
+	// attempt, if any.
+	Endpoint string
+
+	// SRV specifies the DNS SRV record of the endpoint, if the endpoint was
+	// selected by an [SRVEndpointSelector].
+	SRV *net.SRV
+
+	// Proxy specifies the redacted URL of the proxy selected for the attempt,
+	// if any.
+	Proxy string
+
+	// Downgraded specifies whether the attempt was sent over HTTP/1.1 after
+	// repeated HTTP/2 failures.
+	Downgraded bool
+
+	// Reused specifies whether the attempt reused a pooled connection. It is
+	// only recorded if the attempt hook, fallback addresses, or free retries
+	// are specified.
+	Reused bool
+
+	// ContentLanguage specifies the Content-Language header of the response,
+	// if any.
+	ContentLanguage string
+
+	// Vary specifies the header names of the Vary header of the response, if
+	// any.
+	Vary []string
+
+	// FallbackAddress specifies the static fallback address that the attempt
+	// connected to because the hostname could not be resolved, if any.
+	FallbackAddress string
+
+	// Timings specifies where the time of the attempt was spent. It is only
+	// recorded if the attempt hook, fallback addresses, or free retries are
+	// specified.
+	Timings Timings
+}
+
+// Timings contains the timing breakdown of an attempt. Phases that did not
+// occur, such as DNS resolution on a reused connection, are zero.
+type Timings struct {
+	// DNS specifies how long resolving the hostname took.
+	DNS time.Duration
+
+	// Connect specifies how long establishing the TCP connection took.
+	Connect time.Duration
+
+	// TLS specifies how long the TLS handshake took.
+	TLS time.Duration
+
+	// TimeToFirstByte specifies the time from writing the request to
+	// receiving the first byte of the response, which approximates the
+	// processing time of the server.
+	TimeToFirstByte time.Duration
+}
+
+// attemptKey is the context key for the attempt recorder.
+type attemptKey struct{}
+
+// attemptRecorder records the metadata of an attempt, which may be updated
+// by transport hooks on other goroutines.
+type attemptRecorder struct {
+	// mutex guards access to the attempt and the time the response was
+	// received.
+	mutex sync.Mutex
+
+	// attempt contains the metadata of the attempt.
+	attempt Attempt
+
+	// received contains the time the response of the attempt was received.
+	received time.Time
+}
+
+// startAttempt returns a copy of the context with a new attempt recorder for
+// the specified attempt number, and the reason the previous attempt failed.
+func (client *Client) startAttempt(ctx context.Context, attempt int, reason string) context.Context {
+	recorder := &attemptRecorder{attempt: Attempt{Number: attempt, RetryReason: reason, Start: client.clock().Now()}}
+	recorder.attempt.Deadline, _ = ctx.Deadline()
+	stats := &client.state().stats
+	stats.attempts.Add(1)
+	if reason != "" {
+		stats.retries.Add(1)
+	}
+	return context.WithValue(ctx, attemptKey{}, recorder)
+}
+
+// AttemptFromContext returns the metadata of the attempt that the context
+// belongs to, such as in a transport, a request hook, or a nested client, and
+// reports whether the context belongs to an attempt. The metadata reflects
+// the attempt so far, so the outcome of the attempt is not yet known.
+func AttemptFromContext(ctx context.Context) (attempt Attempt, ok bool) {
+	recorder := attemptRecorderFrom(ctx)
+	if recorder == nil {
+		return attempt, false
+	}
+	return recorder.snapshot(), true
+}
+
+// finishAttempt records the outcome of the attempt in the context, and passes
+// the metadata of the attempt to the attempt hook, if specified.
+func (client *Client) finishAttempt(ctx context.Context, response *http.Response, err error) {
+	// Check for attempt recorder
+	recorder := attemptRecorderFrom(ctx)
+	if recorder == nil {
+		return
+	}
+
+	// Record outcome
+	stats := &client.state().stats
+	if err != nil {
+		stats.failures.Add(1)
+	}
+	recorder.update(func(attempt *Attempt) {
+		attempt.Duration = client.clock().Now().Sub(attempt.Start)
+		stats.observeDuration(attempt.Duration)
+		attempt.Err = err
+		if response != nil {
+			attempt.StatusCode = response.StatusCode
+			attempt.ContentLanguage = response.Header.Get("Content-Language")
+			attempt.Vary = parseVary(response.Header)
+		}
+	})
+
+	// Notify hook
+	if client.OnAttempt != nil {
+		client.OnAttempt(recorder.snapshot())
+	}
+}
+
+// attemptRecorderFrom returns the attempt recorder from the context, or nil
+// if the context does not contain an attempt recorder.
+func attemptRecorderFrom(ctx context.Context) *attemptRecorder {
+	if ctx == nil {
+		return nil
+	}
+	recorder, _ := ctx.Value(attemptKey{}).(*attemptRecorder)
+	return recorder
+}
+
+// attemptNumber returns the attempt number from the context, or zero if the
+// context does not contain an attempt.
+func attemptNumber(ctx context.Context) int {
+	recorder := attemptRecorderFrom(ctx)
+	if recorder == nil {
+		return 0
+	}
+	return recorder.snapshot().Number
+}
+
+// attemptReason returns the reason the previous attempt failed from the
+// context, or the empty string if the context does not contain an attempt.
+func attemptReason(ctx context.Context) string {
+	recorder := attemptRecorderFrom(ctx)
+	if recorder == nil {
+		return ""
+	}
+	return recorder.snapshot().RetryReason
+}
+
+// update modifies the attempt while holding the lock.
+func (recorder *attemptRecorder) update(modify func(attempt *Attempt)) {
+	recorder.mutex.Lock()
+	defer recorder.mutex.Unlock()
+	modify(&recorder.attempt)
+}
+
+// snapshot returns a copy of the attempt.
+func (recorder *attemptRecorder) snapshot() (attempt Attempt) {
+	recorder.mutex.Lock()
+	defer recorder.mutex.Unlock()
+	return recorder.attempt
+}
+
+// traceConnection returns a copy of the context that records in the attempt
+// whether the connection of the attempt was reused, whether it is to a static
+// fallback address, and the timing breakdown of the attempt. Hooks of an
+// [net/http/httptrace.ClientTrace] already in the context still fire for each
+// attempt.
+func (client *Client) traceConnection(ctx context.Context) context.Context {
+	// Check for consumers of connection metadata
+	recorder := attemptRecorderFrom(ctx)
+	if recorder == nil || (client.OnAttempt == nil && len(client.FallbackAddresses) == 0 && client.FreeRetries <= 0) {
+		return ctx
+	}
+
+	// Inspect connection and timings of attempt
+	var dnsStart, connectStart, tlsStart, wroteRequest time.Time
+	elapsed := func(start time.Time) time.Duration {
+		if start.IsZero() {
+			return 0
+		}
+		return client.clock().Now().Sub(start)
+	}
+	return httptrace.WithClientTrace(ctx, &httptrace.ClientTrace{
+		DNSStart: func(httptrace.DNSStartInfo) {
+			recorder.update(func(*Attempt) { dnsStart = client.clock().Now() })
+		},
+		DNSDone: func(httptrace.DNSDoneInfo) {
+			recorder.update(func(attempt *Attempt) { attempt.Timings.DNS = elapsed(dnsStart) })
+		},
+		ConnectStart: func(string, string) {
+			recorder.update(func(*Attempt) { connectStart = client.clock().Now() })
+		},
+		ConnectDone: func(string, string, error) {
+			recorder.update(func(attempt *Attempt) { attempt.Timings.Connect = elapsed(connectStart) })
+		},
+		TLSHandshakeStart: func() {
+			recorder.update(func(*Attempt) { tlsStart = client.clock().Now() })
+		},
+		TLSHandshakeDone: func(tls.ConnectionState, error) {
+			recorder.update(func(attempt *Attempt) { attempt.Timings.TLS = elapsed(tlsStart) })
+		},
+		WroteRequest: func(httptrace.WroteRequestInfo) {
+			recorder.update(func(*Attempt) { wroteRequest = client.clock().Now() })
+		},
+		GotFirstResponseByte: func() {
+			recorder.update(func(attempt *Attempt) { attempt.Timings.TimeToFirstByte = elapsed(wroteRequest) })
+		},
+		GotConn: func(info httptrace.GotConnInfo) {
+			conn := info.Conn
+			if tlsConn, ok := conn.(*tls.Conn); ok {
+				conn = tlsConn.NetConn()
+			}
+			fallback, _ := conn.(*fallbackConn)
+			recorder.update(func(attempt *Attempt) {
+				attempt.Reused = info.Reused
+				if fallback != nil {
+					attempt.FallbackAddress = fallback.RemoteAddr().String()
+				}
+			})
+		},
+	})
+}
+
+// traceInformational returns a copy of the context that passes informational
+// responses of the attempt to the informational hook, if specified.
+func (client *Client) traceInformational(ctx context.Context) context.Context {
+	// Check for informational hook
+	if client.OnInformational == nil {
+		return ctx
+	}
+
+	// Pass informational responses to hook
+	return httptrace.WithClientTrace(ctx, &httptrace.ClientTrace{
+		Got1xxResponse: func(code int, header textproto.MIMEHeader) error {
+			client.OnInformational(code, http.Header(header))
+			return nil
+		},
+	})
+}
+
+// limitNestedRetries limits the retry count of the client to the nested retry
+// count, and disables free retries, if the request is sent from within an
+// attempt of another client. The client must be a snapshot.
+func (client *Client) limitNestedRetries(request *http.Request) {
+	// Check for nested request
+	if !client.LimitNestedRetries || request == nil || attemptRecorderFrom(request.Context()) == nil {
+		return
+	}
+
+	// Limit retries
+	if client.RetryCount > client.NestedRetryCount {
+		client.RetryCount = client.NestedRetryCount
+	}
+	client.FreeRetries = 0
+}
@@ -0,0 +1,192 @@
+package retryable
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/http/httptrace"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestClient_OnAttempt(test *testing.T) {
+	test.Parallel()
+
+	var count atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		if count.Add(1) == 1 {
+			writer.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer server.Close()
+
+	var attempts []Attempt
+	client := new(Client)
+	client.Clock = &MockClock{now: time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC)}
+	client.RetryCount = 1
+	client.RetryStatus = []int{http.StatusServiceUnavailable}
+	client.OnAttempt = func(attempt Attempt) {
+		attempts = append(attempts, attempt)
+	}
+	response, err := client.Get(server.URL)
+	require.NoError(test, err)
+	require.NoError(test, response.Body.Close())
+	require.Len(test, attempts, 2)
+	require.Equal(test, 0, attempts[0].Number)
+	require.Equal(test, http.StatusServiceUnavailable, attempts[0].StatusCode)
+	require.ErrorIs(test, attempts[0].Err, ErrRetryable)
+	require.Equal(test, 1, attempts[1].Number)
+	require.Equal(test, http.StatusOK, attempts[1].StatusCode)
+	require.NoError(test, attempts[1].Err)
+	require.Empty(test, attempts[1].FallbackAddress)
+}
+
+func TestClient_AttemptTimings(test *testing.T) {
+	test.Parallel()
+
+	var count atomic.Int32
+	server := httptest.NewTLSServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		if count.Add(1) == 1 {
+			writer.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer server.Close()
+
+	var attempts []Attempt
+	client := new(Client)
+	client.RetryCount = 1
+	client.RetryStatus = []int{http.StatusServiceUnavailable}
+	client.Transport = server.Client().Transport
+	client.OnAttempt = func(attempt Attempt) {
+		attempts = append(attempts, attempt)
+	}
+
+	var connections atomic.Int32
+	ctx := httptrace.WithClientTrace(context.Background(), &httptrace.ClientTrace{
+		GotConn: func(httptrace.GotConnInfo) {
+			connections.Add(1)
+		},
+	})
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, server.URL, nil)
+	require.NoError(test, err)
+	response, err := client.Do(request)
+	require.NoError(test, err)
+	require.NoError(test, response.Body.Close())
+	require.Equal(test, int32(2), connections.Load())
+	require.Len(test, attempts, 2)
+	require.Positive(test, attempts[0].Timings.Connect)
+	require.Positive(test, attempts[0].Timings.TLS)
+	require.Positive(test, attempts[0].Timings.TimeToFirstByte)
+	require.True(test, attempts[1].Reused)
+	require.Zero(test, attempts[1].Timings.TLS)
+	require.Positive(test, attempts[1].Timings.TimeToFirstByte)
+}
+
+func TestClient_OnInformational(test *testing.T) {
+	test.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		writer.Header().Set("Link", "</style.css>; rel=preload")
+		writer.WriteHeader(http.StatusEarlyHints)
+		writer.Header().Del("Link")
+		_, _ = writer.Write([]byte("final"))
+	}))
+	defer server.Close()
+
+	var codes []int
+	var links []string
+	client := new(Client)
+	client.OnInformational = func(code int, header http.Header) {
+		codes = append(codes, code)
+		links = append(links, header.Get("Link"))
+	}
+	response, err := client.Get(server.URL)
+	require.NoError(test, err)
+	require.NoError(test, response.Body.Close())
+	require.Equal(test, http.StatusOK, response.StatusCode)
+	require.Equal(test, []int{http.StatusEarlyHints}, codes)
+	require.Equal(test, []string{"</style.css>; rel=preload"}, links)
+}
+
+func TestAttemptNumber(test *testing.T) {
+	test.Parallel()
+
+	client := new(Client)
+	require.Equal(test, 0, attemptNumber(context.Background()))
+	require.Equal(test, 3, attemptNumber(client.startAttempt(context.Background(), 3, "")))
+	require.Nil(test, attemptRecorderFrom(context.Background()))
+	client.finishAttempt(context.Background(), nil, nil)
+}
+
+type MockTransport func(request *http.Request) (*http.Response, error)
+
+func (transport MockTransport) RoundTrip(request *http.Request) (*http.Response, error) {
+	return transport(request)
+}
+
+func TestAttemptFromContext(test *testing.T) {
+	test.Parallel()
+
+	_, ok := AttemptFromContext(context.Background())
+	require.False(test, ok)
+
+	var numbers []int
+	client := new(Client)
+	client.RetryCount = 2
+	client.RetryStatus = []int{http.StatusServiceUnavailable}
+	client.RetryTimeout = time.Minute
+	client.Transport = MockTransport(func(request *http.Request) (*http.Response, error) {
+		attempt, ok := AttemptFromContext(request.Context())
+		require.True(test, ok)
+		require.WithinDuration(test, time.Now().Add(time.Minute), attempt.Deadline, 10*time.Second)
+		numbers = append(numbers, attempt.Number)
+		status := http.StatusServiceUnavailable
+		if attempt.Number == 2 {
+			status = http.StatusOK
+		}
+		return &http.Response{StatusCode: status, Body: http.NoBody, Header: make(http.Header), Request: request}, nil
+	})
+	response, err := client.Get("http://example.invalid/")
+	require.NoError(test, err)
+	require.NoError(test, response.Body.Close())
+	require.Equal(test, []int{0, 1, 2}, numbers)
+}
+
+func TestClient_LimitNestedRetries(test *testing.T) {
+	test.Parallel()
+
+	var count atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		count.Add(1)
+		writer.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	inner := new(Client)
+	inner.RetryCount = 5
+	inner.RetryStatus = []int{http.StatusServiceUnavailable}
+	inner.LimitNestedRetries = true
+	inner.NestedRetryCount = 1
+	outer := new(Client)
+	outer.RetryCount = 2
+	outer.RetryStatus = []int{http.StatusServiceUnavailable}
+	outer.Transport = MockTransport(func(request *http.Request) (*http.Response, error) {
+		nested, err := http.NewRequestWithContext(request.Context(), http.MethodGet, server.URL, nil)
+		require.NoError(test, err)
+		response, _ := inner.Do(nested)
+		return response, nil
+	})
+	response, err := outer.Get(server.URL)
+	require.ErrorIs(test, err, ErrRetryable)
+	require.NoError(test, response.Body.Close())
+	require.Equal(test, int32(6), count.Load())
+
+	count.Store(0)
+	response, err = inner.Get(server.URL)
+	require.ErrorIs(test, err, ErrRetryable)
+	require.NoError(test, response.Body.Close())
+	require.Equal(test, int32(6), count.Load())
+}
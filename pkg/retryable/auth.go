@@ -0,0 +1,156 @@
+package retryable
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+)
+
+// Authenticator defines credentials that are added to each attempt, and that
+// can be refreshed when they are rejected by the server.
+type Authenticator interface {
+	// Apply adds the current credentials to the request.
+	Apply(request *http.Request) (err error)
+
+	// Refresh obtains new credentials after the server rejected the current
+	// credentials.
+	Refresh(ctx context.Context) (err error)
+}
+
+// BearerAuthenticator is an [Authenticator] that adds an OAuth2 bearer token
+// to the Authorization header, and obtains a new token when the current token
+// is rejected. Concurrent refreshes share a single call to the token function,
+// and a rejection of a token that was already replaced does not obtain another
+// token.
+type BearerAuthenticator struct {
+	// Token specifies the function that obtains a new token, such as from an
+	// OAuth2 token endpoint.
+	Token func(ctx context.Context) (token string, err error)
+
+	// mutex guards access to the current token and the refresh in progress.
+	mutex sync.Mutex
+
+	// token contains the current token.
+	token string
+
+	// refreshing contains the refresh in progress, if any.
+	refreshing *tokenRefresh
+}
+
+// tokenRefresh contains the outcome of a token refresh that is shared with
+// concurrent refreshes.
+type tokenRefresh struct {
+	// done is closed when the refresh finishes.
+	done chan struct{}
+
+	// err contains the error of the refresh, if any.
+	err error
+}
+
+// Apply adds the current token to the request, obtaining a token if required.
+func (auth *BearerAuthenticator) Apply(request *http.Request) (err error) {
+	auth.mutex.Lock()
+	defer auth.mutex.Unlock()
+
+	// Obtain initial token
+	if auth.token == "" {
+		auth.token, err = auth.Token(request.Context())
+		if err != nil {
+			return err
+		}
+	}
+
+	// Add token to request
+	request.Header.Set("Authorization", "Bearer "+auth.token)
+	return nil
+}
+
+// Refresh obtains a new token, unless the rejected token was already replaced,
+// or waits for the refresh in progress, if any.
+func (auth *BearerAuthenticator) Refresh(ctx context.Context) (err error) {
+	// Check for replaced token or refresh in progress
+	auth.mutex.Lock()
+	refresh := auth.refreshing
+	if refresh == nil {
+		rejected := rejectedAuthorization(ctx)
+		if rejected != "" && rejected != "Bearer "+auth.token {
+			auth.mutex.Unlock()
+			return nil
+		}
+		refresh = &tokenRefresh{done: make(chan struct{})}
+		auth.refreshing = refresh
+		auth.mutex.Unlock()
+		auth.refresh(ctx, refresh)
+	} else {
+		auth.mutex.Unlock()
+	}
+
+	// Wait for refresh
+	select {
+	case <-refresh.done:
+		return refresh.err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// refresh obtains a new token and shares the outcome with concurrent
+// refreshes.
+func (auth *BearerAuthenticator) refresh(ctx context.Context, refresh *tokenRefresh) {
+	token, err := auth.Token(ctx)
+	auth.mutex.Lock()
+	defer auth.mutex.Unlock()
+	if err == nil {
+		auth.token = token
+	}
+	auth.refreshing = nil
+	refresh.err = err
+	close(refresh.done)
+}
+
+// rejectedRequestKey is the context key for the request whose credentials
+// were rejected.
+type rejectedRequestKey struct{}
+
+// rejectedAuthorization returns the Authorization header of the request whose
+// credentials were rejected, or an empty string if it is unknown.
+func rejectedAuthorization(ctx context.Context) string {
+	request, ok := ctx.Value(rejectedRequestKey{}).(*http.Request)
+	if !ok || request == nil {
+		return ""
+	}
+	return request.Header.Get("Authorization")
+}
+
+// applyCredentials adds the credentials of the authenticator to the request.
+func (client *Client) applyCredentials(request *http.Request) (err error) {
+	// Check for authenticator
+	if client.Authenticator == nil {
+		return nil
+	}
+
+	// Apply credentials
+	err = client.Authenticator.Apply(request)
+	if err != nil {
+		return fmt.Errorf("%w: unable to apply credentials: %w", ErrNonRetryable, err)
+	}
+	return nil
+}
+
+// rejectedCredentials returns true if the server rejected the credentials of
+// the authenticator with 401 Unauthorized or 403 Forbidden.
+func (client *Client) rejectedCredentials(response *http.Response) bool {
+	return client.Authenticator != nil && response != nil &&
+		(response.StatusCode == http.StatusUnauthorized || response.StatusCode == http.StatusForbidden)
+}
+
+// refreshCredentials refreshes the credentials of the authenticator after
+// the server rejected the request of the response.
+func (client *Client) refreshCredentials(ctx context.Context, response *http.Response) (err error) {
+	err = client.Authenticator.Refresh(context.WithValue(ctx, rejectedRequestKey{}, response.Request))
+	if err != nil {
+		return fmt.Errorf("%w: unable to refresh credentials: %w", ErrNonRetryable, err)
+	}
+	return nil
+}
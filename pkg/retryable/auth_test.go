@@ -0,0 +1,110 @@
+package retryable
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestClient_Authenticator(test *testing.T) {
+	test.Parallel()
+
+	var valid atomic.Value
+	var attempts atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		attempts.Add(1)
+		if request.Header.Get("Authorization") != valid.Load() {
+			writer.WriteHeader(http.StatusUnauthorized)
+		}
+	}))
+	defer server.Close()
+
+	var tokens atomic.Int32
+	auth := &BearerAuthenticator{Token: func(context.Context) (string, error) {
+		return strconv.Itoa(int(tokens.Add(1))), nil
+	}}
+	client := new(Client)
+	client.Authenticator = auth
+	valid.Store("Bearer 2")
+	_, err := client.Get(server.URL)
+	require.NoError(test, err)
+	require.Equal(test, int32(2), attempts.Load())
+	require.Equal(test, int32(2), tokens.Load())
+
+	_, err = client.Get(server.URL)
+	require.NoError(test, err)
+	require.Equal(test, int32(3), attempts.Load())
+
+	valid.Store("Bearer 0")
+	response, err := client.Get(server.URL)
+	require.ErrorIs(test, err, ErrNonRetryable)
+	require.Equal(test, http.StatusUnauthorized, response.StatusCode)
+	require.Equal(test, int32(5), attempts.Load())
+
+	auth.Token = func(context.Context) (string, error) {
+		return "", errors.New("token endpoint unavailable")
+	}
+	_, err = client.Get(server.URL)
+	require.ErrorIs(test, err, ErrNonRetryable)
+	require.ErrorContains(test, err, "unable to refresh credentials")
+	require.Equal(test, int32(6), attempts.Load())
+
+	client.Authenticator = &BearerAuthenticator{Token: auth.Token}
+	_, err = client.Get(server.URL)
+	require.ErrorIs(test, err, ErrNonRetryable)
+	require.ErrorContains(test, err, "unable to apply credentials")
+	require.Equal(test, int32(6), attempts.Load())
+}
+
+func TestBearerAuthenticator_Refresh(test *testing.T) {
+	test.Parallel()
+
+	var status atomic.Int32
+	status.Store(http.StatusUnauthorized)
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		if request.Header.Get("Authorization") != "Bearer 2" {
+			writer.WriteHeader(int(status.Load()))
+		}
+	}))
+	defer server.Close()
+
+	var tokens atomic.Int32
+	client := new(Client)
+	client.Authenticator = &BearerAuthenticator{Token: func(context.Context) (string, error) {
+		time.Sleep(10 * time.Millisecond)
+		return strconv.Itoa(int(tokens.Add(1))), nil
+	}}
+
+	// Share one refresh between concurrent rejections
+	var group sync.WaitGroup
+	for index := 0; index < 10; index++ {
+		group.Add(1)
+		go func() {
+			defer group.Done()
+			_, err := client.Get(server.URL)
+			require.NoError(test, err)
+		}()
+	}
+	group.Wait()
+	require.Equal(test, int32(2), tokens.Load())
+
+	// Refresh once on 401 Unauthorized and 403 Forbidden
+	for _, code := range []int{http.StatusUnauthorized, http.StatusForbidden} {
+		status.Store(int32(code))
+		tokens.Store(0)
+		client.Authenticator = &BearerAuthenticator{Token: func(context.Context) (string, error) {
+			return strconv.Itoa(int(tokens.Add(1))), nil
+		}}
+		_, err := client.Get(server.URL)
+		require.NoError(test, err)
+		require.Equal(test, int32(2), tokens.Load())
+	}
+}
@@ -0,0 +1,151 @@
+package retryable
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sync/atomic"
+)
+
+// bufferedBody is a response body that was read into memory, which supports
+// random access and rewinding with Seek.
+type bufferedBody struct {
+	*bytes.Reader
+	buffer []byte
+}
+
+// newBufferedBody returns a response body that reads from the buffer.
+func newBufferedBody(buffer []byte) *bufferedBody {
+	return &bufferedBody{Reader: bytes.NewReader(buffer), buffer: buffer}
+}
+
+// Close implements [io.Closer]. The buffer remains readable after closing.
+func (body *bufferedBody) Close() (err error) {
+	return nil
+}
+
+// streamedBody is a request body that is sent without reading it into
+// memory, which can only be reset until it is first read.
+type streamedBody struct {
+	io.ReadCloser
+
+	// limit specifies the maximum number of bytes that can be read, or zero
+	// for no limit.
+	limit int64
+
+	// read specifies the number of bytes that were read.
+	read atomic.Int64
+}
+
+// Read reads from the request body, returning an error if the request size
+// is exceeded.
+func (body *streamedBody) Read(buffer []byte) (n int, err error) {
+	n, err = body.ReadCloser.Read(buffer)
+	read := body.read.Add(int64(n))
+	if body.limit > 0 && read > body.limit {
+		return n, fmt.Errorf("%w: request size exceeded (%d)", ErrNonRetryable, read)
+	}
+	return n, err
+}
+
+// Close does not close the request body, since the transport closes it after
+// each attempt, and the request body is still needed to retry attempts that
+// did not read it.
+func (body *streamedBody) Close() (err error) {
+	return nil
+}
+
+// reset returns the request body for another attempt, or a non-retryable
+// error if a previous attempt has already read part of it.
+func (body *streamedBody) reset() (reader io.ReadCloser, err error) {
+	if read := body.read.Load(); read > 0 {
+		return nil, fmt.Errorf("%w: streamed request body already sent (%d bytes)", ErrNonRetryable, read)
+	}
+	return body, nil
+}
+
+// closeStreamedBody closes the request body once all attempts are done, if
+// it is streamed.
+func closeStreamedBody(body io.ReadCloser) {
+	if streamed, ok := body.(*streamedBody); ok {
+		_ = streamed.ReadCloser.Close()
+	}
+}
+
+// Body returns a reader at the start of the response body, if the response
+// body was read into memory by the client, without copying it. Unlike the
+// response body itself, the reader is independent of previous reads.
+func Body(response *http.Response) (reader *bytes.Reader, ok bool) {
+	buffer, ok := BodyBytes(response)
+	if !ok {
+		return nil, false
+	}
+	return bytes.NewReader(buffer), true
+}
+
+// BodyBytes returns the response body, if the response body was read into
+// memory by the client, without copying it. The returned slice is shared with
+// the response body and must not be modified.
+func BodyBytes(response *http.Response) (buffer []byte, ok bool) {
+	if response == nil {
+		return nil, false
+	}
+	body, ok := response.Body.(*bufferedBody)
+	if !ok {
+		return nil, false
+	}
+	return body.buffer, true
+}
+
+// Discard reads the remaining response body and closes it, so that the
+// connection can be reused. It is safe to call with a nil response or body,
+// and more than once, so it can be deferred unconditionally after [Client.Do].
+func Discard(response *http.Response) (err error) {
+	// Check for response body
+	if response == nil || response.Body == nil {
+		return nil
+	}
+
+	// Drain and close response body
+	_, _ = io.Copy(io.Discard, response.Body)
+	err = response.Body.Close()
+	response.Body = http.NoBody
+	return err
+}
+
+// DecodeJSON decodes the response body as JSON into the value, and discards
+// and closes the response body, even if decoding fails. A nil response
+// returns a non-retryable error.
+func DecodeJSON(response *http.Response, value any) (err error) {
+	// Check for response body
+	if response == nil || response.Body == nil {
+		return fmt.Errorf("%w: missing response body", ErrNonRetryable)
+	}
+	defer func() {
+		_ = Discard(response)
+	}()
+
+	// Decode response body
+	err = json.NewDecoder(response.Body).Decode(value)
+	if err != nil {
+		return fmt.Errorf("%w: unable to decode response body: %w", ErrNonRetryable, err)
+	}
+	return nil
+}
+
+// DoJSON sends the HTTP request with retries, and decodes the response body
+// as JSON into the value. The response body is always closed, so the returned
+// response only provides the status code and headers.
+func (client *Client) DoJSON(request *http.Request, value any) (response *http.Response, err error) {
+	// Send request
+	response, err = client.Do(request)
+	if err != nil {
+		_ = Discard(response)
+		return response, err
+	}
+
+	// Decode and close response body
+	return response, DecodeJSON(response, value)
+}
@@ -0,0 +1,222 @@
+package retryable
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiscard(test *testing.T) {
+	test.Parallel()
+
+	require.NoError(test, Discard(nil))
+	require.NoError(test, Discard(new(http.Response)))
+
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		_, _ = writer.Write([]byte("body"))
+	}))
+	defer server.Close()
+	for _, stream := range []bool{false, true} {
+		client := new(Client)
+		client.StreamResponse = stream
+		response, err := client.Get(server.URL)
+		require.NoError(test, err)
+		require.NoError(test, Discard(response))
+		require.NoError(test, Discard(response))
+		require.NoError(test, response.Body.Close())
+		body, err := io.ReadAll(response.Body)
+		require.NoError(test, err)
+		require.Empty(test, body)
+	}
+}
+
+func TestClient_DoJSON(test *testing.T) {
+	test.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		switch request.URL.Path {
+		case "/valid":
+			_, _ = writer.Write([]byte(`{"name":"value"}`))
+		case "/invalid":
+			_, _ = writer.Write([]byte(`{`))
+		default:
+			writer.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	var value struct {
+		Name string `json:"name"`
+	}
+	client := new(Client)
+	request, err := http.NewRequest(http.MethodGet, server.URL+"/valid", nil)
+	require.NoError(test, err)
+	response, err := client.DoJSON(request, &value)
+	require.NoError(test, err)
+	require.Equal(test, http.StatusOK, response.StatusCode)
+	require.Equal(test, "value", value.Name)
+	require.Equal(test, http.NoBody, response.Body)
+
+	request, err = http.NewRequest(http.MethodGet, server.URL+"/invalid", nil)
+	require.NoError(test, err)
+	_, err = client.DoJSON(request, &value)
+	require.ErrorIs(test, err, ErrNonRetryable)
+
+	request, err = http.NewRequest(http.MethodGet, server.URL+"/missing", nil)
+	require.NoError(test, err)
+	response, err = client.DoJSON(request, &value)
+	require.ErrorIs(test, err, ErrNonRetryable)
+	require.Equal(test, http.StatusNotFound, response.StatusCode)
+	require.Equal(test, http.NoBody, response.Body)
+
+	require.ErrorIs(test, DecodeJSON(nil, &value), ErrNonRetryable)
+}
+
+func TestBody(test *testing.T) {
+	test.Parallel()
+
+	_, ok := Body(nil)
+	require.False(test, ok)
+
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		_, _ = writer.Write([]byte("body"))
+	}))
+	defer server.Close()
+
+	client := new(Client)
+	response, err := client.Get(server.URL)
+	require.NoError(test, err)
+	buffer, ok := BodyBytes(response)
+	require.True(test, ok)
+	require.Equal(test, "body", string(buffer))
+
+	body, err := io.ReadAll(response.Body)
+	require.NoError(test, err)
+	require.Equal(test, "body", string(body))
+	reader, ok := Body(response)
+	require.True(test, ok)
+	require.Equal(test, int64(4), reader.Size())
+	require.Equal(test, 4, reader.Len())
+
+	seeker, ok := response.Body.(io.ReadSeeker)
+	require.True(test, ok)
+	offset, err := seeker.Seek(2, io.SeekStart)
+	require.NoError(test, err)
+	require.Equal(test, int64(2), offset)
+	body, err = io.ReadAll(response.Body)
+	require.NoError(test, err)
+	require.Equal(test, "dy", string(body))
+	require.NoError(test, response.Body.Close())
+
+	client.StreamResponse = true
+	response, err = client.Get(server.URL)
+	require.NoError(test, err)
+	defer response.Body.Close()
+	_, ok = BodyBytes(response)
+	require.False(test, ok)
+}
+
+func TestClient_RewindBody(test *testing.T) {
+	test.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		if request.URL.Path == "/missing" {
+			writer.WriteHeader(http.StatusNotFound)
+		}
+		_, _ = writer.Write([]byte("body"))
+	}))
+	defer server.Close()
+
+	client := new(Client)
+	client.RequestTimeout = time.Minute
+	client.StreamResponse = true
+	missing, err := http.NewRequest(http.MethodGet, server.URL+"/missing", nil)
+	require.NoError(test, err)
+	missingResponse, err := client.Do(missing)
+	require.Error(test, err)
+
+	client.StreamResponse = false
+	found, err := http.NewRequest(http.MethodGet, server.URL, nil)
+	require.NoError(test, err)
+	raceResponse, err := client.Race(context.Background(), found)
+	require.NoError(test, err)
+
+	for _, response := range []*http.Response{missingResponse, raceResponse} {
+		body, err := io.ReadAll(response.Body)
+		require.NoError(test, err)
+		require.Equal(test, "body", string(body))
+		seeker, ok := response.Body.(io.ReadSeeker)
+		require.True(test, ok)
+		_, err = seeker.Seek(0, io.SeekStart)
+		require.NoError(test, err)
+		body, err = io.ReadAll(response.Body)
+		require.NoError(test, err)
+		require.Equal(test, "body", string(body))
+		require.NoError(test, response.Body.Close())
+	}
+}
+
+// closeTracker is a request body that records whether it was closed.
+type closeTracker struct {
+	io.Reader
+	closed atomic.Bool
+}
+
+// Close records that the request body was closed.
+func (body *closeTracker) Close() error {
+	body.closed.Store(true)
+	return nil
+}
+
+func TestClient_StreamRequest(test *testing.T) {
+	test.Parallel()
+
+	var attempts atomic.Int64
+	var received []string
+	client := new(Client)
+	client.RetryCount = 5
+	client.StreamRequest = true
+	client.Transport = MockTransport(func(request *http.Request) (*http.Response, error) {
+		// Fail the first attempt before reading the request body
+		if attempts.Add(1) == 1 {
+			_ = request.Body.Close()
+			return nil, errors.New("connection refused")
+		}
+
+		// Fail the second attempt after reading the request body
+		body, err := io.ReadAll(request.Body)
+		received = append(received, string(body))
+		_ = request.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		return nil, errors.New("connection reset")
+	})
+
+	body := &closeTracker{Reader: strings.NewReader("upload")}
+	request, err := http.NewRequest(http.MethodPost, "http://localhost/", body)
+	require.NoError(test, err)
+	request.ContentLength = -1
+	_, err = client.Do(request)
+	require.ErrorIs(test, err, ErrNonRetryable)
+	require.ErrorContains(test, err, "already sent (6 bytes)")
+	require.Equal(test, int64(2), attempts.Load())
+	require.Equal(test, []string{"upload"}, received)
+	require.True(test, body.closed.Load())
+
+	attempts.Store(1)
+	client.RequestSize = 3
+	request, err = http.NewRequest(http.MethodPost, "http://localhost/", io.NopCloser(strings.NewReader("upload")))
+	require.NoError(test, err)
+	_, err = client.Do(request)
+	require.ErrorIs(test, err, ErrNonRetryable)
+	require.ErrorContains(test, err, "request size exceeded")
+}
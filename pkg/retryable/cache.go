@@ -0,0 +1,480 @@
+package retryable
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httputil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Cache defines a storage backend for cached responses. Implementations must
+// be safe for concurrent use.
+type Cache interface {
+	// Get returns the cached value for the specified key, and whether the key
+	// was found.
+	Get(key string) (value []byte, ok bool)
+
+	// Set stores the value for the specified key.
+	Set(key string, value []byte)
+
+	// Delete removes the value for the specified key.
+	Delete(key string)
+}
+
+// MemoryCache is a [Cache] that stores responses in memory. The zero value is
+// an empty cache ready to use.
+type MemoryCache struct {
+	// MaxEntries specifies the maximum number of cached responses. If the
+	// maximum is exceeded, the oldest response is evicted. If the maximum is
+	// zero, the number of cached responses is unlimited.
+	MaxEntries int
+
+	mutex   sync.Mutex
+	entries map[string][]byte
+	keys    []string
+}
+
+// Get returns the cached value for the specified key, and whether the key was
+// found.
+func (cache *MemoryCache) Get(key string) (value []byte, ok bool) {
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+	value, ok = cache.entries[key]
+	return value, ok
+}
+
+// Set stores the value for the specified key, evicting the oldest value if
+// the maximum number of entries is exceeded.
+func (cache *MemoryCache) Set(key string, value []byte) {
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+
+	// Store value
+	if cache.entries == nil {
+		cache.entries = make(map[string][]byte)
+	}
+	if _, ok := cache.entries[key]; !ok {
+		cache.keys = append(cache.keys, key)
+	}
+	cache.entries[key] = value
+
+	// Evict oldest values
+	for cache.MaxEntries > 0 && len(cache.keys) > cache.MaxEntries {
+		delete(cache.entries, cache.keys[0])
+		cache.keys = cache.keys[1:]
+	}
+}
+
+// Delete removes the value for the specified key.
+func (cache *MemoryCache) Delete(key string) {
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+	if _, ok := cache.entries[key]; !ok {
+		return
+	}
+	delete(cache.entries, key)
+	for index, existing := range cache.keys {
+		if existing == key {
+			cache.keys = append(cache.keys[:index], cache.keys[index+1:]...)
+			break
+		}
+	}
+}
+
+// DiskCache is a [Cache] that stores responses as files in a directory.
+type DiskCache struct {
+	// Directory specifies the directory where responses are stored. The
+	// directory is created if it does not exist.
+	Directory string
+}
+
+// Get returns the cached value for the specified key, and whether the key was
+// found.
+func (cache *DiskCache) Get(key string) (value []byte, ok bool) {
+	value, err := os.ReadFile(cache.path(key))
+	if err != nil {
+		return nil, false
+	}
+	return value, true
+}
+
+// Set stores the value for the specified key. Errors writing to disk are
+// ignored, and result in a cache miss.
+func (cache *DiskCache) Set(key string, value []byte) {
+	// Ensure directory exists
+	err := os.MkdirAll(cache.Directory, 0o700)
+	if err != nil {
+		return
+	}
+
+	// Write value to temporary file
+	file, err := os.CreateTemp(cache.Directory, ".tmp-*")
+	if err != nil {
+		return
+	}
+	defer func(name string) {
+		_ = os.Remove(name)
+	}(file.Name())
+	_, err = file.Write(value)
+	if closeErr := file.Close(); err != nil || closeErr != nil {
+		return
+	}
+
+	// Replace existing file
+	_ = os.Rename(file.Name(), cache.path(key))
+}
+
+// Delete removes the value for the specified key.
+func (cache *DiskCache) Delete(key string) {
+	_ = os.Remove(cache.path(key))
+}
+
+// path returns the file path for the specified key.
+func (cache *DiskCache) path(key string) string {
+	hash := sha256.Sum256([]byte(key))
+	return filepath.Join(cache.Directory, hex.EncodeToString(hash[:]))
+}
+
+// cacheEntry defines a cached response, and the metadata required to
+// determine its freshness.
+type cacheEntry struct {
+	// Stored specifies when the response was received.
+	Stored time.Time `json:"stored"`
+
+	// Vary specifies the request headers selected by the Vary header.
+	Vary http.Header `json:"vary,omitempty"`
+
+	// Response specifies the response in HTTP/1.1 wire format.
+	Response []byte `json:"response"`
+
+	// Trailer specifies the trailers of the response, which are not part of
+	// the wire format of a response with a known length.
+	Trailer http.Header `json:"trailer,omitempty"`
+}
+
+// cacheKey returns the cache key for the specified request.
+func cacheKey(request *http.Request) string {
+	return request.URL.String()
+}
+
+// isCacheable reports whether the request may be served from cache.
+func isCacheable(request *http.Request) bool {
+	// Check for valid request method
+	if request.URL == nil || (request.Method != "" && request.Method != http.MethodGet) {
+		return false
+	}
+
+	// Check for request headers that bypass the cache
+	for _, name := range []string{"Range", "If-None-Match", "If-Modified-Since", "If-Match", "If-Unmodified-Since"} {
+		if request.Header.Get(name) != "" {
+			return false
+		}
+	}
+	_, noStore := parseCacheControl(request.Header)["no-store"]
+	return !noStore
+}
+
+// lookupCache returns the cached entry for the request. If the cached
+// response is fresh, it is returned and should be used without sending the
+// request. If a stale response with validators is found, the returned request
+// is a copy with conditional headers added.
+func (client *Client) lookupCache(request *http.Request) (entry *cacheEntry, fresh *http.Response, conditional *http.Request) {
+	// Check for valid cache
+	if client.Cache == nil || !isCacheable(request) {
+		return nil, nil, request
+	}
+
+	// Check for cached response
+	value, ok := client.Cache.Get(cacheKey(request))
+	if !ok {
+		return nil, nil, request
+	}
+	entry = new(cacheEntry)
+	err := json.Unmarshal(value, entry)
+	if err != nil {
+		return nil, nil, request
+	}
+	cached, err := entry.response(request)
+	if err != nil {
+		return nil, nil, request
+	}
+
+	// Check for matching request headers
+	for name := range entry.Vary {
+		if strings.Join(request.Header.Values(name), ",") != strings.Join(entry.Vary.Values(name), ",") {
+			return nil, nil, request
+		}
+	}
+
+	// Check for fresh response
+	requestControl := parseCacheControl(request.Header)
+	responseControl := parseCacheControl(cached.Header)
+	_, requestNoCache := requestControl["no-cache"]
+	_, responseNoCache := responseControl["no-cache"]
+	age := entry.age(client.clock().Now(), cached.Header)
+	if !requestNoCache && !responseNoCache && age < freshnessLifetime(cached.Header) {
+		if maxAge, ok := parseSeconds(requestControl, "max-age"); !ok || age < maxAge {
+			cached.Header.Set("Age", strconv.FormatInt(int64(age/time.Second), 10))
+			return entry, cached, request
+		}
+	}
+
+	// Add validators to request
+	etag := cached.Header.Get("ETag")
+	modified := cached.Header.Get("Last-Modified")
+	if etag == "" && modified == "" {
+		return nil, nil, request
+	}
+	conditional = request.Clone(request.Context())
+	if conditional.Header == nil {
+		conditional.Header = make(http.Header)
+	}
+	if etag != "" {
+		conditional.Header.Set("If-None-Match", etag)
+	}
+	if modified != "" {
+		conditional.Header.Set("If-Modified-Since", modified)
+	}
+	return entry, nil, conditional
+}
+
+// updateCache stores cacheable responses, replaces a not modified response
+// with the cached response, and invalidates cached responses after a
+// successful unsafe request. The returned response should be used in place of
+// the specified response.
+func (client *Client) updateCache(request *http.Request, entry *cacheEntry, response *http.Response) *http.Response {
+	// Check for valid cache
+	if client.Cache == nil || request.URL == nil {
+		return response
+	}
+
+	// Invalidate cached response after unsafe request
+	if request.Method != "" && request.Method != http.MethodGet && request.Method != http.MethodHead {
+		if response.StatusCode < http.StatusBadRequest {
+			client.Cache.Delete(cacheKey(request))
+		}
+		return response
+	}
+	if !isCacheable(request) && entry == nil {
+		return response
+	}
+
+	// Replace not modified response with cached response
+	if response.StatusCode == http.StatusNotModified && entry != nil {
+		cached, err := entry.response(request)
+		if err != nil {
+			return response
+		}
+		for name, values := range response.Header {
+			cached.Header[name] = values
+		}
+		client.storeCache(request, cached)
+		return cached
+	}
+
+	// Store cacheable response
+	client.storeCache(request, response)
+	return response
+}
+
+// storeCache stores the response if it is cacheable.
+func (client *Client) storeCache(request *http.Request, response *http.Response) {
+	// Check for cacheable status code
+	switch response.StatusCode {
+	case http.StatusOK, http.StatusNonAuthoritativeInfo, http.StatusMultipleChoices,
+		http.StatusMovedPermanently, http.StatusNotFound, http.StatusGone:
+	default:
+		return
+	}
+
+	// Check for cacheable response headers
+	if _, noStore := parseCacheControl(response.Header)["no-store"]; noStore {
+		return
+	}
+	if freshnessLifetime(response.Header) <= 0 && response.Header.Get("ETag") == "" &&
+		response.Header.Get("Last-Modified") == "" {
+		return
+	}
+
+	// Select request headers
+	entry := cacheEntry{Stored: client.clock().Now(), Vary: nil, Response: nil}
+	for _, name := range parseVary(response.Header) {
+		if name == "*" {
+			return
+		}
+		if entry.Vary == nil {
+			entry.Vary = make(http.Header)
+		}
+		entry.Vary[http.CanonicalHeaderKey(name)] = request.Header.Values(name)
+	}
+
+	// Store response and trailers
+	var err error
+	if len(response.Trailer) > 0 {
+		entry.Trailer = response.Trailer.Clone()
+	}
+	entry.Response, err = httputil.DumpResponse(response, true)
+	if err != nil {
+		return
+	}
+	value, err := json.Marshal(entry)
+	if err != nil {
+		return
+	}
+	client.Cache.Set(cacheKey(request), value)
+}
+
+// PrimeCache fetches the resources at the URLs with GET requests and stores
+// cacheable responses in the cache, such as to warm the cache with reference
+// data at startup. At most the specified number of requests are sent
+// concurrently, or one if the concurrency is less than one. Each request is
+// retried as with [Client.Do], and resources that are already fresh in the
+// cache are not fetched again. If any requests fail, the errors are joined in
+// the order of the URLs.
+func (client *Client) PrimeCache(ctx context.Context, urls []string, concurrency int) (err error) {
+	// Check for cache
+	if client.Cache == nil {
+		return fmt.Errorf("%w: no cache to prime", ErrNonRetryable)
+	}
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	// Fetch resources with bounded concurrency
+	errs := make([]error, len(urls))
+	semaphore := make(chan struct{}, concurrency)
+	var group sync.WaitGroup
+	for index, location := range urls {
+		semaphore <- struct{}{}
+		group.Add(1)
+		go func(index int, location string) {
+			defer func() {
+				<-semaphore
+				group.Done()
+			}()
+			errs[index] = client.primeCache(ctx, location)
+		}(index, location)
+	}
+	group.Wait()
+	return errors.Join(errs...)
+}
+
+// primeCache fetches the resource at the URL and discards the response body.
+func (client *Client) primeCache(ctx context.Context, location string) (err error) {
+	// Construct and send HTTP request
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, location, nil)
+	if err != nil {
+		return fmt.Errorf("%w: unable to construct request: %w", ErrNonRetryable, err)
+	}
+	response, err := client.Do(request)
+	_ = Discard(response)
+	if err != nil {
+		return fmt.Errorf("unable to prime %s: %w", request.URL.Redacted(), err)
+	}
+	return nil
+}
+
+// response parses the cached response.
+func (entry *cacheEntry) response(request *http.Request) (response *http.Response, err error) {
+	// Parse cached response
+	response, err = http.ReadResponse(bufio.NewReader(bytes.NewReader(entry.Response)), request)
+	if err != nil {
+		return nil, err
+	}
+
+	// Read and replace response body
+	defer func(body io.Closer) {
+		_ = body.Close()
+	}(response.Body)
+	buffer, err := io.ReadAll(response.Body)
+	if err != nil {
+		return nil, err
+	}
+	response.Body = newBufferedBody(buffer)
+	response.ContentLength = int64(len(buffer))
+	response.TransferEncoding = nil
+	if entry.Trailer != nil {
+		response.Trailer = entry.Trailer.Clone()
+	}
+	return response, nil
+}
+
+// age calculates the age of the cached response at the specified time.
+func (entry *cacheEntry) age(now time.Time, header http.Header) time.Duration {
+	age := now.Sub(entry.Stored)
+	if seconds, err := strconv.ParseInt(header.Get("Age"), 10, 64); err == nil && seconds > 0 {
+		age += time.Duration(seconds) * time.Second
+	}
+	if age < 0 {
+		return 0
+	}
+	return age
+}
+
+// freshnessLifetime calculates the freshness lifetime of a response from its
+// Cache-Control, Expires, Date, and Last-Modified headers.
+func freshnessLifetime(header http.Header) time.Duration {
+	// Check for explicit freshness lifetime
+	if maxAge, ok := parseSeconds(parseCacheControl(header), "max-age"); ok {
+		return maxAge
+	}
+	date, err := http.ParseTime(header.Get("Date"))
+	if err != nil {
+		return 0
+	}
+	if value := header.Get("Expires"); value != "" {
+		expires, err := http.ParseTime(value)
+		if err != nil {
+			return 0
+		}
+		return expires.Sub(date)
+	}
+
+	// Calculate heuristic freshness lifetime
+	modified, err := http.ParseTime(header.Get("Last-Modified"))
+	if err != nil || modified.After(date) {
+		return 0
+	}
+	return date.Sub(modified) / 10
+}
+
+// parseCacheControl parses the Cache-Control header into a map of lowercase
+// directives and their unquoted values.
+func parseCacheControl(header http.Header) map[string]string {
+	directives := make(map[string]string)
+	for _, value := range header.Values("Cache-Control") {
+		for _, directive := range strings.Split(value, ",") {
+			name, argument, _ := strings.Cut(strings.TrimSpace(directive), "=")
+			if name != "" {
+				directives[strings.ToLower(name)] = strings.Trim(argument, "\"")
+			}
+		}
+	}
+	return directives
+}
+
+// parseSeconds parses the value of a directive as a number of seconds.
+func parseSeconds(directives map[string]string, name string) (duration time.Duration, ok bool) {
+	value, ok := directives[name]
+	if !ok {
+		return 0, false
+	}
+	seconds, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || seconds < 0 {
+		return 0, false
+	}
+	return time.Duration(seconds) * time.Second, true
+}
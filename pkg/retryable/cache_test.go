@@ -0,0 +1,199 @@
+package retryable
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryCache(test *testing.T) {
+	test.Parallel()
+
+	cache := new(MemoryCache)
+	value, ok := cache.Get("x")
+	require.False(test, ok)
+	require.Nil(test, value)
+
+	cache.Set("x", []byte("1"))
+	cache.Set("y", []byte("2"))
+	value, ok = cache.Get("x")
+	require.True(test, ok)
+	require.Equal(test, []byte("1"), value)
+
+	cache.MaxEntries = 1
+	cache.Set("z", []byte("3"))
+	_, ok = cache.Get("x")
+	require.False(test, ok)
+	_, ok = cache.Get("y")
+	require.False(test, ok)
+
+	cache.Delete("z")
+	cache.Delete("z")
+	_, ok = cache.Get("z")
+	require.False(test, ok)
+}
+
+func TestDiskCache(test *testing.T) {
+	test.Parallel()
+
+	cache := &DiskCache{Directory: test.TempDir()}
+	value, ok := cache.Get("x")
+	require.False(test, ok)
+	require.Nil(test, value)
+
+	cache.Set("x", []byte("1"))
+	cache.Set("x", []byte("2"))
+	value, ok = cache.Get("x")
+	require.True(test, ok)
+	require.Equal(test, []byte("2"), value)
+
+	cache.Delete("x")
+	_, ok = cache.Get("x")
+	require.False(test, ok)
+}
+
+func TestFreshnessLifetime(test *testing.T) {
+	test.Parallel()
+
+	header := make(http.Header)
+	require.Zero(test, freshnessLifetime(header))
+
+	header.Set("Cache-Control", "public, max-age=60")
+	require.Equal(test, time.Minute, freshnessLifetime(header))
+
+	date := time.Now()
+	header = http.Header{"Date": {date.UTC().Format(http.TimeFormat)}}
+	header.Set("Expires", date.Add(time.Hour).UTC().Format(http.TimeFormat))
+	require.Equal(test, time.Hour, freshnessLifetime(header))
+
+	header.Del("Expires")
+	header.Set("Last-Modified", date.Add(-10*time.Hour).UTC().Format(http.TimeFormat))
+	require.Equal(test, time.Hour, freshnessLifetime(header))
+}
+
+func TestClient_Cache(test *testing.T) {
+	test.Parallel()
+
+	var attempts atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		attempts.Add(1)
+		switch request.URL.Path {
+		case "/fresh":
+			writer.Header().Set("Cache-Control", "max-age=60")
+		case "/stale":
+			writer.Header().Set("Cache-Control", "no-cache")
+			writer.Header().Set("ETag", `"v1"`)
+			if request.Header.Get("If-None-Match") == `"v1"` {
+				if attempts.Load()%2 == 1 {
+					writer.WriteHeader(http.StatusServiceUnavailable)
+					return
+				}
+				writer.WriteHeader(http.StatusNotModified)
+				return
+			}
+		}
+		_, _ = writer.Write([]byte("xyz"))
+	}))
+	defer server.Close()
+
+	client := new(Client)
+	client.Cache = new(MemoryCache)
+	client.RetryCount = 1
+	client.RetryStatus = []int{http.StatusServiceUnavailable}
+	for range []int{1, 2} {
+		response, err := client.Get(server.URL + "/fresh")
+		require.NoError(test, err)
+		buffer, err := io.ReadAll(response.Body)
+		require.NoError(test, err)
+		require.Equal(test, "xyz", string(buffer))
+	}
+	require.Equal(test, int32(1), attempts.Load())
+
+	for range []int{1, 2} {
+		response, err := client.Get(server.URL + "/stale")
+		require.NoError(test, err)
+		require.Equal(test, http.StatusOK, response.StatusCode)
+		buffer, err := io.ReadAll(response.Body)
+		require.NoError(test, err)
+		require.Equal(test, "xyz", string(buffer))
+	}
+	require.Equal(test, int32(4), attempts.Load())
+
+	response, err := client.Post(server.URL+"/fresh", "text/plain", nil)
+	require.NoError(test, err)
+	require.Equal(test, http.StatusOK, response.StatusCode)
+	_, err = client.Get(server.URL + "/fresh")
+	require.NoError(test, err)
+	require.Equal(test, int32(6), attempts.Load())
+}
+
+func TestClient_CacheTrailer(test *testing.T) {
+	test.Parallel()
+
+	var attempts atomic.Int32
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		attempts.Add(1)
+		writer.Header().Set("Cache-Control", "max-age=60")
+		writer.Header().Set("Trailer", "Grpc-Status")
+		_, _ = writer.Write([]byte("message"))
+		writer.Header().Set("Grpc-Status", "0")
+	}))
+	server.EnableHTTP2 = true
+	server.StartTLS()
+	defer server.Close()
+
+	client := new(Client)
+	client.Cache = new(MemoryCache)
+	client.Transport = server.Client().Transport
+	for range []int{0, 1} {
+		response, err := client.Get(server.URL)
+		require.NoError(test, err)
+		body, err := io.ReadAll(response.Body)
+		require.NoError(test, err)
+		require.NoError(test, response.Body.Close())
+		require.Equal(test, "message", string(body))
+		require.Equal(test, 2, response.ProtoMajor)
+		require.Equal(test, "0", response.Trailer.Get("Grpc-Status"))
+	}
+	require.Equal(test, int32(1), attempts.Load())
+}
+
+func TestClient_PrimeCache(test *testing.T) {
+	test.Parallel()
+
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		requests.Add(1)
+		if request.URL.Path == "/missing" {
+			writer.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		writer.Header().Set("Cache-Control", "max-age=60")
+		_, _ = writer.Write([]byte(request.URL.Path))
+	}))
+	defer server.Close()
+
+	client := new(Client)
+	require.ErrorIs(test, client.PrimeCache(context.Background(), []string{server.URL}, 1), ErrNonRetryable)
+
+	client.Cache = new(MemoryCache)
+	urls := []string{server.URL + "/a", server.URL + "/b", server.URL + "/c"}
+	require.NoError(test, client.PrimeCache(context.Background(), urls, 2))
+	require.Equal(test, int32(3), requests.Load())
+
+	err := client.PrimeCache(context.Background(), append(urls, server.URL+"/missing"), 0)
+	require.ErrorIs(test, err, ErrNonRetryable)
+	require.ErrorContains(test, err, "/missing")
+	require.Equal(test, int32(4), requests.Load())
+
+	response, err := client.Get(server.URL + "/b")
+	require.NoError(test, err)
+	require.NoError(test, response.Body.Close())
+	require.Equal(test, int32(4), requests.Load())
+}
@@ -0,0 +1,133 @@
+package retryable
+
+import (
+	"bytes"
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"net/http"
+	"strings"
+)
+
+// ErrChecksumMismatch defines an error for responses whose body does not
+// match a checksum header, such as a response corrupted by a proxy.
+var ErrChecksumMismatch = errors.New("checksum mismatch")
+
+// checksumAlgorithms contains the supported checksum algorithms per name, as
+// used by the Digest, Content-Digest, and Repr-Digest headers.
+var checksumAlgorithms = map[string]func(body []byte) []byte{
+	"md5": func(body []byte) []byte {
+		sum := md5.Sum(body)
+		return sum[:]
+	},
+	"sha": func(body []byte) []byte {
+		sum := sha1.Sum(body)
+		return sum[:]
+	},
+	"sha-256": func(body []byte) []byte {
+		sum := sha256.Sum256(body)
+		return sum[:]
+	},
+	"sha-512": func(body []byte) []byte {
+		sum := sha512.Sum512(body)
+		return sum[:]
+	},
+}
+
+// amazonChecksums contains the supported checksum algorithms per Amazon S3
+// checksum header.
+var amazonChecksums = map[string]func(body []byte) []byte{
+	"X-Amz-Checksum-Crc32": func(body []byte) []byte {
+		return binary.BigEndian.AppendUint32(nil, crc32.ChecksumIEEE(body))
+	},
+	"X-Amz-Checksum-Crc32c": func(body []byte) []byte {
+		return binary.BigEndian.AppendUint32(nil, crc32.Checksum(body, crc32.MakeTable(crc32.Castagnoli)))
+	},
+	"X-Amz-Checksum-Sha1":   checksumAlgorithms["sha"],
+	"X-Amz-Checksum-Sha256": checksumAlgorithms["sha-256"],
+}
+
+// verifyChecksum returns a retryable error if the response body does not
+// match any of the Content-MD5, Digest, Content-Digest, Repr-Digest, or
+// x-amz-checksum headers of the response. Unknown algorithms are ignored.
+// Responses without a complete body, such as partial or decompressed
+// responses, are not verified.
+func (client *Client) verifyChecksum(response *http.Response, body []byte) (err error) {
+	// Check for complete response body
+	if !client.VerifyChecksum || response.Uncompressed || response.StatusCode == http.StatusPartialContent ||
+		response.StatusCode == http.StatusNoContent || response.StatusCode == http.StatusNotModified ||
+		(response.Request != nil && response.Request.Method == http.MethodHead) {
+		return nil
+	}
+
+	// Verify Content-MD5 header
+	if value := response.Header.Get("Content-Md5"); value != "" {
+		err = verifyDigest("Content-MD5", checksumAlgorithms["md5"], value, body)
+		if err != nil {
+			return err
+		}
+	}
+
+	// Verify Digest header
+	for _, digest := range splitDigests(response.Header.Values("Digest")) {
+		name, value, _ := strings.Cut(digest, "=")
+		if algorithm, ok := checksumAlgorithms[strings.ToLower(strings.TrimSpace(name))]; ok {
+			err = verifyDigest("Digest", algorithm, value, body)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	// Verify Content-Digest and Repr-Digest headers
+	for _, header := range []string{"Content-Digest", "Repr-Digest"} {
+		for _, digest := range splitDigests(response.Header.Values(header)) {
+			name, value, _ := strings.Cut(digest, "=")
+			if algorithm, ok := checksumAlgorithms[strings.ToLower(strings.TrimSpace(name))]; ok {
+				err = verifyDigest(header, algorithm, strings.Trim(strings.TrimSpace(value), ":"), body)
+				if err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	// Verify Amazon S3 checksum headers
+	for header, algorithm := range amazonChecksums {
+		if value := response.Header.Get(header); value != "" {
+			err = verifyDigest(header, algorithm, value, body)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// splitDigests returns the comma-separated digests of the header values.
+func splitDigests(values []string) (digests []string) {
+	for _, value := range values {
+		for _, digest := range strings.Split(value, ",") {
+			if digest = strings.TrimSpace(digest); digest != "" {
+				digests = append(digests, digest)
+			}
+		}
+	}
+	return digests
+}
+
+// verifyDigest returns a retryable error if the base64-encoded digest does
+// not match the checksum of the body.
+func verifyDigest(header string, algorithm func(body []byte) []byte, digest string, body []byte) (err error) {
+	expected, err := base64.StdEncoding.DecodeString(strings.TrimSpace(digest))
+	if err != nil || !bytes.Equal(expected, algorithm(body)) {
+		return fmt.Errorf("%w: %w (%s)", ErrRetryable, ErrChecksumMismatch, header)
+	}
+	return nil
+}
@@ -0,0 +1,69 @@
+package retryable
+
+import (
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/base64"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestClient_VerifyChecksum(test *testing.T) {
+	test.Parallel()
+
+	body := []byte("artifact")
+	md5Sum := md5.Sum(body)
+	sha256Sum := sha256.Sum256(body)
+	md5Digest := base64.StdEncoding.EncodeToString(md5Sum[:])
+	sha256Digest := base64.StdEncoding.EncodeToString(sha256Sum[:])
+	client := &Client{VerifyChecksum: true}
+	for header, valid := range map[string]map[string]bool{
+		"Content-Md5":           {md5Digest: true, sha256Digest: false, "invalid": false},
+		"Digest":                {"SHA-256=" + sha256Digest: true, "md5=" + md5Digest + ", sha-256=" + md5Digest: false, "unknown=x": true},
+		"Content-Digest":        {"sha-256=:" + sha256Digest + ":": true, "sha-256=:" + md5Digest + ":": false},
+		"Repr-Digest":           {"sha-256=:" + sha256Digest + ":": true},
+		"X-Amz-Checksum-Crc32":  {"SOVgLA==": true, "Rg2BWQ==": false},
+		"X-Amz-Checksum-Sha256": {sha256Digest: true, md5Digest: false},
+	} {
+		for value, ok := range valid {
+			response := &http.Response{StatusCode: http.StatusOK, Header: http.Header{header: {value}}}
+			err := client.verifyChecksum(response, body)
+			if ok {
+				require.NoError(test, err, header+": "+value)
+			} else {
+				require.ErrorIs(test, err, ErrRetryable, header+": "+value)
+				require.ErrorIs(test, err, ErrChecksumMismatch, header+": "+value)
+			}
+		}
+	}
+
+	// Skip partial responses
+	response := &http.Response{StatusCode: http.StatusPartialContent, Header: http.Header{"Content-Md5": {sha256Digest}}}
+	require.NoError(test, client.verifyChecksum(response, body))
+}
+
+func TestClient_VerifyChecksumRetry(test *testing.T) {
+	test.Parallel()
+
+	var attempts atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, _ *http.Request) {
+		sum := md5.Sum([]byte("artifact"))
+		writer.Header().Set("Content-Md5", base64.StdEncoding.EncodeToString(sum[:]))
+		if attempts.Add(1) == 1 {
+			_, _ = writer.Write([]byte("corrupt!"))
+			return
+		}
+		_, _ = writer.Write([]byte("artifact"))
+	}))
+	defer server.Close()
+
+	client := &Client{VerifyChecksum: true, RetryCount: 1}
+	response, err := client.Get(server.URL)
+	require.NoError(test, err)
+	require.NoError(test, response.Body.Close())
+	require.Equal(test, int32(2), attempts.Load())
+}
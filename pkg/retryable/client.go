@@ -8,7 +8,8 @@ import (
 	"errors"
 	"fmt"
 	"io"
-	"math"
+	"log"
+	"math/rand"
 	"net/http"
 	"net/url"
 	"runtime/debug"
@@ -16,7 +17,6 @@ import (
 	"strings"
 	"time"
 
-	"github.com/cholland1989/go-delay/pkg/sleep"
 	"github.com/cholland1989/go-retryable/pkg/unofficial"
 )
 
@@ -26,7 +26,15 @@ var ErrRetryable = errors.New("retryable error")
 // ErrNonRetryable defines a non-retryable error.
 var ErrNonRetryable = errors.New("non-retryable error")
 
-// DefaultClient is the default retryable HTTP client.
+// ErrResponseSize defines an error for responses that exceed the maximum
+// response size.
+var ErrResponseSize = errors.New("response size exceeded")
+
+// DefaultClient is the default retryable HTTP client, which is returned by
+// [Default] unless another client is installed with [SetDefault].
+//
+// Deprecated: Use [Default] instead, since DefaultClient does not reflect the
+// client installed with [SetDefault].
 var DefaultClient = &Client{
 	Client:          *http.DefaultClient,
 	RetryStatus:     DefaultStatus,
@@ -69,18 +77,68 @@ var DefaultStatus = []int{
 	unofficial.StatusNetworkConnectTimeout,
 }
 
+// DefaultPermanentStatus contains the default status codes of permanent
+// protocol errors, which are never retried and do not count toward host
+// health.
+var DefaultPermanentStatus = []int{
+	http.StatusUpgradeRequired,
+	http.StatusNotImplemented,
+	http.StatusHTTPVersionNotSupported,
+}
+
+// ErrPermanentStatus defines an error for responses with the status code of a
+// permanent protocol error.
+var ErrPermanentStatus = errors.New("permanent protocol error")
+
 // Client is an HTTP client that can automatically retry failed requests, and
 // provides a drop-in replacement for [net/http.Client].
+//
+// The configuration of the client is read once at the start of each request,
+// so all attempts of a request use the same configuration. To change the
+// retry parameters while requests are in progress, use [Client.UpdatePolicy]
+// instead of modifying the exported fields.
 type Client struct {
 	// Client specifies the base HTTP client.
 	http.Client
 
+	// BaseURL specifies the URL against which relative request URLs, such as
+	// "/users", are resolved. If the base URL is nil, request URLs must be
+	// absolute.
+	BaseURL *url.URL
+
 	// RetryStatus specifies the status codes that are retryable.
 	RetryStatus []int
 
+	// RetryRanges specifies ranges of status codes that are retryable, in
+	// addition to the retryable status codes, such as [ServerErrors].
+	RetryRanges []StatusRange
+
+	// NoRetryStatus specifies status codes that are not retryable, which take
+	// precedence over the retryable status codes and ranges, such as a
+	// vendor-specific status code within a retryable range.
+	NoRetryStatus []int
+
+	// PermanentStatus specifies the status codes of permanent protocol
+	// errors, which are never retried even if they are retryable status codes,
+	// and are not recorded as failures of the host. If the status codes are
+	// nil, [DefaultPermanentStatus] is used.
+	PermanentStatus []int
+
 	// RetryCount specifies the maximum number of retries per request.
 	RetryCount int
 
+	// LimitNestedRetries specifies whether the retries of requests sent from
+	// within an attempt of another client are limited to the nested retry
+	// count, such as requests of a nested client used by a transport, to
+	// prevent multiplicative retry storms. Requests are detected as nested
+	// through their context, as reported by [AttemptFromContext].
+	LimitNestedRetries bool
+
+	// NestedRetryCount specifies the maximum number of retries per nested
+	// request, if nested retries are limited. If the count is zero, nested
+	// requests are not retried.
+	NestedRetryCount int
+
 	// RetryDelay specifies the delay between retries.
 	RetryDelay time.Duration
 
@@ -92,37 +150,408 @@ type Client struct {
 	// RetryJitter specifies the random jitter applied to the retry delay.
 	RetryJitter float64
 
+	// JitterMode specifies how random jitter is applied to the retry delay.
+	// The default mode applies the retry jitter proportionally around the
+	// retry delay.
+	JitterMode JitterMode
+
+	// RandSource specifies the source of random numbers for random jitter,
+	// such as a seeded source from [rand.NewSource] for reproducible tests
+	// and simulations. The source is only used by one goroutine at a time. If
+	// the source is nil, the global random source is used.
+	RandSource rand.Source
+
 	// RetryTimeout specifies the maximum total duration of retries per request.
 	RetryTimeout time.Duration
 
+	// MaxRetryDelay specifies the maximum delay between retries, including
+	// random jitter. If the maximum retry delay is zero, the delay is not
+	// limited. Delays specified by the server are not limited.
+	MaxRetryDelay time.Duration
+
+	// MaxTotalDelay specifies the maximum sum of the retry delays per
+	// request, excluding the time spent sending requests. If the next retry
+	// delay would exceed the budget, the error of the last attempt is
+	// returned. If the maximum is zero, the sum is unlimited.
+	MaxTotalDelay time.Duration
+
+	// RetryDelayParsers specifies additional parsers for server-specified
+	// retry delays, such as rate limit headers, which are used in order when
+	// the Retry-After header is missing or invalid.
+	RetryDelayParsers []RetryDelayParser
+
+	// AdaptiveBackoff specifies an adaptive base delay for the exponential
+	// backoff, which is shared by all requests.
+	AdaptiveBackoff AdaptiveBackoff
+
+	// HostPacing specifies whether a retry delay specified by the server, such
+	// as the Retry-After header, is also applied to subsequent requests to the
+	// same host, instead of only to the retry of the current request.
+	HostPacing bool
+
+	// OnRetryAfter specifies a function that is called with the host and the
+	// delay whenever a response specifies a retry delay.
+	OnRetryAfter func(host string, delay time.Duration)
+
 	// RequestDelay specifies a fixed delay applied to each request.
 	RequestDelay time.Duration
 
 	// RequestJitter specifies the random jitter applied to the request delay.
 	RequestJitter float64
 
+	// SkipInitialDelay specifies whether the request delay is only applied
+	// to retries, so that the first attempt of each request is sent without
+	// delay.
+	SkipInitialDelay bool
+
+	// PollInterval specifies the delay between the requests of [Client.Poll],
+	// with the request jitter applied. If the interval is zero, the requests
+	// are one second apart.
+	PollInterval time.Duration
+
 	// RequestTimeout specifies the maximum duration per request.
 	RequestTimeout time.Duration
 
 	// RequestSize specifies the maximum request size in bytes.
 	RequestSize int64
 
+	// StreamRequest specifies whether request bodies without a GetBody method
+	// are sent without reading them into memory, such as chunked uploads of
+	// unknown length. Such requests are only retried if no part of the request
+	// body was sent by the failed attempt. The request size is enforced while
+	// the body is sent.
+	StreamRequest bool
+
+	// ExpectContinue specifies the minimum request body size in bytes for
+	// which the Expect: 100-continue header is sent, so that a server that
+	// rejects the request does not receive the body. Bodies of unknown size
+	// always qualify. If a server rejects the expectation with a 417 status
+	// code, the request is resent without the header, and the header is no
+	// longer sent to the host. The transport must specify an
+	// ExpectContinueTimeout, as [net/http.DefaultTransport] does. If the size
+	// is zero, the header is not sent.
+	ExpectContinue int64
+
 	// ResponseSize specifies the maximum response size in bytes.
 	ResponseSize int64
+
+	// StreamResponse specifies whether the body of a successful response is
+	// returned without reading it into memory. The response size is enforced
+	// while the body is read. Failed responses, cached responses, responses
+	// of operations with a response check, and responses with body transforms
+	// are still read into memory.
+	StreamResponse bool
+
+	// Decompress specifies whether responses with a gzip, deflate, or
+	// registered content coding are decoded by the client, which requests
+	// them with the Accept-Encoding header unless the request already
+	// specifies it. The response size limits the decoded size, so that
+	// decompression bombs are rejected with [ErrResponseSize].
+	Decompress bool
+
+	// RequestEncoding specifies the content coding used to compress request
+	// bodies, such as "gzip" or a coding registered with
+	// [Client.RegisterEncoding], unless the request already specifies a
+	// Content-Encoding. If the coding is empty, request bodies are sent as is.
+	RequestEncoding string
+
+	// ErrorBodySize specifies the maximum number of bytes of the response
+	// body attached to errors for invalid status codes, which are available
+	// through [ErrorBody]. If the size is zero, [DefaultErrorBodySize] is used.
+	// If the size is negative, no response body or headers are attached.
+	ErrorBodySize int
+
+	// ErrorHeaders specifies the response headers attached to errors for
+	// invalid status codes, which are available through [ErrorHeader]. If the
+	// headers are nil, [DefaultErrorHeaders] is used.
+	ErrorHeaders []string
+
+	// OmitStackTraces specifies whether the stack trace is omitted from the
+	// error returned when a panic is recovered.
+	OmitStackTraces bool
+
+	// TrackResponseSizes specifies the number of largest responses that are
+	// retained for [Client.ResponseSizes]. If it is positive, the response size
+	// distribution per host and operation is also recorded.
+	TrackResponseSizes int
+
+	// DisableHostStatus specifies whether failed attempts are not recorded for
+	// [Client.HostStatus].
+	DisableHostStatus bool
+
+	// AllowedMethods specifies the request methods that the client is allowed
+	// to send, such as GET and HEAD for a read-only client. Other methods are
+	// rejected before sending with [ErrMethodNotAllowed]. If the allowed
+	// methods are empty, all methods are allowed.
+	AllowedMethods []string
+
+	// DeniedMethods specifies the request methods that the client is not
+	// allowed to send, which are rejected before sending with
+	// [ErrMethodNotAllowed].
+	DeniedMethods []string
+
+	// AllowHostOverride specifies whether the Host header is allowed to differ
+	// from the host of the request URL.
+	AllowHostOverride bool
+
+	// RedirectCount specifies the maximum number of redirects per request,
+	// across all retries. If the redirect count is zero, only the redirect
+	// policy of the base HTTP client is applied.
+	RedirectCount int
+
+	// RejectRedirectLoops specifies whether a redirect to a previously visited
+	// URL is treated as a non-retryable error.
+	RejectRedirectLoops bool
+
+	// RetryMalformedRedirects specifies whether redirects with a missing or
+	// invalid Location header, which broken CDNs return during incidents, are
+	// treated as a retryable error. By default, redirects with an invalid
+	// Location header are treated as a non-retryable error, and redirects
+	// with a missing Location header are returned as is. Either error wraps
+	// [ErrMalformedRedirect].
+	RetryMalformedRedirects bool
+
+	// AllowDowngradeHosts specifies the hosts that are allowed to redirect
+	// from HTTPS to HTTP. By default, downgrade redirects are treated as a
+	// non-retryable error.
+	AllowDowngradeHosts []string
+
+	// RejectPortRedirects specifies whether a redirect to a different,
+	// non-default port is treated as a non-retryable error.
+	RejectPortRedirects bool
+
+	// StripCrossOriginAuth specifies whether credentials are removed from
+	// redirects to a different origin (scheme, host, and port). The base HTTP
+	// client only removes credentials from redirects to a different domain.
+	StripCrossOriginAuth bool
+
+	// OnRedirect specifies a function that is called for each redirect policy
+	// decision, such as an allowed downgrade or stripped credentials.
+	OnRedirect func(event RedirectEvent)
+
+	// Cache specifies the storage backend for cached responses. If the cache
+	// is nil, responses are not cached.
+	Cache Cache
+
+	// ConditionalRequests specifies whether the validators of the most recent
+	// response for a URL are automatically added to subsequent GET requests for
+	// the same URL, which may result in a not modified response.
+	ConditionalRequests bool
+
+	// Deduplicate specifies whether identical concurrent GET and HEAD requests
+	// share a single upstream request and its retries, such as during a cache
+	// stampede. Requests are identical if their URL, operation, and
+	// deduplication headers match. Requests are not deduplicated if the
+	// response is streamed.
+	Deduplicate bool
+
+	// DeduplicateHeaders specifies the request headers that must match for
+	// requests to be deduplicated. If the headers are nil,
+	// [DefaultDeduplicateHeaders] are used.
+	DeduplicateHeaders []string
+
+	// Clock specifies the time source and sleep function used for delays and
+	// retry headers. If the clock is nil, the system clock is used. Retry and
+	// request timeouts always use the system clock.
+	Clock Clock
+
+	// AttemptHeaders specifies request headers that are regenerated or removed
+	// on each attempt.
+	AttemptHeaders AttemptHeaders
+
+	// Authenticator specifies credentials that are added to each attempt. If
+	// the server responds with 401 Unauthorized or 403 Forbidden, the
+	// credentials are refreshed and the attempt is repeated once, without
+	// counting towards the retry count.
+	Authenticator Authenticator
+
+	// CheckResponse specifies a response check that is applied to every
+	// response with an accepted status code, such as a detector for faults
+	// that are returned in the response body with a successful status code.
+	// It is applied before the response check of the operation, if any, and
+	// disables streaming of responses.
+	CheckResponse ResponseCheck
+
+	// BodyTransforms specifies transformations that are applied in order to
+	// the body of every response with an accepted status code, after it is
+	// read into memory and its checksum is verified, but before the response
+	// checks and the caller see it, such as stripping a vendor envelope. The
+	// response size is also enforced on the transformed body. Transforms
+	// disable streaming of responses.
+	BodyTransforms []BodyTransform
+
+	// ValidateResponse specifies a function that is called with every
+	// response that passed the other checks of an attempt, before the
+	// response is accepted, such as to verify a checksum header or a required
+	// field. Unlike a response check, it does not disable streaming of
+	// responses, in which case the response body has not been read yet. An
+	// error that is not wrapped with [ErrRetryable] or [ErrNonRetryable] is
+	// treated as retryable.
+	ValidateResponse func(response *http.Response) error
+
+	// VerifyChecksum specifies whether the body of a response read into
+	// memory is verified against its Content-MD5, Digest, Content-Digest,
+	// Repr-Digest, and x-amz-checksum headers. A mismatch is treated as a
+	// retryable error that wraps [ErrChecksumMismatch]. Partial and
+	// decompressed responses are not verified.
+	VerifyChecksum bool
+
+	// EndpointSelector specifies the selection of a backend endpoint for each
+	// attempt, such as [FailoverEndpointSelector], which replaces the scheme
+	// and host of the request URL.
+	EndpointSelector EndpointSelector
+
+	// EndpointRaceDelay specifies the stagger after which the first attempt
+	// is also sent to the second endpoint of the endpoint selector, if the
+	// first endpoint has not responded. The first endpoint to respond wins the
+	// race and is used for the remaining attempts of the request, and the
+	// loser is canceled. If the delay is zero, endpoints are not raced.
+	EndpointRaceDelay time.Duration
+
+	// ProxySelector specifies the selection of a proxy for each attempt, such
+	// as [RoundRobinProxySelector] or [FailureAwareProxySelector], replacing
+	// the proxy of the transport. The proxy selector is only used if the
+	// transport of the base HTTP client is nil or an [net/http.Transport].
+	ProxySelector ProxySelector
+
+	// FreeRetries specifies the maximum number of free retries per request,
+	// which do not consume the retry count: errors that wrap [ErrFreeRetry],
+	// responses with a server-specified retry delay if FreeRetryAfter is set,
+	// and requests that failed because the server closed a reused connection.
+	// Free retries are still bounded by the retry timeout. If the maximum is
+	// zero, every retry consumes the retry count.
+	FreeRetries int
+
+	// FreeRetryAfter specifies whether retryable responses with a
+	// server-specified retry delay, such as a Retry-After header, are retried
+	// for free, so that throttling is not conflated with errors.
+	FreeRetryAfter bool
+
+	// HTTP2Fallback specifies the number of consecutive attempts that must
+	// fail with HTTP/2 transport errors before the remaining attempts of the
+	// request are sent over HTTP/1.1, for middleboxes that intermittently
+	// break HTTP/2. If the number is zero, attempts are never downgraded.
+	HTTP2Fallback int
+
+	// PreflightSize specifies the minimum size of a request body, in bytes,
+	// for which a lightweight preflight request is sent before retrying a
+	// request whose previous attempt failed without a response, so that large
+	// request bodies are not repeatedly sent into a dead connection. If the
+	// size is zero, no preflight requests are sent.
+	PreflightSize int64
+
+	// PreflightMethod specifies the method of preflight requests, such as
+	// HEAD or OPTIONS. If the method is empty, HEAD is used.
+	PreflightMethod string
+
+	// OnDeprecation specifies a function that is called with each response
+	// that announces the deprecation or sunset of its endpoint, with the
+	// Deprecation or Sunset header.
+	OnDeprecation func(deprecation Deprecation)
+
+	// DeprecationLogger specifies the logger used to warn about deprecated
+	// endpoints. Warnings are rate limited per endpoint. If the logger is nil,
+	// no warnings are logged.
+	DeprecationLogger *log.Logger
+
+	// DeprecationLogInterval specifies the minimum interval between warnings
+	// for the same deprecated endpoint. If the interval is zero, an interval
+	// of one hour is used.
+	DeprecationLogInterval time.Duration
+
+	// FallbackAddresses specifies static IP addresses per lowercase hostname,
+	// which are dialed in order when the hostname cannot be resolved, such as
+	// during an outage of the DNS provider. Fallback addresses are only used
+	// if the transport of the base HTTP client is nil or an
+	// [net/http.Transport].
+	FallbackAddresses map[string][]string
+
+	// OnAttempt specifies a function that is called with the metadata of each
+	// attempt after it completes.
+	OnAttempt func(attempt Attempt)
+
+	// OnInformational specifies a function that is called with each
+	// informational (1xx) response received before the final response of an
+	// attempt, such as 103 Early Hints. The header must not be retained or
+	// modified after the function returns.
+	OnInformational func(code int, header http.Header)
+
+	// EventBuffer specifies the capacity of the channel returned by
+	// [Client.Events]. Events that do not fit are dropped. If the capacity
+	// is zero, a capacity of 256 is used.
+	EventBuffer int
+
+	// AttachCurl specifies whether errors returned by Do include a redacted
+	// curl command that reproduces the failed request, which can be retrieved
+	// with [CurlCommand].
+	AttachCurl bool
+
+	// PrepareAttempt specifies a function that is called with the attempt
+	// number (starting from zero) and a copy of the request before each
+	// attempt, such as to refresh an access token or re-sign the request. The
+	// request body can be read with GetBody. If the function returns an error,
+	// the request fails with a non-retryable error, unless the error wraps
+	// [ErrRetryable].
+	PrepareAttempt func(attempt int, request *http.Request) error
+
+	// Signer specifies the signer that signs each attempt, after the request
+	// body is reset and the attempt is prepared. If the signer returns an
+	// error, the request fails with a non-retryable error, unless the error
+	// wraps [ErrRetryable].
+	Signer Signer
+
+	// Middleware specifies functions that wrap the whole retry loop of each
+	// request, such as for logging or header injection, where the first
+	// middleware is the outermost.
+	Middleware []Middleware
+
+	// AttemptMiddleware specifies functions that wrap each attempt of a
+	// request, where the first middleware is the outermost. The context of
+	// the request passed to the middleware belongs to the attempt, as reported
+	// by [AttemptFromContext].
+	AttemptMiddleware []Middleware
+
+	// Negotiation specifies the content negotiation preferences of requests,
+	// which can be replaced per request with [WithNegotiation].
+	Negotiation Negotiation
+
+	// shared contains the mutable state shared by all requests.
+	shared *clientState
 }
 
 // CloseIdleConnections closes any connections on its [net/http.Transport]
 // which were previously connected from previous requests but are now sitting
 // idle in a "keep-alive" state. It does not interrupt any connections
-// currently in use.
+// currently in use. This includes the copies of the transport used for
+// fallback addresses, proxy selection, HTTP/1.1 downgrades, and selected
+// endpoints.
 func (client *Client) CloseIdleConnections() {
+	// Close connections of base transport
 	client.Client.CloseIdleConnections()
+
+	// Close connections of derived transports
+	state := client.state()
+	state.mutex.Lock()
+	transports := []*http.Transport{state.wrappedTransport, state.http1Transport}
+	state.mutex.Unlock()
+	transports = append(transports, state.endpointTransportsFor("")...)
+	for _, transport := range transports {
+		if transport != nil {
+			transport.CloseIdleConnections()
+		}
+	}
 }
 
 // Get issues a GET to the specified URL.
 func (client *Client) Get(url string) (response *http.Response, err error) {
+	return client.GetContext(context.Background(), url)
+}
+
+// GetContext issues a GET to the specified URL with the specified context,
+// which bounds all attempts and retry delays of the request.
+func (client *Client) GetContext(ctx context.Context, url string) (response *http.Response, err error) {
 	// Construct and send HTTP request
-	request, err := http.NewRequestWithContext(context.Background(), http.MethodGet, url, nil)
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
 	if err != nil {
 		return nil, fmt.Errorf("%w: unable to construct request: %w", ErrNonRetryable, err)
 	}
@@ -131,8 +560,14 @@ func (client *Client) Get(url string) (response *http.Response, err error) {
 
 // Head issues a HEAD to the specified URL.
 func (client *Client) Head(url string) (response *http.Response, err error) {
+	return client.HeadContext(context.Background(), url)
+}
+
+// HeadContext issues a HEAD to the specified URL with the specified context,
+// which bounds all attempts and retry delays of the request.
+func (client *Client) HeadContext(ctx context.Context, url string) (response *http.Response, err error) {
 	// Construct and send HTTP request
-	request, err := http.NewRequestWithContext(context.Background(), http.MethodHead, url, nil)
+	request, err := http.NewRequestWithContext(ctx, http.MethodHead, url, nil)
 	if err != nil {
 		return nil, fmt.Errorf("%w: unable to construct request: %w", ErrNonRetryable, err)
 	}
@@ -141,8 +576,14 @@ func (client *Client) Head(url string) (response *http.Response, err error) {
 
 // Post issues a POST to the specified URL.
 func (client *Client) Post(url string, contentType string, body io.Reader) (response *http.Response, err error) {
+	return client.PostContext(context.Background(), url, contentType, body)
+}
+
+// PostContext issues a POST to the specified URL with the specified context,
+// which bounds all attempts and retry delays of the request.
+func (client *Client) PostContext(ctx context.Context, url string, contentType string, body io.Reader) (response *http.Response, err error) {
 	// Construct and send HTTP request
-	request, err := http.NewRequestWithContext(context.Background(), http.MethodPost, url, body)
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, url, body)
 	if err != nil {
 		return nil, fmt.Errorf("%w: unable to construct request: %w", ErrNonRetryable, err)
 	}
@@ -153,69 +594,248 @@ func (client *Client) Post(url string, contentType string, body io.Reader) (resp
 // PostForm issues a POST to the specified URL, with data's keys and values
 // URL-encoded as the request body.
 func (client *Client) PostForm(url string, data url.Values) (response *http.Response, err error) {
+	return client.PostFormContext(context.Background(), url, data)
+}
+
+// PostFormContext issues a POST to the specified URL with the specified
+// context, with data's keys and values URL-encoded as the request body.
+func (client *Client) PostFormContext(ctx context.Context, url string, data url.Values) (response *http.Response, err error) {
 	// Construct and send HTTP request
 	if data != nil {
-		return client.Post(url, "application/x-www-form-urlencoded", strings.NewReader(data.Encode()))
+		return client.PostContext(ctx, url, "application/x-www-form-urlencoded", strings.NewReader(data.Encode()))
 	}
-	return client.Post(url, "application/x-www-form-urlencoded", nil)
+	return client.PostContext(ctx, url, "application/x-www-form-urlencoded", nil)
 }
 
 // Do sends an HTTP request and returns an HTTP response, following policy
-// (such as redirects, cookies, auth) as configured on the client.
+// (such as redirects, cookies, auth) as configured on the client. The request
+// passes through the middleware of the client, if any. Unless the response is
+// streamed, the response body is read into memory and implements
+// [io.ReadSeeker], so it can be rewound and read more than once.
 func (client *Client) Do(request *http.Request) (response *http.Response, err error) {
+	// Check for middleware
+	if len(client.Middleware) == 0 {
+		return client.do(request)
+	}
+	return chain(client.Middleware, DoerFunc(client.do)).Do(request)
+}
+
+// do sends an HTTP request with retries.
+func (client *Client) do(request *http.Request) (response *http.Response, err error) {
 	// Convert panics into an error
 	defer client.panicHandler(&err)
 
+	// Take a snapshot of the configuration for the duration of the request
+	client = client.snapshot()
+
+	// Attach curl command to errors
+	defer client.attachCurl(&request, &err)
+
+	// Limit retries of requests sent from within another attempt
+	client.limitNestedRetries(request)
+
+	// Resolve relative request URL
+	request = client.resolveURL(request)
+
+	// Reject malformed or conflicting requests
+	err = client.validateRequest(request)
+	if err != nil {
+		return nil, err
+	}
+
+	// Share the response of identical concurrent requests
+	if client.Deduplicate {
+		return client.deduplicate(request)
+	}
+	return client.retryRequest(request)
+}
+
+// retryRequest sends a validated HTTP request with retries. The client must
+// be a snapshot.
+func (client *Client) retryRequest(request *http.Request) (response *http.Response, err error) {
+	// Reject requests after shutdown, and cancel them if shutdown expires
+	ctx, release, err := client.trackRequest(request.Context())
+	if err != nil {
+		return nil, err
+	}
+	defer client.cancelAfterBody(&response, &err, release)
+
+	// Reject unknown operations before sending
+	_, err = client.operationCheck(request)
+	if err != nil {
+		return nil, err
+	}
+
 	// Ensure request body can be reset
 	err = client.prepareRequestBody(request)
 	if err != nil {
 		return nil, err
 	}
+	defer closeStreamedBody(request.Body)
+
+	// Apply content negotiation preferences
+	request = client.applyNegotiation(request)
+
+	// Compress request body
+	request, err = client.encodeRequestBody(request)
+	if err != nil {
+		return nil, err
+	}
+
+	// Serve fresh responses from cache
+	entry, cached, request := client.lookupCache(request)
+	if cached != nil {
+		return cached, nil
+	}
+	if entry == nil {
+		request = client.applyValidators(request)
+	}
+
+	// Generate headers that are shared by all attempts
+	request, err = client.prepareRequestHeaders(request)
+	if err != nil {
+		return nil, err
+	}
 
 	// Apply retry timeout to context
-	ctx := request.Context()
+	ctx = client.withEndpointRace(withRedirectCounter(ctx))
 	if client.RetryTimeout > 0 {
 		var cancel context.CancelFunc
 		ctx, cancel = context.WithTimeout(ctx, client.RetryTimeout)
-		defer cancel()
+		defer client.cancelAfterBody(&response, &err, cancel)
 	}
 
 	// Retry failed requests
+	refreshed := false
+	unreachable := false
+	downgraded := false
+	free := 0
+	http2Failures := 0
+	totalDelay := time.Duration(0)
+	sent := false
+	reason := ""
 	for attempt := 0; attempt <= client.RetryCount; attempt++ {
-		// Apply fixed request delay
-		err = client.applyRequestDelay(ctx)
+		// Apply fixed request delay, unless skipped for the first attempt
+		if sent || !client.SkipInitialDelay {
+			err = client.applyRequestDelay(ctx)
+			if err != nil {
+				client.emitEvent(request, Event{Kind: EventGaveUp, Attempt: attempt, Err: err}, response)
+				return response, err
+			}
+		}
+		sent = true
+
+		// Apply server-specified delay for host
+		err = client.applyHostPacing(ctx, request)
 		if err != nil {
+			client.emitEvent(request, Event{Kind: EventGaveUp, Attempt: attempt, Err: err}, response)
 			return response, err
 		}
 
 		// Reset request body
 		err = client.resetRequestBody(request)
 		if err != nil {
+			client.emitEvent(request, Event{Kind: EventGaveUp, Attempt: attempt, Err: err}, response)
 			return response, err
 		}
 
 		// Send request and receive response
-		response, err = client.sendRequest(ctx, request)
+		attemptCtx := client.startAttempt(ctx, attempt, reason)
+		client.emitEvent(request, Event{Kind: EventAttemptStarted, Attempt: attempt}, nil)
+		if downgraded {
+			attemptCtx = withDowngrade(attemptCtx)
+		}
+		response, err = nil, client.sendPreflight(attemptCtx, request, unreachable)
+		if err == nil {
+			response, err = client.doAttempt(attemptCtx, request)
+		}
+		client.finishAttempt(attemptCtx, response, err)
+		client.recordHostPacing(request, response)
 		if err == nil {
-			return response, nil
+			client.traceDecision(ctx, Decision{Attempt: attempt, Kind: DecisionAccept, Reason: fmt.Sprintf("status %d", response.StatusCode)}, response, totalDelay)
+			client.adaptBackoff(true)
+			client.storeValidators(request, response)
+			return client.updateCache(request, entry, response), nil
+		}
+		client.recordFailure(request, response, err)
+		client.emitEvent(request, Event{Kind: EventAttemptFailed, Attempt: attempt, Err: err}, response)
+		reason = retryReason(response, err)
+		unreachable = response == nil
+		if isHTTP2Error(err) {
+			http2Failures++
+		} else {
+			http2Failures = 0
+		}
+		downgraded = downgraded || (client.HTTP2Fallback > 0 && http2Failures >= client.HTTP2Fallback)
+
+		// Refresh rejected credentials and repeat the attempt once
+		if !refreshed && client.rejectedCredentials(response) {
+			refreshed = true
+			client.traceDecision(ctx, Decision{Attempt: attempt, Kind: DecisionRefresh, Reason: reason, Err: err}, response, totalDelay)
+			err = client.refreshCredentials(ctx, response)
+			if err != nil {
+				client.emitEvent(request, Event{Kind: EventGaveUp, Attempt: attempt, Err: err}, response)
+				return response, err
+			}
+			attempt--
+			continue
 		}
 
 		// Check for non-retryable error
 		if !errors.Is(err, ErrRetryable) {
+			client.traceDecision(ctx, Decision{Attempt: attempt, Kind: DecisionStop, Reason: "non-retryable error", Err: err}, response, totalDelay)
+			client.emitEvent(request, Event{Kind: EventGaveUp, Attempt: attempt, Err: err}, response)
 			return response, err
 		}
+		client.adaptBackoff(false)
 
-		// Apply exponential retry delay
-		if attempt < client.RetryCount {
-			err = client.applyRetryDelay(ctx, response, attempt)
+		// Check for retry that does not consume the retry count
+		retry := free < client.FreeRetries && client.isFreeRetry(attemptCtx, response, err)
+
+		// Apply exponential retry delay within the total delay budget
+		if attempt < client.RetryCount || retry {
+			duration := client.nextRetryDelay(response, attempt)
+			if client.MaxTotalDelay > 0 && totalDelay+duration > client.MaxTotalDelay {
+				client.traceDecision(ctx, Decision{Attempt: attempt, Kind: DecisionStop, Reason: "total delay budget exceeded", Err: err, Delay: duration}, response, totalDelay)
+				client.emitEvent(request, Event{Kind: EventGaveUp, Attempt: attempt, Err: err}, response)
+				return response, err
+			}
+			totalDelay += duration
+			client.traceDecision(ctx, Decision{Attempt: attempt, Kind: DecisionRetry, Reason: reason, Err: err, Free: retry, Delay: duration}, response, totalDelay)
+			client.emitEvent(request, Event{Kind: EventSleeping, Attempt: attempt, Err: err, Delay: duration}, response)
+			err = client.applyRetryDelay(ctx, duration)
 			if err != nil {
+				client.emitEvent(request, Event{Kind: EventGaveUp, Attempt: attempt, Err: err}, response)
 				return response, err
 			}
+		} else {
+			client.traceDecision(ctx, Decision{Attempt: attempt, Kind: DecisionStop, Reason: "retry count exhausted", Err: err}, response, totalDelay)
+			client.emitEvent(request, Event{Kind: EventGaveUp, Attempt: attempt, Err: err}, response)
+		}
+
+		// Repeat the attempt number for free retries
+		if retry {
+			free++
+			attempt--
 		}
 	}
 	return response, err
 }
 
+// resolveURL returns a copy of the request with its relative URL resolved
+// against the base URL, if specified.
+func (client *Client) resolveURL(request *http.Request) *http.Request {
+	// Check for relative URL
+	if client.BaseURL == nil || request == nil || request.URL == nil || request.URL.IsAbs() {
+		return request
+	}
+
+	// Resolve URL against base URL
+	resolved := request.WithContext(request.Context())
+	resolved.URL = client.BaseURL.ResolveReference(request.URL)
+	return resolved
+}
+
 // panicHandler recovers panics and converts them into an error, replacing the
 // specified error.
 func (client *Client) panicHandler(err *error) {
@@ -226,15 +846,18 @@ func (client *Client) panicHandler(err *error) {
 
 	// Convert panic into error
 	cause := recover()
-	if cause != nil {
+	if cause != nil && client.OmitStackTraces {
+		*err = fmt.Errorf("%w: %v", ErrNonRetryable, cause)
+	} else if cause != nil {
 		*err = fmt.Errorf("%w: %v: %s", ErrNonRetryable, cause, string(debug.Stack()))
 	}
 }
 
 // prepareRequestBody ensures that the request body can be reset between retry
 // attempts. If the request body is nil or the GetBody method is already set,
-// the request is not modified. Otherwise the request body is read into memory
-// and the GetBody method is updated.
+// the request is not modified. If the request body is streamed, it is wrapped
+// so that it can only be reset until it is first read. Otherwise the request
+// body is read into memory and the GetBody method is updated.
 func (client *Client) prepareRequestBody(request *http.Request) (err error) {
 	// Check for valid request
 	if request == nil {
@@ -246,6 +869,14 @@ func (client *Client) prepareRequestBody(request *http.Request) (err error) {
 		return nil
 	}
 
+	// Stream request body without reading it into memory
+	if client.StreamRequest {
+		body := &streamedBody{ReadCloser: request.Body, limit: client.RequestSize}
+		request.Body = body
+		request.GetBody = body.reset
+		return nil
+	}
+
 	// Limit request size
 	reader := io.Reader(request.Body)
 	if client.RequestSize > 0 {
@@ -283,10 +914,11 @@ func (client *Client) prepareRequestBody(request *http.Request) (err error) {
 }
 
 // applyRequestDelay applies a fixed backoff with random jitter to each
-// request, returning an error if the context is canceled.
+// request, returning an error if the context is canceled or the client is shut
+// down.
 func (client *Client) applyRequestDelay(ctx context.Context) (err error) {
 	// Sleep for a fixed duration with random jitter
-	err = sleep.RandomJitterWithContext(ctx, client.RequestDelay, client.RequestJitter)
+	err = client.sleepUnlessClosed(ctx, client.randomJitter(client.RequestDelay, client.RequestJitter))
 	if err != nil {
 		return fmt.Errorf("%w: %w", ErrNonRetryable, err)
 	}
@@ -309,27 +941,62 @@ func (client *Client) resetRequestBody(request *http.Request) (err error) {
 }
 
 // sendRequest sends the request with the configured HTTP client, validates
-// the response, and reads the response body into memory.
+// the response, and reads the response body into memory. A response body read
+// into memory implements [io.Seeker] and [io.ReaderAt], and is available
+// without copying through [Body] and [BodyBytes].
 func (client *Client) sendRequest(ctx context.Context, request *http.Request) (response *http.Response, err error) {
 	// Apply request timeout to context
 	if client.RequestTimeout > 0 {
 		var cancel context.CancelFunc
 		ctx, cancel = context.WithTimeout(ctx, client.RequestTimeout)
-		defer cancel()
+		defer client.cancelAfterBody(&response, &err, cancel)
+	}
+
+	// Clone request so that each attempt starts from the original headers
+	request = request.Clone(client.traceInformational(client.traceConnection(ctx)))
+	request, endpoint, proxy, err := client.prepareAttempt(ctx, request)
+	defer client.reportEndpoint(endpoint, &err)
+	if err != nil {
+		return nil, err
 	}
 
 	// Send request and receive response
-	response, err = client.Client.Do(request.WithContext(ctx))
+	base := client.Client
+	base.CheckRedirect = client.checkRedirect
+	base.Transport = client.endpointTransport(endpoint, client.downgradeTransport(ctx, client.transport()))
+	response, err = base.Do(request)
+	if err == nil && isExpectationFailed(request, response) {
+		response, err = client.resendWithoutExpect(base, request, response)
+	}
+	client.reportProxy(proxy, err)
 
 	// Check that context is valid
 	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
 		return response, fmt.Errorf("%w: %w", ErrNonRetryable, err)
 	}
 
+	// Check for redirect policy violation
+	if errors.Is(err, ErrTooManyRedirects) || errors.Is(err, ErrUnsafeRedirect) {
+		return response, fmt.Errorf("%w: %w", ErrNonRetryable, err)
+	}
+
+	// Check for non-retryable error, such as an exceeded request size while
+	// streaming the request body
+	if errors.Is(err, ErrNonRetryable) {
+		return response, err
+	}
+
+	// Check for redirect with an invalid Location header
+	if isInvalidLocation(err) {
+		return response, client.malformedRedirect(err)
+	}
+
 	// Check for error sending request
 	if err != nil {
-		return response, fmt.Errorf("%w: unable to send request: %w", ErrRetryable, err)
+		return response, classifySendError(request, err)
 	}
+	client.markReceived(response)
+	client.checkDeprecation(response)
 
 	// Check for valid response
 	if response == nil || response.Body == nil {
@@ -344,9 +1011,88 @@ func (client *Client) sendRequest(ctx context.Context, request *http.Request) (r
 	return response, nil
 }
 
+// prepareAttempt rewrites the request to the endpoint selected for the
+// attempt, applies cookies, attempt headers, and credentials, calls the
+// PrepareAttempt hook, signs the request, and selects the proxy for the
+// attempt. The request must be a copy that belongs to the attempt. The
+// selected endpoint is returned even if an error occurs, so that the outcome
+// can be reported.
+func (client *Client) prepareAttempt(ctx context.Context, request *http.Request) (_ *http.Request, endpoint *url.URL, proxy *url.URL, err error) {
+	// Select endpoint and apply headers
+	endpoint, err = client.selectEndpoint(request)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	client.applyCookieJar(request)
+	client.applyAcceptEncoding(request)
+	client.applyExpectContinue(request)
+	err = client.applyAttemptHeaders(request, attemptNumber(ctx), attemptReason(ctx))
+	if err != nil {
+		return nil, endpoint, nil, err
+	}
+
+	// Apply credentials
+	err = client.applyCredentials(request)
+	if err != nil {
+		return nil, endpoint, nil, err
+	}
+
+	// Apply per-attempt request mutation
+	if client.PrepareAttempt != nil {
+		err = client.PrepareAttempt(attemptNumber(ctx), request)
+		if errors.Is(err, ErrRetryable) {
+			return nil, endpoint, nil, err
+		}
+		if err != nil {
+			return nil, endpoint, nil, fmt.Errorf("%w: unable to prepare attempt: %w", ErrNonRetryable, err)
+		}
+	}
+
+	// Sign attempt
+	err = client.applySigner(request)
+	if err != nil {
+		return nil, endpoint, nil, err
+	}
+
+	// Select proxy for attempt
+	request, proxy, err = client.selectProxy(ctx, request)
+	if err != nil {
+		return nil, endpoint, nil, err
+	}
+	return request, endpoint, proxy, nil
+}
+
 // prepareResponseBody reads the response body into memory, validates the
-// status code, and validates the response size.
+// status code, validates the response size, and applies the response checks
+// of the client and the operation. If the response is streamed and the status
+// code is valid, the response body is limited instead.
 func (client *Client) prepareResponseBody(response *http.Response) (err error) {
+	// Check for operation with custom success criteria
+	check, err := client.responseCheck(response.Request)
+	if err != nil {
+		_ = response.Body.Close()
+		return err
+	}
+
+	// Decode compressed response body
+	decoded, err := client.decodeResponseBody(response)
+	if err != nil {
+		return err
+	}
+
+	// Stream successful responses without reading them into memory
+	if client.StreamResponse && check == nil && len(client.BodyTransforms) == 0 && client.checkStatus(response) == nil {
+		client.recordResponseSize(response, response.ContentLength)
+		if client.ResponseSize > 0 {
+			response.Body = &limitedBody{ReadCloser: response.Body, remaining: client.ResponseSize}
+		}
+		err = client.validateResponse(response)
+		if err != nil {
+			_ = response.Body.Close()
+		}
+		return err
+	}
+
 	// Close response body
 	defer func(body io.Closer) {
 		_ = body.Close()
@@ -364,12 +1110,20 @@ func (client *Client) prepareResponseBody(response *http.Response) (err error) {
 		return fmt.Errorf("%w: unable to read response body: %w", ErrRetryable, err)
 	}
 
+	// Reject decoded response bodies that exceed the response size, without
+	// decoding the remainder
+	if decoded && client.ResponseSize > 0 && int64(len(buffer)) == client.ResponseSize {
+		n, _ := response.Body.Read(make([]byte, 1))
+		if n > 0 {
+			return fmt.Errorf("%w: %w after decoding", ErrNonRetryable, ErrResponseSize)
+		}
+	}
+
 	// Replace response body
-	defer func(buffer []byte) {
+	defer func() {
 		response.ContentLength = int64(len(buffer))
-		body := bytes.NewReader(buffer)
-		response.Body = io.NopCloser(body)
-	}(buffer)
+		response.Body = newBufferedBody(buffer)
+	}()
 
 	// Discard remaining response body
 	size, err := io.Copy(io.Discard, response.Body)
@@ -377,47 +1131,200 @@ func (client *Client) prepareResponseBody(response *http.Response) (err error) {
 		return fmt.Errorf("%w: unable to discard response body: %w", ErrRetryable, err)
 	}
 
-	// Check for retryable status code
-	for _, status := range client.RetryStatus {
-		if status == response.StatusCode {
-			return fmt.Errorf("%w: invalid status code (%d)", ErrRetryable, response.StatusCode)
+	// Record response size
+	client.recordResponseSize(response, int64(len(buffer))+size)
+
+	// Check for valid status code
+	err = client.checkStatus(response)
+	if err != nil {
+		return client.attachErrorBody(err, response, buffer)
+	}
+
+	// Check for valid response size
+	size += client.ResponseSize
+	if client.ResponseSize > 0 && size > client.ResponseSize {
+		return fmt.Errorf("%w: %w (%d)", ErrNonRetryable, ErrResponseSize, size)
+	}
+
+	// Check for corrupted response body
+	err = client.verifyChecksum(response, buffer)
+	if err != nil {
+		return err
+	}
+
+	// Transform response body
+	buffer, err = client.transformBody(response, buffer)
+	if err != nil {
+		return err
+	}
+
+	// Check for custom success criteria
+	if check != nil {
+		err = applyResponseCheck(check, response, buffer)
+		if err != nil {
+			return err
 		}
 	}
 
+	// Validate response before accepting it, with the response body read
+	// into memory
+	response.Body = newBufferedBody(buffer)
+	return client.validateResponse(response)
+}
+
+// validateResponse applies the response validator to the response,
+// classifying errors that are not already classified as retryable.
+func (client *Client) validateResponse(response *http.Response) (err error) {
+	// Check for response validator
+	if client.ValidateResponse == nil {
+		return nil
+	}
+
+	// Validate response
+	err = client.ValidateResponse(response)
+	if err == nil || errors.Is(err, ErrNonRetryable) || errors.Is(err, ErrRetryable) {
+		return err
+	}
+	return fmt.Errorf("%w: response validation failed: %w", ErrRetryable, err)
+}
+
+// checkStatus returns a non-retryable error for permanent protocol errors, a
+// retryable error for retryable status codes, and a non-retryable error for
+// other client and server errors.
+func (client *Client) checkStatus(response *http.Response) (err error) {
+	// Check for redirect with a missing Location header
+	if client.RetryMalformedRedirects && isRedirectStatus(response.StatusCode) && response.Header.Get("Location") == "" {
+		return client.malformedRedirect(fmt.Errorf("missing location header (%d)", response.StatusCode))
+	}
+
+	// Check for permanent protocol error
+	if client.isPermanentStatus(response) {
+		return fmt.Errorf("%w: %w (%d)", ErrNonRetryable, ErrPermanentStatus, response.StatusCode)
+	}
+
+	// Check for retryable status code
+	if client.isRetryStatus(response.StatusCode) {
+		return fmt.Errorf("%w: invalid status code (%d)", ErrRetryable, response.StatusCode)
+	}
+
 	// Check for non-retryable status code
 	if response.StatusCode >= http.StatusBadRequest {
 		return fmt.Errorf("%w: invalid status code (%d)", ErrNonRetryable, response.StatusCode)
 	}
+	return nil
+}
+
+// isPermanentStatus reports whether the response has the status code of a
+// permanent protocol error.
+func (client *Client) isPermanentStatus(response *http.Response) bool {
+	// Check for valid response
+	if response == nil {
+		return false
+	}
+
+	// Check for permanent status code
+	statuses := client.PermanentStatus
+	if statuses == nil {
+		statuses = DefaultPermanentStatus
+	}
+	for _, status := range statuses {
+		if status == response.StatusCode {
+			return true
+		}
+	}
+	return false
+}
+
+// cancelAfterBody cancels the context of a request, unless the response body
+// is streamed, in which case the context is canceled when the response body
+// is closed.
+func (client *Client) cancelAfterBody(response **http.Response, err *error, cancel context.CancelFunc) {
+	// Check for streamed response body
+	if client.StreamResponse && *err == nil && *response != nil && (*response).Body != nil {
+		(*response).Body = cancelOnClose((*response).Body, cancel)
+		return
+	}
+	cancel()
+}
+
+// cancelOnClose returns a response body that cancels the context of the
+// request when it is closed. A response body read into memory no longer needs
+// the context, so the context is canceled immediately and the response body
+// is returned unchanged, which keeps it rewindable.
+func cancelOnClose(body io.ReadCloser, cancel context.CancelFunc) io.ReadCloser {
+	if _, ok := body.(*bufferedBody); ok {
+		cancel()
+		return body
+	}
+	return &cancelBody{ReadCloser: body, cancel: cancel}
+}
+
+// cancelBody is a response body that cancels the context of the request when
+// it is closed.
+type cancelBody struct {
+	io.ReadCloser
+
+	// cancel specifies the function that cancels the context.
+	cancel context.CancelFunc
+}
+
+// Close closes the response body and cancels the context of the request.
+func (body *cancelBody) Close() (err error) {
+	err = body.ReadCloser.Close()
+	body.cancel()
+	return err
+}
+
+// limitedBody is a response body that returns a non-retryable error if more
+// than the remaining number of bytes are read.
+type limitedBody struct {
+	io.ReadCloser
+
+	// remaining specifies the number of bytes that can be read.
+	remaining int64
+}
+
+// Read reads from the response body, returning an error if the response size
+// is exceeded.
+func (body *limitedBody) Read(buffer []byte) (n int, err error) {
+	// Check for exceeded response size
+	if body.remaining < 0 {
+		return 0, fmt.Errorf("%w: %w", ErrNonRetryable, ErrResponseSize)
+	}
+
+	// Read at most one byte more than the remaining size
+	if int64(len(buffer)) > body.remaining+1 {
+		buffer = buffer[:body.remaining+1]
+	}
+	n, err = body.ReadCloser.Read(buffer)
+	body.remaining -= int64(n)
 
 	// Check for valid response size
-	size += client.ResponseSize
-	if client.ResponseSize > 0 && size > client.ResponseSize {
-		return fmt.Errorf("%w: response size exceeded (%d)", ErrNonRetryable, size)
+	if body.remaining < 0 {
+		return n + int(body.remaining), fmt.Errorf("%w: %w", ErrNonRetryable, ErrResponseSize)
 	}
-	return nil
+	return n, err
 }
 
-// applyRetryDelay applies an exponential backoff with random jitter to each
-// retry, returning an error if the context is canceled. If the retry header
-// is present and valid, it is used (without random jitter) instead of an
-// exponential backoff.
-func (client *Client) applyRetryDelay(ctx context.Context, response *http.Response, attempt int) (err error) {
+// nextRetryDelay returns the exponential backoff with random jitter for the
+// retry after the attempt. If the retry header is present and valid, the part
+// of it that remains since the response was received is used (without random
+// jitter) instead of an exponential backoff.
+func (client *Client) nextRetryDelay(response *http.Response, attempt int) (duration time.Duration) {
 	// Check for valid retry header
-	delay := client.parseRetryDelay(response)
-	if delay > 0 {
-		// Sleep for a fixed duration without random jitter
-		err = sleep.RandomJitterWithContext(ctx, delay, 0.0)
-		if err != nil {
-			return fmt.Errorf("%w: %w", ErrNonRetryable, err)
-		}
-		return nil
+	duration, ok := client.remainingRetryDelay(response)
+	if !ok {
+		// Apply exponential duration with random jitter
+		duration = client.limitRetryDelay(client.applyJitter(client.retryDelay(attempt)))
 	}
+	return duration
+}
 
-	// Ensure the retry multiplier is valid when unset
-	multiplier := math.Max(client.RetryMultiplier, 1.0)
-
-	// Sleep for an exponential duration with random jitter
-	err = sleep.ExponentialBackoffWithContext(ctx, client.RetryDelay, multiplier, client.RetryJitter, attempt)
+// applyRetryDelay sleeps for the retry delay, returning an error if the
+// context is canceled or the client starts draining.
+func (client *Client) applyRetryDelay(ctx context.Context, duration time.Duration) (err error) {
+	// Sleep until the delay elapses or the client starts draining
+	err = client.sleepUnlessDraining(ctx, duration)
 	if err != nil {
 		return fmt.Errorf("%w: %w", ErrNonRetryable, err)
 	}
@@ -426,7 +1333,8 @@ func (client *Client) applyRetryDelay(ctx context.Context, response *http.Respon
 
 // parseRetryDelay attempts to parse the retry header for either a duration
 // in seconds or a date in [time.RFC1123] format, returning a non-zero
-// [time.Duration] if the retry header is present and valid.
+// [time.Duration] if the retry header is present and valid. The delay is
+// relative to the time the response was received.
 func (client *Client) parseRetryDelay(response *http.Response) (delay time.Duration) {
 	// Check for valid response headers
 	if response == nil || response.Header == nil {
@@ -436,7 +1344,7 @@ func (client *Client) parseRetryDelay(response *http.Response) (delay time.Durat
 	// Check for valid retry header
 	header := response.Header.Get("Retry-After")
 	if header == "" {
-		return 0
+		return client.parseCustomRetryDelay(response)
 	}
 
 	// Attempt to parse retry header as duration
@@ -448,7 +1356,7 @@ func (client *Client) parseRetryDelay(response *http.Response) (delay time.Durat
 	// Attempt to parse retry header as date
 	date, err := time.Parse(time.RFC1123, header)
 	if err == nil {
-		return time.Until(date)
+		return date.Sub(client.receivedAt(response))
 	}
-	return 0
+	return client.parseCustomRetryDelay(response)
 }
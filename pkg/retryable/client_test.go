@@ -2,12 +2,16 @@ package retryable
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"log"
 	"net/http"
+	"net/http/httptest"
 	"net/url"
+	"strconv"
 	"strings"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -31,7 +35,7 @@ func ExampleClient() {
 	if err != nil {
 		log.Fatal(err)
 	}
-	response, err := DefaultClient.Do(request)
+	response, err := Default().Do(request)
 	if err != nil {
 		log.Fatal(err)
 	}
@@ -100,6 +104,37 @@ func TestClient_PostForm(test *testing.T) {
 	require.NotNil(test, response)
 }
 
+func TestClient_GetContext(test *testing.T) {
+	test.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		writer.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	client := new(Client)
+	client.RetryCount = 5
+	client.RetryDelay = time.Minute
+	client.RetryStatus = []int{http.StatusServiceUnavailable}
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	for _, send := range []func() (*http.Response, error){
+		func() (*http.Response, error) { return client.GetContext(ctx, server.URL) },
+		func() (*http.Response, error) { return client.HeadContext(ctx, server.URL) },
+		func() (*http.Response, error) { return client.PostContext(ctx, server.URL, "text/plain", nil) },
+		func() (*http.Response, error) {
+			return client.PostFormContext(ctx, server.URL, url.Values{"key": {"value"}})
+		},
+	} {
+		_, err := send()
+		require.ErrorIs(test, err, ErrNonRetryable)
+		require.ErrorIs(test, err, context.DeadlineExceeded)
+	}
+
+	_, err := client.GetContext(context.Background(), string([]byte{0x7F}))
+	require.ErrorIs(test, err, ErrNonRetryable)
+}
+
 func TestClient_Do(test *testing.T) {
 	test.Parallel()
 
@@ -174,6 +209,18 @@ func TestClient_PanicHandler(test *testing.T) {
 
 	require.ErrorIs(test, err, ErrNonRetryable)
 	require.ErrorContains(test, err, "runtime error")
+	require.ErrorContains(test, err, "goroutine")
+
+	err = func() (err error) {
+		client := new(Client)
+		client.OmitStackTraces = true
+		defer client.panicHandler(&err)
+		panic("runtime error")
+	}()
+
+	require.ErrorIs(test, err, ErrNonRetryable)
+	require.ErrorContains(test, err, "runtime error")
+	require.NotContains(test, err.Error(), "goroutine")
 }
 
 func TestClient_PrepareRequestBody(test *testing.T) {
@@ -365,7 +412,7 @@ func TestClient_ApplyRetryDelay(test *testing.T) {
 	client := new(Client)
 	applyRetryDelay := func(ctx context.Context, response *http.Response, attempt int) (time.Duration, error) {
 		timestamp := time.Now()
-		err := client.applyRetryDelay(ctx, response, attempt)
+		err := client.applyRetryDelay(ctx, client.nextRetryDelay(response, attempt))
 		return time.Since(timestamp), err
 	}
 
@@ -444,3 +491,223 @@ func TestClient_ParseRetryDelay(test *testing.T) {
 	require.Greater(test, delay, time.Minute-time.Second)
 	require.Less(test, delay, time.Minute)
 }
+
+func TestClient_ValidateResponse(test *testing.T) {
+	test.Parallel()
+
+	var attempts atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, _ *http.Request) {
+		writer.Header().Set("X-Length", "7")
+		if attempts.Add(1) == 1 {
+			_, _ = writer.Write([]byte("part"))
+			return
+		}
+		_, _ = writer.Write([]byte("partial"))
+	}))
+	defer server.Close()
+
+	client := new(Client)
+	client.RetryCount = 1
+	client.ValidateResponse = func(response *http.Response) error {
+		body, err := io.ReadAll(response.Body)
+		if err != nil {
+			return err
+		}
+		if strconv.Itoa(len(body)) != response.Header.Get("X-Length") {
+			return errors.New("length mismatch")
+		}
+		return nil
+	}
+	response, err := client.Get(server.URL)
+	require.NoError(test, err)
+	body, err := io.ReadAll(response.Body)
+	require.NoError(test, err)
+	require.NoError(test, response.Body.Close())
+	require.Equal(test, "partial", string(body))
+	require.Equal(test, int32(2), attempts.Load())
+
+	// Validate streamed responses before the body is read
+	client.StreamResponse = true
+	client.ValidateResponse = func(response *http.Response) error {
+		return fmt.Errorf("%w: missing header", ErrNonRetryable)
+	}
+	_, err = client.Get(server.URL)
+	require.ErrorIs(test, err, ErrNonRetryable)
+	require.Equal(test, int32(3), attempts.Load())
+}
+
+func TestClient_StreamResponse(test *testing.T) {
+	test.Parallel()
+
+	status := http.StatusOK
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, _ *http.Request) {
+		writer.WriteHeader(status)
+		_, _ = writer.Write([]byte("streamed"))
+	}))
+	defer server.Close()
+
+	client := new(Client)
+	client.StreamResponse = true
+	client.ResponseSize = 1024
+	response, err := client.Get(server.URL)
+	require.NoError(test, err)
+	require.IsType(test, new(cancelBody), response.Body)
+	require.IsType(test, new(limitedBody), response.Body.(*cancelBody).ReadCloser)
+	body, err := io.ReadAll(response.Body)
+	require.NoError(test, err)
+	require.Equal(test, "streamed", string(body))
+	require.NoError(test, response.Body.Close())
+
+	client.ResponseSize = 4
+	response, err = client.Get(server.URL)
+	require.NoError(test, err)
+	body, err = io.ReadAll(response.Body)
+	require.ErrorIs(test, err, ErrNonRetryable)
+	require.Equal(test, "stre", string(body))
+	_, err = response.Body.Read(make([]byte, 1))
+	require.ErrorIs(test, err, ErrNonRetryable)
+	require.NoError(test, response.Body.Close())
+
+	client.ResponseSize = 0
+	client.RetryTimeout = time.Minute
+	client.RequestTimeout = time.Minute
+	response, err = client.Get(server.URL)
+	require.NoError(test, err)
+	require.IsType(test, new(cancelBody), response.Body)
+	body, err = io.ReadAll(response.Body)
+	require.NoError(test, err)
+	require.Equal(test, "streamed", string(body))
+	require.NoError(test, response.Body.Close())
+	require.ErrorIs(test, response.Request.Context().Err(), context.Canceled)
+
+	status = http.StatusNotFound
+	response, err = client.Get(server.URL)
+	require.ErrorIs(test, err, ErrNonRetryable)
+	body, err = io.ReadAll(response.Body)
+	require.NoError(test, err)
+	require.Equal(test, "streamed", string(body))
+}
+
+func TestClient_PrepareAttempt(test *testing.T) {
+	test.Parallel()
+
+	var headers []string
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		headers = append(headers, request.Header.Get("X-Retry-Attempt"))
+		writer.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	client := new(Client)
+	client.RetryCount = 2
+	client.RetryStatus = []int{http.StatusServiceUnavailable}
+	client.PrepareAttempt = func(attempt int, request *http.Request) error {
+		request.Header.Set("X-Retry-Attempt", strconv.Itoa(attempt))
+		return nil
+	}
+	request, err := http.NewRequest(http.MethodGet, server.URL, nil)
+	require.NoError(test, err)
+	_, err = client.Do(request)
+	require.ErrorIs(test, err, ErrRetryable)
+	require.Equal(test, []string{"0", "1", "2"}, headers)
+	require.Empty(test, request.Header.Get("X-Retry-Attempt"))
+
+	client.PrepareAttempt = func(int, *http.Request) error {
+		return errors.New("token expired")
+	}
+	_, err = client.Get(server.URL)
+	require.ErrorIs(test, err, ErrNonRetryable)
+	require.ErrorContains(test, err, "token expired")
+	require.Len(test, headers, 3)
+
+	attempts := 0
+	client.PrepareAttempt = func(int, *http.Request) error {
+		attempts++
+		return fmt.Errorf("%w: token service unavailable", ErrRetryable)
+	}
+	_, err = client.Get(server.URL)
+	require.ErrorIs(test, err, ErrRetryable)
+	require.Equal(test, 3, attempts)
+	require.Len(test, headers, 3)
+}
+
+func TestClient_PermanentStatus(test *testing.T) {
+	test.Parallel()
+
+	var attempts int
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		attempts++
+		writer.WriteHeader(http.StatusNotImplemented)
+	}))
+	defer server.Close()
+	address, err := url.Parse(server.URL)
+	require.NoError(test, err)
+
+	client := new(Client)
+	client.RetryCount = 3
+	client.RetryStatus = []int{http.StatusNotImplemented}
+	_, err = client.Get(server.URL)
+	require.ErrorIs(test, err, ErrNonRetryable)
+	require.ErrorIs(test, err, ErrPermanentStatus)
+	require.Equal(test, 1, attempts)
+	_, ok := client.HostStatus(address.Host)
+	require.False(test, ok)
+
+	attempts = 0
+	client.PermanentStatus = []int{}
+	_, err = client.Get(server.URL)
+	require.ErrorIs(test, err, ErrRetryable)
+	require.Equal(test, 4, attempts)
+	_, ok = client.HostStatus(address.Host)
+	require.True(test, ok)
+}
+
+func TestClient_MaxTotalDelay(test *testing.T) {
+	test.Parallel()
+
+	var attempts atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, _ *http.Request) {
+		attempts.Add(1)
+		writer.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	clock := &MockClock{now: time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC)}
+	client := new(Client)
+	client.RetryStatus = []int{http.StatusServiceUnavailable}
+	client.RetryCount = 10
+	client.RetryDelay = time.Second
+	client.RetryMultiplier = 2.0
+	client.MaxTotalDelay = 10 * time.Second
+	client.Clock = clock
+	response, err := client.Get(server.URL)
+	require.ErrorIs(test, err, ErrRetryable)
+	require.NoError(test, response.Body.Close())
+	require.Equal(test, int32(3), attempts.Load())
+	require.Equal(test, []time.Duration{0, 2 * time.Second, 0, 4 * time.Second, 0}, clock.sleeps)
+}
+
+func TestClient_SkipInitialDelay(test *testing.T) {
+	test.Parallel()
+
+	var attempts atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, _ *http.Request) {
+		if attempts.Add(1) == 1 {
+			writer.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer server.Close()
+
+	clock := &MockClock{now: time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC)}
+	client := new(Client)
+	client.RetryStatus = []int{http.StatusServiceUnavailable}
+	client.RetryCount = 1
+	client.RetryDelay = time.Second
+	client.RequestDelay = 10 * time.Millisecond
+	client.SkipInitialDelay = true
+	client.Clock = clock
+	response, err := client.Get(server.URL)
+	require.NoError(test, err)
+	require.NoError(test, response.Body.Close())
+	require.Equal(test, []time.Duration{time.Second, 10 * time.Millisecond}, clock.sleeps)
+}
@@ -0,0 +1,41 @@
+package retryable
+
+import (
+	"context"
+	"time"
+
+	"github.com/cholland1989/go-delay/pkg/sleep"
+)
+
+// Clock defines the time source and sleep function used by a client, so that
+// tests can fast-forward time instead of sleeping.
+type Clock interface {
+	// Now returns the current time.
+	Now() time.Time
+
+	// Sleep pauses the current goroutine for the specified duration, or until
+	// the context is canceled.
+	Sleep(ctx context.Context, duration time.Duration) (err error)
+}
+
+// systemClock is a [Clock] that uses the system time.
+type systemClock struct{}
+
+// Now returns the current system time.
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
+
+// Sleep pauses the current goroutine for the specified duration, or until the
+// context is canceled.
+func (systemClock) Sleep(ctx context.Context, duration time.Duration) (err error) {
+	return sleep.RandomJitterWithContext(ctx, duration, 0.0)
+}
+
+// clock returns the configured clock, or the system clock if unset.
+func (client *Client) clock() Clock {
+	if client.Clock == nil {
+		return systemClock{}
+	}
+	return client.Clock
+}
@@ -0,0 +1,72 @@
+package retryable
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type MockClock struct {
+	mutex  sync.Mutex
+	now    time.Time
+	sleeps []time.Duration
+}
+
+func (mock *MockClock) Now() time.Time {
+	mock.mutex.Lock()
+	defer mock.mutex.Unlock()
+	return mock.now
+}
+
+func (mock *MockClock) Sleep(ctx context.Context, duration time.Duration) error {
+	mock.mutex.Lock()
+	defer mock.mutex.Unlock()
+	mock.now = mock.now.Add(duration)
+	mock.sleeps = append(mock.sleeps, duration)
+	if ctx != nil {
+		return ctx.Err()
+	}
+	return nil
+}
+
+func TestSystemClock(test *testing.T) {
+	test.Parallel()
+
+	clock := new(Client).clock()
+	require.WithinDuration(test, time.Now(), clock.Now(), time.Second)
+
+	timestamp := time.Now()
+	err := clock.Sleep(context.Background(), time.Millisecond)
+	require.NoError(test, err)
+	require.GreaterOrEqual(test, time.Since(timestamp), time.Millisecond)
+}
+
+func TestClient_Clock(test *testing.T) {
+	test.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, _ *http.Request) {
+		writer.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	clock := &MockClock{now: time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC)}
+	client := new(Client)
+	client.Clock = clock
+	client.RetryCount = 3
+	client.RetryDelay = time.Hour
+	client.RetryMultiplier = 2.0
+	client.RetryStatus = []int{http.StatusServiceUnavailable}
+	timestamp := time.Now()
+	_, err := client.Get(server.URL)
+	require.ErrorIs(test, err, ErrRetryable)
+	require.Less(test, time.Since(timestamp), time.Minute)
+	require.Equal(test, []time.Duration{0, 2 * time.Hour, 0, 4 * time.Hour, 0, 8 * time.Hour, 0}, clock.sleeps)
+
+	response := &http.Response{Header: http.Header{"Retry-After": {clock.Now().Add(time.Minute).Format(time.RFC1123)}}}
+	require.Equal(test, time.Minute, client.parseRetryDelay(response))
+}
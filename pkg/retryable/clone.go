@@ -0,0 +1,36 @@
+package retryable
+
+// Clone returns a copy of the client with the current policy applied to the
+// exported fields. The copy shares the transport, and thus the connection
+// pool, of the client, but none of its mutable state, such as validators,
+// host status, response sizes, or the policy of [Client.UpdatePolicy].
+// Registered operations and encodings are copied, so that registering them on
+// either client does not affect the other.
+func (client *Client) Clone() (cloned *Client) {
+	// Copy exported fields with the current policy applied
+	cloned = client.snapshot()
+	cloned.shared = nil
+
+	// Copy registered operations and encodings
+	state := client.state()
+	state.mutex.Lock()
+	defer state.mutex.Unlock()
+	for name, check := range state.operations {
+		cloned.RegisterOperation(name, check)
+	}
+	for name, encoding := range state.encodings {
+		cloned.RegisterEncoding(name, encoding)
+	}
+	return cloned
+}
+
+// With returns a copy of the client, as returned by [Client.Clone], with the
+// specified options applied in order, such as to derive a client with a
+// different retry policy that shares the connection pool of the client.
+func (client *Client) With(options ...Option) (derived *Client) {
+	derived = client.Clone()
+	for _, option := range options {
+		option(derived)
+	}
+	return derived
+}
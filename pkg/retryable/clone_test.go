@@ -0,0 +1,65 @@
+package retryable
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestClient_Clone(test *testing.T) {
+	test.Parallel()
+
+	client := new(Client)
+	client.RetryStatus = []int{http.StatusServiceUnavailable}
+	client.Transport = http.DefaultTransport
+	client.RegisterOperation("check", func(*http.Response, []byte) error { return nil })
+	client.UpdatePolicy(Policy{RetryStatus: []int{http.StatusTooManyRequests}, RetryCount: 3})
+
+	cloned := client.Clone()
+	require.NotSame(test, client.state(), cloned.state())
+	require.Equal(test, http.DefaultTransport, cloned.Transport)
+	require.Equal(test, []int{http.StatusTooManyRequests}, cloned.RetryStatus)
+	require.Equal(test, 3, cloned.RetryCount)
+	request, err := http.NewRequestWithContext(WithOperation(context.Background(), "check"), http.MethodGet, "/", nil)
+	require.NoError(test, err)
+	_, err = cloned.operationCheck(request)
+	require.NoError(test, err)
+
+	cloned.UpdatePolicy(Policy{RetryCount: 5})
+	cloned.RegisterOperation("other", nil)
+	cloned.RetryStatus[0] = http.StatusBadGateway
+	require.Equal(test, 3, client.Policy().RetryCount)
+	require.Equal(test, []int{http.StatusTooManyRequests}, client.Policy().RetryStatus)
+	request, err = http.NewRequestWithContext(WithOperation(context.Background(), "other"), http.MethodGet, "/", nil)
+	require.NoError(test, err)
+	_, err = client.operationCheck(request)
+	require.ErrorIs(test, err, ErrNonRetryable)
+}
+
+func TestClient_With(test *testing.T) {
+	test.Parallel()
+
+	attempts := 0
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		attempts++
+		writer.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	client := NewClient()
+	client.Transport = new(http.Transport)
+	derived := client.With(WithPolicy(Policy{
+		RetryStatus: []int{http.StatusServiceUnavailable},
+		RetryCount:  2,
+		RetryDelay:  time.Millisecond,
+	}))
+	require.Same(test, client.Transport, derived.Transport)
+	require.Equal(test, DefaultClient.RetryCount, client.RetryCount)
+	_, err := derived.Get(server.URL)
+	require.ErrorIs(test, err, ErrRetryable)
+	require.Equal(test, 3, attempts)
+}
@@ -0,0 +1,83 @@
+package retryable
+
+import (
+	"net/http"
+)
+
+// maxValidators is the maximum number of URLs for which validators are
+// retained by a client.
+const maxValidators = 1024
+
+// validators contains the validators of a previous response.
+type validators struct {
+	// ETag specifies the entity tag of the previous response.
+	ETag string
+
+	// LastModified specifies the modification date of the previous response.
+	LastModified string
+}
+
+// applyValidators returns a copy of the request with conditional headers
+// added from the most recent response for the same URL. If conditional
+// requests are disabled, or no validators are known, the request is returned
+// unmodified.
+func (client *Client) applyValidators(request *http.Request) (conditional *http.Request) {
+	// Check for valid request
+	if !client.ConditionalRequests || !isCacheable(request) {
+		return request
+	}
+
+	// Check for known validators
+	state := client.state()
+	state.mutex.Lock()
+	known, ok := state.validators[cacheKey(request)]
+	state.mutex.Unlock()
+	if !ok {
+		return request
+	}
+
+	// Add validators to request
+	conditional = request.Clone(request.Context())
+	if conditional.Header == nil {
+		conditional.Header = make(http.Header)
+	}
+	if known.ETag != "" {
+		conditional.Header.Set("If-None-Match", known.ETag)
+	}
+	if known.LastModified != "" {
+		conditional.Header.Set("If-Modified-Since", known.LastModified)
+	}
+	return conditional
+}
+
+// storeValidators records the validators of a successful response, so that
+// they can be used by subsequent requests for the same URL.
+func (client *Client) storeValidators(request *http.Request, response *http.Response) {
+	// Check for valid response
+	if !client.ConditionalRequests || request.URL == nil || response.StatusCode != http.StatusOK ||
+		(request.Method != "" && request.Method != http.MethodGet) {
+		return
+	}
+
+	// Check for validators
+	known := validators{ETag: response.Header.Get("ETag"), LastModified: response.Header.Get("Last-Modified")}
+	if known.ETag == "" && known.LastModified == "" {
+		return
+	}
+
+	// Store validators, evicting an arbitrary URL if required
+	state := client.state()
+	state.mutex.Lock()
+	defer state.mutex.Unlock()
+	if state.validators == nil {
+		state.validators = make(map[string]validators)
+	}
+	key := cacheKey(request)
+	if _, ok := state.validators[key]; !ok && len(state.validators) >= maxValidators {
+		for existing := range state.validators {
+			delete(state.validators, existing)
+			break
+		}
+	}
+	state.validators[key] = known
+}
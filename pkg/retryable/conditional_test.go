@@ -0,0 +1,72 @@
+package retryable
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestClient_ApplyValidators(test *testing.T) {
+	test.Parallel()
+
+	client := new(Client)
+	request, err := http.NewRequest(http.MethodGet, "https://www.github.com/", nil)
+	require.NoError(test, err)
+	response := &http.Response{StatusCode: http.StatusOK, Header: http.Header{"Etag": {`"v1"`}}}
+	client.storeValidators(request, response)
+	require.Equal(test, request, client.applyValidators(request))
+
+	client.ConditionalRequests = true
+	require.Equal(test, request, client.applyValidators(request))
+
+	client.storeValidators(request, response)
+	conditional := client.applyValidators(request)
+	require.NotEqual(test, request, conditional)
+	require.Equal(test, `"v1"`, conditional.Header.Get("If-None-Match"))
+	require.Empty(test, conditional.Header.Get("If-Modified-Since"))
+	require.Empty(test, request.Header.Get("If-None-Match"))
+
+	response.Header = http.Header{"Last-Modified": {"Mon, 02 Jan 2006 15:04:05 GMT"}}
+	client.storeValidators(request, response)
+	conditional = client.applyValidators(request)
+	require.Empty(test, conditional.Header.Get("If-None-Match"))
+	require.Equal(test, "Mon, 02 Jan 2006 15:04:05 GMT", conditional.Header.Get("If-Modified-Since"))
+
+	request.Method = http.MethodPost
+	require.Equal(test, request, client.applyValidators(request))
+}
+
+func TestClient_ConditionalRequests(test *testing.T) {
+	test.Parallel()
+
+	var attempts atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		writer.Header().Set("ETag", `"v1"`)
+		if attempts.Add(1) == 2 {
+			writer.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		if request.Header.Get("If-None-Match") == `"v1"` {
+			writer.WriteHeader(http.StatusNotModified)
+			return
+		}
+		_, _ = writer.Write([]byte("xyz"))
+	}))
+	defer server.Close()
+
+	client := new(Client)
+	client.ConditionalRequests = true
+	client.RetryCount = 1
+	client.RetryStatus = []int{http.StatusTooManyRequests}
+	response, err := client.Get(server.URL)
+	require.NoError(test, err)
+	require.Equal(test, http.StatusOK, response.StatusCode)
+
+	response, err = client.Get(server.URL)
+	require.NoError(test, err)
+	require.Equal(test, http.StatusNotModified, response.StatusCode)
+	require.Equal(test, int32(3), attempts.Load())
+}
@@ -0,0 +1,369 @@
+package retryable
+
+import (
+	"encoding"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ErrInvalidConfig defines an error for configuration that is malformed or
+// out of range.
+var ErrInvalidConfig = errors.New("invalid config")
+
+// Config contains the retry parameters of a client, so that they can be
+// tuned at deploy time without recompiling.
+type Config struct {
+	// RetryStatus specifies the status codes that are retryable.
+	RetryStatus []int `json:"retry_status" yaml:"retry_status"`
+
+	// RetryRanges specifies ranges of status codes that are retryable, such
+	// as "500-599" or "5xx".
+	RetryRanges []StatusRange `json:"retry_ranges" yaml:"retry_ranges"`
+
+	// NoRetryStatus specifies status codes that are not retryable, which take
+	// precedence over the retryable status codes and ranges.
+	NoRetryStatus []int `json:"no_retry_status" yaml:"no_retry_status"`
+
+	// RetryCount specifies the maximum number of retries per request.
+	RetryCount int `json:"retry_count" yaml:"retry_count"`
+
+	// RetryDelay specifies the initial delay between retries.
+	RetryDelay Duration `json:"retry_delay" yaml:"retry_delay"`
+
+	// RetryMultiplier specifies the exponential backoff multiplier, which is
+	// either zero or at least one.
+	RetryMultiplier float64 `json:"retry_multiplier" yaml:"retry_multiplier"`
+
+	// RetryJitter specifies the random jitter between retries, between zero
+	// and one.
+	RetryJitter float64 `json:"retry_jitter" yaml:"retry_jitter"`
+
+	// RetryTimeout specifies the maximum duration for all retries.
+	RetryTimeout Duration `json:"retry_timeout" yaml:"retry_timeout"`
+
+	// MaxRetryDelay specifies the maximum delay between retries.
+	MaxRetryDelay Duration `json:"max_retry_delay" yaml:"max_retry_delay"`
+
+	// MaxTotalDelay specifies the maximum sum of the delays between retries,
+	// excluding the duration of the requests.
+	MaxTotalDelay Duration `json:"max_total_delay" yaml:"max_total_delay"`
+
+	// RequestDelay specifies the fixed delay between requests.
+	RequestDelay Duration `json:"request_delay" yaml:"request_delay"`
+
+	// RequestJitter specifies the random jitter between requests, between
+	// zero and one.
+	RequestJitter float64 `json:"request_jitter" yaml:"request_jitter"`
+
+	// RequestTimeout specifies the maximum duration for each request.
+	RequestTimeout Duration `json:"request_timeout" yaml:"request_timeout"`
+
+	// RequestSize specifies the maximum size of the request body in bytes.
+	RequestSize int64 `json:"request_size" yaml:"request_size"`
+
+	// ResponseSize specifies the maximum size of the response body in bytes.
+	ResponseSize int64 `json:"response_size" yaml:"response_size"`
+
+	// RedirectCount specifies the maximum number of redirects across all
+	// attempts of a request.
+	RedirectCount int `json:"redirect_count" yaml:"redirect_count"`
+}
+
+// Duration is a [time.Duration] that is encoded as a string in the format
+// accepted by [time.ParseDuration], such as "1.5s" or "10m".
+type Duration time.Duration
+
+// MarshalText encodes the duration as a string.
+func (duration Duration) MarshalText() (text []byte, err error) {
+	return []byte(time.Duration(duration).String()), nil
+}
+
+// UnmarshalText decodes the duration from a string.
+func (duration *Duration) UnmarshalText(text []byte) (err error) {
+	value, err := time.ParseDuration(string(text))
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidConfig, err)
+	}
+	*duration = Duration(value)
+	return nil
+}
+
+// DefaultConfig returns the configuration of the default client returned by
+// [Default].
+func DefaultConfig() (config Config) {
+	policy := Default().Policy()
+	return Config{
+		RetryStatus:     policy.RetryStatus,
+		RetryRanges:     policy.RetryRanges,
+		NoRetryStatus:   policy.NoRetryStatus,
+		RetryCount:      policy.RetryCount,
+		RetryDelay:      Duration(policy.RetryDelay),
+		RetryMultiplier: policy.RetryMultiplier,
+		RetryJitter:     policy.RetryJitter,
+		RetryTimeout:    Duration(policy.RetryTimeout),
+		MaxRetryDelay:   Duration(policy.MaxRetryDelay),
+		MaxTotalDelay:   Duration(policy.MaxTotalDelay),
+		RequestDelay:    Duration(policy.RequestDelay),
+		RequestJitter:   policy.RequestJitter,
+		RequestTimeout:  Duration(policy.RequestTimeout),
+		RequestSize:     policy.RequestSize,
+		ResponseSize:    policy.ResponseSize,
+		RedirectCount:   policy.RedirectCount,
+	}
+}
+
+// Validate returns an error describing every parameter that is out of range.
+func (config Config) Validate() (err error) {
+	var errs []error
+	invalid := func(format string, args ...any) {
+		errs = append(errs, fmt.Errorf("%w: "+format, append([]any{ErrInvalidConfig}, args...)...))
+	}
+
+	// Check for out of range status codes
+	for _, status := range config.RetryStatus {
+		if status < 100 || status > 999 {
+			invalid("retry_status must contain three-digit status codes (got %d)", status)
+		}
+	}
+	for _, status := range config.NoRetryStatus {
+		if status < 100 || status > 999 {
+			invalid("no_retry_status must contain three-digit status codes (got %d)", status)
+		}
+	}
+	for _, statusRange := range config.RetryRanges {
+		if statusRange.Min < 100 || statusRange.Max > 999 || statusRange.Min > statusRange.Max {
+			invalid("retry_ranges must contain ranges of three-digit status codes (got %s)", statusRange)
+		}
+	}
+
+	// Check for out of range counts and sizes
+	if config.RetryCount < 0 {
+		invalid("retry_count must not be negative (got %d)", config.RetryCount)
+	}
+	if config.RedirectCount < 0 {
+		invalid("redirect_count must not be negative (got %d)", config.RedirectCount)
+	}
+	if config.RequestSize < 0 {
+		invalid("request_size must not be negative (got %d)", config.RequestSize)
+	}
+	if config.ResponseSize < 0 {
+		invalid("response_size must not be negative (got %d)", config.ResponseSize)
+	}
+
+	// Check for out of range multiplier and jitter
+	if config.RetryMultiplier != 0.0 && !(config.RetryMultiplier >= 1.0) {
+		invalid("retry_multiplier must be zero or at least 1 (got %g)", config.RetryMultiplier)
+	}
+	if !(config.RetryJitter >= 0.0 && config.RetryJitter <= 1.0) {
+		invalid("retry_jitter must be between 0 and 1 (got %g)", config.RetryJitter)
+	}
+	if !(config.RequestJitter >= 0.0 && config.RequestJitter <= 1.0) {
+		invalid("request_jitter must be between 0 and 1 (got %g)", config.RequestJitter)
+	}
+
+	// Check for negative durations
+	for name, duration := range map[string]Duration{
+		"retry_delay":     config.RetryDelay,
+		"retry_timeout":   config.RetryTimeout,
+		"max_retry_delay": config.MaxRetryDelay,
+		"max_total_delay": config.MaxTotalDelay,
+		"request_delay":   config.RequestDelay,
+		"request_timeout": config.RequestTimeout,
+	} {
+		if duration < 0 {
+			invalid("%s must not be negative (got %s)", name, time.Duration(duration))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// FromConfig returns a new client with the specified configuration, or an
+// error if the configuration is out of range.
+func FromConfig(config Config) (client *Client, err error) {
+	// Check for valid configuration
+	err = config.Validate()
+	if err != nil {
+		return nil, err
+	}
+
+	// Construct client
+	return &Client{
+		Client:          http.Client{},
+		RetryStatus:     append([]int(nil), config.RetryStatus...),
+		RetryRanges:     append([]StatusRange(nil), config.RetryRanges...),
+		NoRetryStatus:   append([]int(nil), config.NoRetryStatus...),
+		RetryCount:      config.RetryCount,
+		RetryDelay:      time.Duration(config.RetryDelay),
+		RetryMultiplier: config.RetryMultiplier,
+		RetryJitter:     config.RetryJitter,
+		RetryTimeout:    time.Duration(config.RetryTimeout),
+		MaxRetryDelay:   time.Duration(config.MaxRetryDelay),
+		MaxTotalDelay:   time.Duration(config.MaxTotalDelay),
+		RequestDelay:    time.Duration(config.RequestDelay),
+		RequestJitter:   config.RequestJitter,
+		RequestTimeout:  time.Duration(config.RequestTimeout),
+		RequestSize:     config.RequestSize,
+		ResponseSize:    config.ResponseSize,
+		RedirectCount:   config.RedirectCount,
+	}, nil
+}
+
+// LoadJSON returns a new client with the configuration decoded from JSON.
+// Parameters that are not specified use the value from [DefaultConfig].
+func LoadJSON(reader io.Reader) (client *Client, err error) {
+	// Decode configuration
+	config := DefaultConfig()
+	decoder := json.NewDecoder(reader)
+	decoder.DisallowUnknownFields()
+	err = decoder.Decode(&config)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("%w: unable to decode json: %w", ErrInvalidConfig, err)
+	}
+	return FromConfig(config)
+}
+
+// LoadYAML returns a new client with the configuration decoded from YAML.
+// Parameters that are not specified use the value from [DefaultConfig].
+func LoadYAML(reader io.Reader) (client *Client, err error) {
+	// Decode configuration
+	config := DefaultConfig()
+	decoder := yaml.NewDecoder(reader)
+	decoder.KnownFields(true)
+	err = decoder.Decode(&config)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("%w: unable to decode yaml: %w", ErrInvalidConfig, err)
+	}
+	return FromConfig(config)
+}
+
+// FromEnv returns a new client with the configuration read from environment
+// variables. Each variable is named after the JSON key of the parameter in
+// upper case, with the specified prefix, such as "HTTP_RETRY_COUNT" for the
+// prefix "HTTP_". Status codes and status ranges are separated by commas.
+// Parameters that are not specified use the value from [DefaultConfig].
+func FromEnv(prefix string) (client *Client, err error) {
+	config := DefaultConfig()
+	value := reflect.ValueOf(&config).Elem()
+	for index := 0; index < value.NumField(); index++ {
+		// Check for environment variable
+		key, _, _ := strings.Cut(value.Type().Field(index).Tag.Get("json"), ",")
+		name := prefix + strings.ToUpper(key)
+		text, ok := os.LookupEnv(name)
+		if !ok {
+			continue
+		}
+
+		// Parse environment variable
+		err = parseField(value.Field(index), strings.TrimSpace(text))
+		if err != nil {
+			return nil, fmt.Errorf("%w: unable to parse %s: %w", ErrInvalidConfig, name, err)
+		}
+	}
+	return FromConfig(config)
+}
+
+// Parse returns a new client with the configuration read from a DSN-style
+// string, such as "https://api.example.com?retry_count=5&retry_delay=200ms",
+// so that the client can be configured through a single string setting. Each
+// query parameter is named after the JSON key of the parameter, and "timeout"
+// is an alias for "retry_timeout". Status codes and status ranges are
+// separated by commas. The scheme, host, and path, if any, are used as the
+// base URL of the client. Parameters that are not specified use the value
+// from [DefaultConfig].
+func Parse(dsn string) (client *Client, err error) {
+	// Parse DSN
+	parsed, err := url.Parse(dsn)
+	if err != nil {
+		return nil, fmt.Errorf("%w: unable to parse dsn: %w", ErrInvalidConfig, err)
+	}
+	query := parsed.Query()
+	if values, ok := query["timeout"]; ok {
+		query["retry_timeout"] = values
+		delete(query, "timeout")
+	}
+
+	// Parse query parameters
+	config := DefaultConfig()
+	value := reflect.ValueOf(&config).Elem()
+	for index := 0; index < value.NumField(); index++ {
+		key, _, _ := strings.Cut(value.Type().Field(index).Tag.Get("json"), ",")
+		if !query.Has(key) {
+			continue
+		}
+		err = parseField(value.Field(index), strings.TrimSpace(query.Get(key)))
+		if err != nil {
+			return nil, fmt.Errorf("%w: unable to parse %s: %w", ErrInvalidConfig, key, err)
+		}
+		delete(query, key)
+	}
+
+	// Check for unknown query parameters
+	for key := range query {
+		return nil, fmt.Errorf("%w: unknown parameter (%s)", ErrInvalidConfig, key)
+	}
+
+	// Construct client with base URL
+	client, err = FromConfig(config)
+	if err != nil {
+		return nil, err
+	}
+	if parsed.Host != "" {
+		parsed.RawQuery = ""
+		parsed.ForceQuery = false
+		client.BaseURL = parsed
+	}
+	return client, nil
+}
+
+// parseField parses the text of an environment variable or query parameter
+// into the field.
+func parseField(field reflect.Value, text string) (err error) {
+	// Check for text unmarshaler
+	if unmarshaler, ok := field.Addr().Interface().(encoding.TextUnmarshaler); ok {
+		return unmarshaler.UnmarshalText([]byte(text))
+	}
+
+	// Parse by kind
+	switch field.Kind() {
+	case reflect.Int, reflect.Int64:
+		value, err := strconv.ParseInt(text, 10, 64)
+		if err != nil {
+			return err
+		}
+		field.SetInt(value)
+	case reflect.Float64:
+		value, err := strconv.ParseFloat(text, 64)
+		if err != nil {
+			return err
+		}
+		field.SetFloat(value)
+	case reflect.Slice:
+		values := reflect.Zero(field.Type())
+		for _, item := range strings.Split(text, ",") {
+			if strings.TrimSpace(item) == "" {
+				continue
+			}
+			value := reflect.New(field.Type().Elem()).Elem()
+			err = parseField(value, strings.TrimSpace(item))
+			if err != nil {
+				return err
+			}
+			values = reflect.Append(values, value)
+		}
+		field.Set(values)
+	default:
+		return fmt.Errorf("unsupported type %s", field.Type())
+	}
+	return nil
+}
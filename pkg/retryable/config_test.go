@@ -0,0 +1,183 @@
+package retryable
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfig_Validate(test *testing.T) {
+	test.Parallel()
+
+	config := DefaultConfig()
+	require.NoError(test, config.Validate())
+	require.NoError(test, Config{}.Validate())
+
+	config.RetryJitter = 1.5
+	config.RequestJitter = -0.5
+	config.RetryMultiplier = 0.5
+	config.RetryCount = -1
+	config.RetryStatus = []int{42}
+	config.RetryDelay = Duration(-time.Second)
+	err := config.Validate()
+	require.ErrorIs(test, err, ErrInvalidConfig)
+	require.ErrorContains(test, err, "retry_jitter must be between 0 and 1 (got 1.5)")
+	require.ErrorContains(test, err, "request_jitter must be between 0 and 1 (got -0.5)")
+	require.ErrorContains(test, err, "retry_multiplier must be zero or at least 1 (got 0.5)")
+	require.ErrorContains(test, err, "retry_count must not be negative")
+	require.ErrorContains(test, err, "retry_status")
+	require.ErrorContains(test, err, "retry_delay must not be negative (got -1s)")
+}
+
+func TestFromConfig(test *testing.T) {
+	test.Parallel()
+
+	client, err := FromConfig(DefaultConfig())
+	require.NoError(test, err)
+	require.Equal(test, DefaultClient.RetryStatus, client.RetryStatus)
+	require.Equal(test, DefaultClient.RetryCount, client.RetryCount)
+	require.Equal(test, DefaultClient.RetryDelay, client.RetryDelay)
+	require.Equal(test, DefaultClient.RequestTimeout, client.RequestTimeout)
+	require.Equal(test, DefaultClient.ResponseSize, client.ResponseSize)
+
+	client, err = FromConfig(Config{RetryJitter: 2.0})
+	require.ErrorIs(test, err, ErrInvalidConfig)
+	require.Nil(test, client)
+}
+
+func TestLoadJSON(test *testing.T) {
+	test.Parallel()
+
+	client, err := LoadJSON(strings.NewReader(`{"retry_count": 3, "retry_delay": "1.5s", "retry_status": [503]}`))
+	require.NoError(test, err)
+	require.Equal(test, 3, client.RetryCount)
+	require.Equal(test, 1500*time.Millisecond, client.RetryDelay)
+	require.Equal(test, []int{http.StatusServiceUnavailable}, client.RetryStatus)
+	require.Equal(test, DefaultClient.RetryMultiplier, client.RetryMultiplier)
+
+	client, err = LoadJSON(strings.NewReader(""))
+	require.NoError(test, err)
+	require.Equal(test, DefaultClient.RetryCount, client.RetryCount)
+
+	_, err = LoadJSON(strings.NewReader(`{"retry_delay": "soon"}`))
+	require.ErrorIs(test, err, ErrInvalidConfig)
+
+	_, err = LoadJSON(strings.NewReader(`{"retry_cuont": 3}`))
+	require.ErrorIs(test, err, ErrInvalidConfig)
+	require.ErrorContains(test, err, "retry_cuont")
+
+	_, err = LoadJSON(strings.NewReader(`{"retry_jitter": 1.5}`))
+	require.ErrorIs(test, err, ErrInvalidConfig)
+	require.ErrorContains(test, err, "retry_jitter")
+
+	text, err := json.Marshal(DefaultConfig())
+	require.NoError(test, err)
+	require.Contains(test, string(text), `"retry_delay":"500ms"`)
+}
+
+func TestLoadYAML(test *testing.T) {
+	test.Parallel()
+
+	client, err := LoadYAML(strings.NewReader("retry_count: 3\nretry_delay: 2s\nretry_status: [429, 503]\n"))
+	require.NoError(test, err)
+	require.Equal(test, 3, client.RetryCount)
+	require.Equal(test, 2*time.Second, client.RetryDelay)
+	require.Equal(test, []int{http.StatusTooManyRequests, http.StatusServiceUnavailable}, client.RetryStatus)
+
+	client, err = LoadYAML(strings.NewReader(""))
+	require.NoError(test, err)
+	require.Equal(test, DefaultClient.RetryCount, client.RetryCount)
+
+	_, err = LoadYAML(strings.NewReader("retry_cuont: 3\n"))
+	require.ErrorIs(test, err, ErrInvalidConfig)
+
+	_, err = LoadYAML(strings.NewReader("retry_multiplier: 0.5\n"))
+	require.ErrorIs(test, err, ErrInvalidConfig)
+	require.ErrorContains(test, err, "retry_multiplier")
+}
+
+func TestFromEnv(test *testing.T) {
+	test.Setenv("RETRYABLE_TEST_RETRY_COUNT", "3")
+	test.Setenv("RETRYABLE_TEST_RETRY_DELAY", "250ms")
+	test.Setenv("RETRYABLE_TEST_RETRY_MULTIPLIER", "2")
+	test.Setenv("RETRYABLE_TEST_RETRY_STATUS", "429, 503")
+	client, err := FromEnv("RETRYABLE_TEST_")
+	require.NoError(test, err)
+	require.Equal(test, 3, client.RetryCount)
+	require.Equal(test, 250*time.Millisecond, client.RetryDelay)
+	require.Equal(test, 2.0, client.RetryMultiplier)
+	require.Equal(test, []int{http.StatusTooManyRequests, http.StatusServiceUnavailable}, client.RetryStatus)
+	require.Equal(test, DefaultClient.RequestTimeout, client.RequestTimeout)
+
+	test.Setenv("RETRYABLE_TEST_RETRY_COUNT", "three")
+	_, err = FromEnv("RETRYABLE_TEST_")
+	require.ErrorIs(test, err, ErrInvalidConfig)
+	require.ErrorContains(test, err, "RETRYABLE_TEST_RETRY_COUNT")
+
+	test.Setenv("RETRYABLE_TEST_RETRY_COUNT", "3")
+	test.Setenv("RETRYABLE_TEST_REQUEST_JITTER", "2")
+	_, err = FromEnv("RETRYABLE_TEST_")
+	require.ErrorIs(test, err, ErrInvalidConfig)
+	require.ErrorContains(test, err, "request_jitter")
+}
+
+func TestParse(test *testing.T) {
+	test.Parallel()
+
+	client, err := Parse("https://api.example.com/v1/?retry_count=5&retry_delay=200ms&timeout=10s&retry_status=429,503")
+	require.NoError(test, err)
+	require.Equal(test, 5, client.RetryCount)
+	require.Equal(test, 200*time.Millisecond, client.RetryDelay)
+	require.Equal(test, 10*time.Second, client.RetryTimeout)
+	require.Equal(test, []int{429, 503}, client.RetryStatus)
+	require.Equal(test, DefaultClient.RequestTimeout, client.RequestTimeout)
+	require.Equal(test, "https://api.example.com/v1/", client.BaseURL.String())
+
+	client, err = Parse("?retry_count=1")
+	require.NoError(test, err)
+	require.Equal(test, 1, client.RetryCount)
+	require.Nil(test, client.BaseURL)
+
+	_, err = Parse("https://api.example.com?retry_count=many")
+	require.ErrorIs(test, err, ErrInvalidConfig)
+	_, err = Parse("https://api.example.com?retries=1")
+	require.ErrorIs(test, err, ErrInvalidConfig)
+	_, err = Parse("https://api.example.com?retry_count=-1")
+	require.ErrorIs(test, err, ErrInvalidConfig)
+	_, err = Parse("%zz")
+	require.ErrorIs(test, err, ErrInvalidConfig)
+}
+
+func TestClient_BaseURL(test *testing.T) {
+	test.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		_, _ = writer.Write([]byte(request.URL.Path))
+	}))
+	defer server.Close()
+
+	client, err := Parse(server.URL + "/v1/?retry_count=0")
+	require.NoError(test, err)
+	response, err := client.Get("users")
+	require.NoError(test, err)
+	body, err := io.ReadAll(response.Body)
+	require.NoError(test, err)
+	require.NoError(test, response.Body.Close())
+	require.Equal(test, "/v1/users", string(body))
+
+	request, err := http.NewRequest(http.MethodGet, "/other", nil)
+	require.NoError(test, err)
+	response, err = client.Do(request)
+	require.NoError(test, err)
+	body, err = io.ReadAll(response.Body)
+	require.NoError(test, err)
+	require.NoError(test, response.Body.Close())
+	require.Equal(test, "/other", string(body))
+	require.Equal(test, "/other", request.URL.String())
+}
@@ -0,0 +1,33 @@
+package retryable
+
+import (
+	"net/http"
+)
+
+// applyCookieJar removes cookies from the request that are managed by the
+// cookie jar, so that the current cookies from the jar are sent with each
+// attempt instead of the cookies from the original request.
+func (client *Client) applyCookieJar(request *http.Request) {
+	// Check for valid cookie jar
+	if client.Jar == nil || request == nil || request.URL == nil || request.Header == nil {
+		return
+	}
+
+	// Check for cookies managed by the cookie jar
+	managed := make(map[string]bool)
+	for _, cookie := range client.Jar.Cookies(request.URL) {
+		managed[cookie.Name] = true
+	}
+	if len(managed) == 0 {
+		return
+	}
+
+	// Replace cookie header with unmanaged cookies
+	cookies := request.Cookies()
+	request.Header.Del("Cookie")
+	for _, cookie := range cookies {
+		if !managed[cookie.Name] {
+			request.AddCookie(cookie)
+		}
+	}
+}
@@ -0,0 +1,69 @@
+package retryable
+
+import (
+	"net/http"
+	"net/http/cookiejar"
+	"net/http/httptest"
+	"net/url"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestClient_ApplyCookieJar(test *testing.T) {
+	test.Parallel()
+
+	client := new(Client)
+	client.applyCookieJar(nil)
+
+	request, err := http.NewRequest(http.MethodGet, "https://www.github.com/", nil)
+	require.NoError(test, err)
+	request.Header.Set("Cookie", "session=a; other=b")
+	client.applyCookieJar(request)
+	require.Equal(test, "session=a; other=b", request.Header.Get("Cookie"))
+
+	client.Jar, err = cookiejar.New(nil)
+	require.NoError(test, err)
+	client.applyCookieJar(request)
+	require.Equal(test, "session=a; other=b", request.Header.Get("Cookie"))
+
+	client.Jar.SetCookies(request.URL, []*http.Cookie{{Name: "session", Value: "c"}})
+	client.applyCookieJar(request)
+	require.Equal(test, "other=b", request.Header.Get("Cookie"))
+}
+
+func TestClient_CookieRotation(test *testing.T) {
+	test.Parallel()
+
+	var attempts atomic.Int32
+	cookies := make(chan []string, 2)
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		cookies <- request.Header.Values("Cookie")
+		if attempts.Add(1) == 1 {
+			http.SetCookie(writer, &http.Cookie{Name: "session", Value: "b"})
+			writer.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer server.Close()
+
+	jar, err := cookiejar.New(nil)
+	require.NoError(test, err)
+	url, err := url.Parse(server.URL)
+	require.NoError(test, err)
+	jar.SetCookies(url, []*http.Cookie{{Name: "session", Value: "a"}})
+
+	client := new(Client)
+	client.Jar = jar
+	client.RetryCount = 1
+	client.RetryStatus = []int{http.StatusServiceUnavailable}
+	request, err := http.NewRequest(http.MethodGet, server.URL, nil)
+	require.NoError(test, err)
+	request.Header.Set("Cookie", "session=a")
+	response, err := client.Do(request)
+	require.NoError(test, err)
+	require.Equal(test, http.StatusOK, response.StatusCode)
+	require.Equal(test, []string{"session=a"}, <-cookies)
+	require.Equal(test, []string{"session=b"}, <-cookies)
+	require.Equal(test, "session=a", request.Header.Get("Cookie"))
+}
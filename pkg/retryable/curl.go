@@ -0,0 +1,213 @@
+package retryable
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// maxCurlBody is the maximum size of a request body included in a curl
+// command.
+const maxCurlBody = 64 * 1024
+
+// sensitiveNames contains substrings of header, query parameter, and body
+// field names whose values are redacted from curl commands.
+var sensitiveNames = []string{"auth", "cookie", "key", "password", "secret", "session", "signature", "token"}
+
+// curlError is an error with a curl command that reproduces the request.
+type curlError struct {
+	error
+
+	// command specifies the curl command.
+	command string
+}
+
+// Unwrap returns the underlying error.
+func (err *curlError) Unwrap() error {
+	return err.error
+}
+
+// CurlCommand returns the curl command attached to the error by a client
+// with [Client.AttachCurl] enabled, and reports whether one was attached.
+func CurlCommand(err error) (command string, ok bool) {
+	var target *curlError
+	if errors.As(err, &target) {
+		return target.command, true
+	}
+	return "", false
+}
+
+// Curl returns a copy-pasteable curl command that reproduces the request.
+// Credentials in the URL, and the values of headers, query parameters, and
+// form or JSON body fields with sensitive names, such as Authorization,
+// api_key, or password, are replaced with REDACTED. The query parameters keep
+// their original order and encoding. The request body is included if it is no
+// larger than 64 KiB and can be read again with GetBody.
+func Curl(request *http.Request) (command string) {
+	// Check for valid request
+	if request == nil || request.URL == nil {
+		return ""
+	}
+
+	// Redact URL
+	location := *request.URL
+	if location.User != nil {
+		location.User = url.User("REDACTED")
+	}
+	location.RawQuery = redactPairs(location.RawQuery)
+
+	// Format method and URL
+	method := request.Method
+	if method == "" {
+		method = http.MethodGet
+	}
+	parts := []string{"curl", "-X", method, shellQuote(location.String())}
+
+	// Format headers in a stable order
+	names := make([]string, 0, len(request.Header))
+	for name := range request.Header {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	if request.Host != "" && request.Host != request.URL.Host {
+		parts = append(parts, "-H", shellQuote("Host: "+request.Host))
+	}
+	for _, name := range names {
+		for _, value := range request.Header[name] {
+			if isSensitive(name) {
+				value = "REDACTED"
+			}
+			parts = append(parts, "-H", shellQuote(name+": "+value))
+		}
+	}
+
+	// Format request body
+	if body, ok := curlBody(request); ok && len(body) > 0 {
+		parts = append(parts, "--data-binary", shellQuote(string(redactBody(request, body))))
+	}
+	return strings.Join(parts, " ")
+}
+
+// attachCurl wraps the error with a curl command that reproduces the request,
+// if enabled.
+func (client *Client) attachCurl(request **http.Request, err *error) {
+	// Check for failed request
+	if !client.AttachCurl || *err == nil || *request == nil {
+		return
+	}
+
+	// Attach curl command
+	*err = &curlError{error: *err, command: Curl(*request)}
+}
+
+// curlBody returns the request body if it can be read again and is not too
+// large for a curl command.
+func curlBody(request *http.Request) (body []byte, ok bool) {
+	// Check for replayable request body
+	if request.GetBody == nil || request.ContentLength > maxCurlBody {
+		return nil, false
+	}
+	reader, err := request.GetBody()
+	if err != nil {
+		return nil, false
+	}
+	defer func() {
+		_ = reader.Close()
+	}()
+
+	// Read request body up to limit
+	body, err = io.ReadAll(io.LimitReader(reader, maxCurlBody+1))
+	if err != nil || len(body) > maxCurlBody {
+		return nil, false
+	}
+	return body, true
+}
+
+// redactBody replaces the values of form or JSON body fields with sensitive
+// names. Other request bodies are returned unchanged.
+func redactBody(request *http.Request, body []byte) (redacted []byte) {
+	// Check for form or JSON request body
+	mediaType, _, _ := mime.ParseMediaType(request.Header.Get("Content-Type"))
+	switch {
+	case mediaType == "application/x-www-form-urlencoded":
+		return []byte(redactPairs(string(body)))
+	case mediaType != "application/json" && !strings.HasSuffix(mediaType, "+json"):
+		return body
+	}
+
+	// Redact JSON fields, keeping the request body unchanged if none match
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+	var value any
+	if decoder.Decode(&value) != nil || !redactJSON(value) {
+		return body
+	}
+	redacted, err := json.Marshal(value)
+	if err != nil {
+		return body
+	}
+	return redacted
+}
+
+// redactJSON replaces the values of object fields with sensitive names in the
+// decoded JSON value, and reports whether any field was replaced.
+func redactJSON(value any) (changed bool) {
+	switch value := value.(type) {
+	case map[string]any:
+		for name, field := range value {
+			if isSensitive(name) {
+				value[name] = "REDACTED"
+				changed = true
+			} else if redactJSON(field) {
+				changed = true
+			}
+		}
+	case []any:
+		for _, element := range value {
+			if redactJSON(element) {
+				changed = true
+			}
+		}
+	}
+	return changed
+}
+
+// redactPairs replaces the values of URL-encoded name-value pairs with
+// sensitive names, keeping the order and encoding of the other pairs.
+func redactPairs(raw string) (redacted string) {
+	pairs := strings.Split(raw, "&")
+	for index, pair := range pairs {
+		name, _, _ := strings.Cut(pair, "=")
+		unescaped, err := url.QueryUnescape(name)
+		if err != nil {
+			unescaped = name
+		}
+		if isSensitive(unescaped) {
+			pairs[index] = name + "=REDACTED"
+		}
+	}
+	return strings.Join(pairs, "&")
+}
+
+// isSensitive reports whether the header or query parameter name indicates a
+// sensitive value.
+func isSensitive(name string) bool {
+	name = strings.ToLower(name)
+	for _, sensitive := range sensitiveNames {
+		if strings.Contains(name, sensitive) {
+			return true
+		}
+	}
+	return false
+}
+
+// shellQuote quotes the value for a POSIX shell.
+func shellQuote(value string) string {
+	return "'" + strings.ReplaceAll(value, "'", `'\''`) + "'"
+}
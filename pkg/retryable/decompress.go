@@ -0,0 +1,226 @@
+package retryable
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// Encoding defines a content coding, such as zstd, that compresses request
+// bodies and decompresses response bodies.
+type Encoding interface {
+	// Encode returns a writer that compresses data written to it into the
+	// specified writer, and flushes it when closed.
+	Encode(writer io.Writer) (encoder io.WriteCloser, err error)
+
+	// Decode returns a reader that decompresses data read from the specified
+	// reader.
+	Decode(reader io.Reader) (decoder io.ReadCloser, err error)
+}
+
+// gzipEncoding is the built-in gzip [Encoding].
+type gzipEncoding struct{}
+
+// Encode returns a gzip writer.
+func (gzipEncoding) Encode(writer io.Writer) (encoder io.WriteCloser, err error) {
+	return gzip.NewWriter(writer), nil
+}
+
+// Decode returns a gzip reader.
+func (gzipEncoding) Decode(reader io.Reader) (decoder io.ReadCloser, err error) {
+	return gzip.NewReader(reader)
+}
+
+// deflateEncoding is the built-in deflate [Encoding], which uses the zlib
+// format as specified for HTTP.
+type deflateEncoding struct{}
+
+// Encode returns a zlib writer.
+func (deflateEncoding) Encode(writer io.Writer) (encoder io.WriteCloser, err error) {
+	return zlib.NewWriter(writer), nil
+}
+
+// Decode returns a zlib reader.
+func (deflateEncoding) Decode(reader io.Reader) (decoder io.ReadCloser, err error) {
+	return zlib.NewReader(reader)
+}
+
+// RegisterEncoding registers the content coding with the specified name, such
+// as "zstd" or "br", for compressing request bodies and decompressing
+// response bodies, replacing any existing or built-in coding with the name.
+func (client *Client) RegisterEncoding(name string, encoding Encoding) {
+	state := client.state()
+	state.mutex.Lock()
+	defer state.mutex.Unlock()
+	if state.encodings == nil {
+		state.encodings = make(map[string]Encoding)
+	}
+	state.encodings[strings.ToLower(name)] = encoding
+}
+
+// encoding returns the content coding with the specified name, or nil if the
+// coding is neither registered nor built in.
+func (client *Client) encoding(name string) Encoding {
+	// Check for registered coding
+	name = strings.ToLower(name)
+	state := client.state()
+	state.mutex.Lock()
+	encoding, ok := state.encodings[name]
+	state.mutex.Unlock()
+	if ok {
+		return encoding
+	}
+
+	// Check for built-in coding
+	switch name {
+	case "gzip", "x-gzip":
+		return gzipEncoding{}
+	case "deflate":
+		return deflateEncoding{}
+	default:
+		return nil
+	}
+}
+
+// applyAcceptEncoding requests the content codings that the client decodes,
+// unless the request already specifies them.
+func (client *Client) applyAcceptEncoding(request *http.Request) {
+	// Check for decompression
+	if !client.Decompress || request.Header.Get("Accept-Encoding") != "" {
+		return
+	}
+
+	// List built-in and registered codings
+	names := []string{"gzip", "deflate"}
+	state := client.state()
+	state.mutex.Lock()
+	registered := make([]string, 0, len(state.encodings))
+	for name := range state.encodings {
+		if name != "gzip" && name != "deflate" {
+			registered = append(registered, name)
+		}
+	}
+	state.mutex.Unlock()
+	sort.Strings(registered)
+	request.Header.Set("Accept-Encoding", strings.Join(append(names, registered...), ", "))
+}
+
+// encodeRequestBody returns a copy of the request with its body compressed
+// with the request encoding, unless the request has no body or already
+// specifies a content coding. Otherwise the request is returned unmodified.
+func (client *Client) encodeRequestBody(request *http.Request) (encoded *http.Request, err error) {
+	// Check for request encoding
+	if client.RequestEncoding == "" || request.Body == nil || request.Body == http.NoBody ||
+		request.GetBody == nil || request.Header.Get("Content-Encoding") != "" {
+		return request, nil
+	}
+	encoding := client.encoding(client.RequestEncoding)
+	if encoding == nil {
+		return nil, fmt.Errorf("%w: unknown request encoding (%s)", ErrNonRetryable, client.RequestEncoding)
+	}
+
+	// Compress request body
+	body, err := request.GetBody()
+	if err != nil {
+		return nil, fmt.Errorf("%w: unable to read request body: %w", ErrNonRetryable, err)
+	}
+	defer func() {
+		_ = body.Close()
+	}()
+	var buffer bytes.Buffer
+	encoder, err := encoding.Encode(&buffer)
+	if err == nil {
+		_, err = io.Copy(encoder, body)
+		if closeErr := encoder.Close(); err == nil {
+			err = closeErr
+		}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: unable to encode request body: %w", ErrNonRetryable, err)
+	}
+
+	// Replace request body
+	compressed := buffer.Bytes()
+	encoded = request.Clone(request.Context())
+	encoded.Header.Set("Content-Encoding", strings.ToLower(client.RequestEncoding))
+	encoded.ContentLength = int64(len(compressed))
+	encoded.Body = io.NopCloser(bytes.NewReader(compressed))
+	encoded.GetBody = func() (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(compressed)), nil
+	}
+	return encoded, nil
+}
+
+// decodeResponseBody replaces the response body with its decoded content, if
+// decompression is enabled and every content coding of the response is
+// supported, and reports whether the response body was decoded.
+func (client *Client) decodeResponseBody(response *http.Response) (decoded bool, err error) {
+	// Check for supported content codings
+	if !client.Decompress {
+		return false, nil
+	}
+	var encodings []Encoding
+	for _, value := range response.Header.Values("Content-Encoding") {
+		for _, name := range strings.Split(value, ",") {
+			name = strings.TrimSpace(name)
+			if name == "" || strings.EqualFold(name, "identity") {
+				continue
+			}
+			encoding := client.encoding(name)
+			if encoding == nil {
+				return false, nil
+			}
+			encodings = append(encodings, encoding)
+		}
+	}
+	if len(encodings) == 0 {
+		return false, nil
+	}
+
+	// Decode content codings in the reverse order of application
+	body := &decodedBody{Closer: response.Body}
+	reader := io.Reader(response.Body)
+	for index := len(encodings) - 1; index >= 0; index-- {
+		decoder, err := encodings[index].Decode(reader)
+		if err != nil {
+			_ = body.Close()
+			return false, fmt.Errorf("%w: unable to decode response body: %w", ErrRetryable, err)
+		}
+		body.decoders = append(body.decoders, decoder)
+		reader = decoder
+	}
+
+	// Replace response body and headers
+	body.Reader = reader
+	response.Body = body
+	response.Header.Del("Content-Encoding")
+	response.Header.Del("Content-Length")
+	response.ContentLength = -1
+	response.Uncompressed = true
+	return true, nil
+}
+
+// decodedBody is a response body that reads the decoded content, and closes
+// the decoders and the original response body.
+type decodedBody struct {
+	io.Reader
+
+	// Closer specifies the original response body.
+	io.Closer
+
+	// decoders contains the decoders of the content codings.
+	decoders []io.ReadCloser
+}
+
+// Close closes the decoders and the original response body.
+func (body *decodedBody) Close() (err error) {
+	for index := len(body.decoders) - 1; index >= 0; index-- {
+		_ = body.decoders[index].Close()
+	}
+	return body.Closer.Close()
+}
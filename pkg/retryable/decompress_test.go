@@ -0,0 +1,167 @@
+package retryable
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestClient_Decompress(test *testing.T) {
+	test.Parallel()
+
+	compress := func(coding string, data []byte) []byte {
+		var buffer bytes.Buffer
+		var writer io.WriteCloser
+		if coding == "deflate" {
+			writer = zlib.NewWriter(&buffer)
+		} else {
+			writer = gzip.NewWriter(&buffer)
+		}
+		_, err := writer.Write(data)
+		require.NoError(test, err)
+		require.NoError(test, writer.Close())
+		return buffer.Bytes()
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		writer.Header().Set("X-Accept-Encoding", request.Header.Get("Accept-Encoding"))
+		switch request.URL.Path {
+		case "/gzip":
+			writer.Header().Set("Content-Encoding", "gzip")
+			_, _ = writer.Write(compress("gzip", []byte("gzip body")))
+		case "/stacked":
+			writer.Header().Set("Content-Encoding", "deflate, gzip")
+			_, _ = writer.Write(compress("gzip", compress("deflate", []byte("stacked body"))))
+		case "/unknown":
+			writer.Header().Set("Content-Encoding", "br")
+			_, _ = writer.Write([]byte("raw"))
+		case "/bomb":
+			writer.Header().Set("Content-Encoding", "gzip")
+			_, _ = writer.Write(compress("gzip", make([]byte, 1024*1024)))
+		case "/invalid":
+			writer.Header().Set("Content-Encoding", "gzip")
+			_, _ = writer.Write([]byte("invalid"))
+		}
+	}))
+	defer server.Close()
+
+	client := new(Client)
+	client.Decompress = true
+	client.ResponseSize = 1024
+	get := func(path string) (*http.Response, string, error) {
+		response, err := client.Get(server.URL + path)
+		if err != nil {
+			return response, "", err
+		}
+		body, err := io.ReadAll(response.Body)
+		require.NoError(test, response.Body.Close())
+		return response, string(body), err
+	}
+
+	response, body, err := get("/gzip")
+	require.NoError(test, err)
+	require.Equal(test, "gzip body", body)
+	require.Equal(test, "gzip, deflate", response.Header.Get("X-Accept-Encoding"))
+	require.Empty(test, response.Header.Get("Content-Encoding"))
+	require.True(test, response.Uncompressed)
+
+	_, body, err = get("/stacked")
+	require.NoError(test, err)
+	require.Equal(test, "stacked body", body)
+
+	response, body, err = get("/unknown")
+	require.NoError(test, err)
+	require.Equal(test, "raw", body)
+	require.Equal(test, "br", response.Header.Get("Content-Encoding"))
+
+	_, _, err = get("/bomb")
+	require.ErrorIs(test, err, ErrNonRetryable)
+	require.ErrorIs(test, err, ErrResponseSize)
+
+	_, _, err = get("/invalid")
+	require.ErrorIs(test, err, ErrRetryable)
+
+	client.StreamResponse = true
+	_, body, err = get("/gzip")
+	require.NoError(test, err)
+	require.Equal(test, "gzip body", body)
+	_, _, err = get("/bomb")
+	require.ErrorIs(test, err, ErrResponseSize)
+}
+
+type reverseEncoding struct{}
+
+func (reverseEncoding) Encode(writer io.Writer) (io.WriteCloser, error) {
+	return &reverseWriter{writer: writer}, nil
+}
+
+func (reverseEncoding) Decode(reader io.Reader) (io.ReadCloser, error) {
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, err
+	}
+	return io.NopCloser(bytes.NewReader(reverse(data))), nil
+}
+
+type reverseWriter struct {
+	writer io.Writer
+	buffer bytes.Buffer
+}
+
+func (writer *reverseWriter) Write(data []byte) (int, error) {
+	return writer.buffer.Write(data)
+}
+
+func (writer *reverseWriter) Close() error {
+	_, err := writer.writer.Write(reverse(writer.buffer.Bytes()))
+	return err
+}
+
+func reverse(data []byte) []byte {
+	reversed := make([]byte, len(data))
+	for index, value := range data {
+		reversed[len(data)-1-index] = value
+	}
+	return reversed
+}
+
+func TestClient_RegisterEncoding(test *testing.T) {
+	test.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		body, _ := io.ReadAll(request.Body)
+		writer.Header().Set("X-Accept-Encoding", request.Header.Get("Accept-Encoding"))
+		writer.Header().Set("X-Content-Encoding", request.Header.Get("Content-Encoding"))
+		writer.Header().Set("Content-Encoding", "rev")
+		_, _ = writer.Write(body)
+	}))
+	defer server.Close()
+
+	client := new(Client)
+	client.Decompress = true
+	client.RequestEncoding = "rev"
+	response, err := client.Post(server.URL, "text/plain", bytes.NewReader([]byte("unused")))
+	require.ErrorIs(test, err, ErrNonRetryable)
+	require.Nil(test, response)
+
+	client.RegisterEncoding("REV", reverseEncoding{})
+	response, err = client.Post(server.URL, "text/plain", bytes.NewReader([]byte("hello")))
+	require.NoError(test, err)
+	body, err := io.ReadAll(response.Body)
+	require.NoError(test, err)
+	require.NoError(test, response.Body.Close())
+	require.Equal(test, "hello", string(body))
+	require.Equal(test, "gzip, deflate, rev", response.Header.Get("X-Accept-Encoding"))
+	require.Equal(test, "rev", response.Header.Get("X-Content-Encoding"))
+
+	client.RequestEncoding = "gzip"
+	response, err = client.Get(server.URL)
+	require.NoError(test, err)
+	require.NoError(test, response.Body.Close())
+	require.Empty(test, response.Header.Get("X-Content-Encoding"))
+}
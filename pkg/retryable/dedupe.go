@@ -0,0 +1,120 @@
+package retryable
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// DefaultDeduplicateHeaders defines the request headers that must match for
+// requests to be deduplicated, if the client does not specify them.
+var DefaultDeduplicateHeaders = []string{"Accept", "Accept-Encoding", "Accept-Language", "Authorization", "Cookie"}
+
+// flight contains the outcome of a request that is shared with identical
+// concurrent requests.
+type flight struct {
+	// done is closed when the request finishes.
+	done chan struct{}
+
+	// response contains the response of the request, if any.
+	response *http.Response
+
+	// body contains the response body read into memory, if any.
+	body []byte
+
+	// shared specifies whether the outcome can be shared. Context errors of
+	// the request are not shared, since the contexts of identical requests
+	// may still be valid.
+	shared bool
+
+	// err contains the error of the request, if any.
+	err error
+}
+
+// deduplicate sends the request, unless an identical request is already in
+// progress, in which case it waits for the response of that request instead.
+// If the outcome of that request cannot be shared, the waiting requests elect
+// a new request to send. The client must be a snapshot.
+func (client *Client) deduplicate(request *http.Request) (response *http.Response, err error) {
+	// Check for eligible request
+	key, ok := client.deduplicationKey(request)
+	if !ok {
+		return client.retryRequest(request)
+	}
+
+	// Wait for identical request in progress
+	state := client.state()
+	state.mutex.Lock()
+	if existing, ok := state.flights[key]; ok {
+		state.mutex.Unlock()
+		select {
+		case <-existing.done:
+		case <-request.Context().Done():
+			return nil, fmt.Errorf("%w: %w", ErrNonRetryable, request.Context().Err())
+		}
+		if !existing.shared {
+			return client.deduplicate(request)
+		}
+		return existing.share()
+	}
+	current := &flight{done: make(chan struct{})}
+	if state.flights == nil {
+		state.flights = make(map[string]*flight)
+	}
+	state.flights[key] = current
+	state.mutex.Unlock()
+
+	// Send request and share its outcome
+	defer func() {
+		state.mutex.Lock()
+		delete(state.flights, key)
+		state.mutex.Unlock()
+		close(current.done)
+	}()
+	response, err = client.retryRequest(request)
+	current.response, current.err = response, err
+	current.shared = !errors.Is(err, context.Canceled) && !errors.Is(err, context.DeadlineExceeded)
+	if response != nil {
+		var ok bool
+		current.body, ok = BodyBytes(response)
+		current.shared = current.shared && ok
+	}
+	return response, err
+}
+
+// deduplicationKey returns the key that identifies identical requests, and
+// reports whether the request can be deduplicated.
+func (client *Client) deduplicationKey(request *http.Request) (key string, ok bool) {
+	// Check for request without body whose response is read into memory
+	if client.StreamResponse || (request.Method != http.MethodGet && request.Method != http.MethodHead) ||
+		(request.Body != nil && request.Body != http.NoBody) {
+		return "", false
+	}
+
+	// Combine method, URL, operation, and headers
+	headers := client.DeduplicateHeaders
+	if headers == nil {
+		headers = DefaultDeduplicateHeaders
+	}
+	operation, _ := request.Context().Value(operationKey{}).(string)
+	var builder strings.Builder
+	builder.WriteString(request.Method + " " + request.Host + " " + request.URL.String() + "\n" + operation)
+	for _, name := range headers {
+		builder.WriteString("\n" + strings.Join(request.Header.Values(name), ", "))
+	}
+	return builder.String(), true
+}
+
+// share returns a copy of the shared response with its own response body.
+func (flight *flight) share() (response *http.Response, err error) {
+	if flight.response == nil {
+		return nil, flight.err
+	}
+	copied := *flight.response
+	copied.Header = flight.response.Header.Clone()
+	copied.Trailer = flight.response.Trailer.Clone()
+	copied.Body = newBufferedBody(flight.body)
+	return &copied, flight.err
+}
@@ -0,0 +1,177 @@
+package retryable
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestClient_Deduplicate(test *testing.T) {
+	test.Parallel()
+
+	var count atomic.Int32
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		if count.Add(1) == 1 {
+			<-release
+			writer.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		_, _ = writer.Write([]byte("shared " + request.Header.Get("Authorization")))
+	}))
+	defer server.Close()
+
+	client := new(Client)
+	client.RetryCount = 1
+	client.RetryStatus = []int{http.StatusServiceUnavailable}
+	client.Deduplicate = true
+
+	// Share one upstream request and its retries
+	var group sync.WaitGroup
+	bodies := make([]string, 5)
+	for index := range bodies {
+		group.Add(1)
+		go func(index int) {
+			defer group.Done()
+			response, err := client.Get(server.URL)
+			require.NoError(test, err)
+			body, err := io.ReadAll(response.Body)
+			require.NoError(test, err)
+			require.NoError(test, response.Body.Close())
+			bodies[index] = string(body)
+		}(index)
+	}
+	time.Sleep(20 * time.Millisecond)
+	close(release)
+	group.Wait()
+	require.Equal(test, int32(2), count.Load())
+	require.Equal(test, []string{"shared ", "shared ", "shared ", "shared ", "shared "}, bodies)
+
+	// Send requests with different headers separately
+	request, err := http.NewRequest(http.MethodGet, server.URL, nil)
+	require.NoError(test, err)
+	request.Header.Set("Authorization", "Bearer token")
+	key, ok := client.deduplicationKey(request)
+	require.True(test, ok)
+	other, _ := client.deduplicationKey(request.Clone(request.Context()))
+	require.Equal(test, key, other)
+	request.Header.Del("Authorization")
+	other, _ = client.deduplicationKey(request)
+	require.NotEqual(test, key, other)
+	request.Method = http.MethodPost
+	_, ok = client.deduplicationKey(request)
+	require.False(test, ok)
+}
+
+func TestClient_Deduplicate_CanceledLeader(test *testing.T) {
+	test.Parallel()
+
+	var count atomic.Int32
+	started := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		if count.Add(1) == 1 {
+			close(started)
+			<-request.Context().Done()
+			return
+		}
+		_, _ = writer.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	client := new(Client)
+	client.Deduplicate = true
+
+	// Cancel the leader while followers wait for it
+	ctx, cancel := context.WithCancel(context.Background())
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, server.URL, nil)
+	require.NoError(test, err)
+	leader := make(chan error, 1)
+	go func() {
+		_, err := client.Do(request)
+		leader <- err
+	}()
+	<-started
+	var group sync.WaitGroup
+	bodies := make([]string, 3)
+	for index := range bodies {
+		group.Add(1)
+		go func(index int) {
+			defer group.Done()
+			response, err := client.Get(server.URL)
+			require.NoError(test, err)
+			body, err := io.ReadAll(response.Body)
+			require.NoError(test, err)
+			require.NoError(test, response.Body.Close())
+			bodies[index] = string(body)
+		}(index)
+	}
+	time.Sleep(20 * time.Millisecond)
+	cancel()
+	require.ErrorIs(test, <-leader, context.Canceled)
+	group.Wait()
+	require.Equal(test, []string{"ok", "ok", "ok"}, bodies)
+	require.Equal(test, int32(2), count.Load())
+}
+
+func TestClient_Deduplicate_CanceledLeaderWithResponse(test *testing.T) {
+	test.Parallel()
+
+	var count atomic.Int32
+	started := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, _ *http.Request) {
+		if count.Add(1) == 1 {
+			close(started)
+			writer.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		_, _ = writer.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	client := new(Client)
+	client.RetryCount = 1
+	client.RetryDelay = time.Hour
+	client.RetryStatus = []int{http.StatusServiceUnavailable}
+	client.Deduplicate = true
+
+	// Cancel the leader during its retry delay while followers wait for it
+	ctx, cancel := context.WithCancel(context.Background())
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, server.URL, nil)
+	require.NoError(test, err)
+	leader := make(chan error, 1)
+	go func() {
+		response, err := client.Do(request)
+		if response != nil {
+			_ = response.Body.Close()
+		}
+		leader <- err
+	}()
+	<-started
+	var group sync.WaitGroup
+	bodies := make([]string, 3)
+	for index := range bodies {
+		group.Add(1)
+		go func(index int) {
+			defer group.Done()
+			response, err := client.Get(server.URL)
+			require.NoError(test, err)
+			body, err := io.ReadAll(response.Body)
+			require.NoError(test, err)
+			require.NoError(test, response.Body.Close())
+			bodies[index] = string(body)
+		}(index)
+	}
+	time.Sleep(20 * time.Millisecond)
+	cancel()
+	require.ErrorIs(test, <-leader, context.Canceled)
+	group.Wait()
+	require.Equal(test, []string{"ok", "ok", "ok"}, bodies)
+	require.Equal(test, int32(2), count.Load())
+}
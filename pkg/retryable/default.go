@@ -0,0 +1,54 @@
+package retryable
+
+import (
+	"io"
+	"net/http"
+	"net/url"
+	"sync/atomic"
+)
+
+// defaultClient contains the client installed with SetDefault, if any.
+var defaultClient atomic.Pointer[Client]
+
+// Default returns the client installed with [SetDefault], or [DefaultClient]
+// if none was installed. It is safe to call concurrently with [SetDefault].
+func Default() (client *Client) {
+	client = defaultClient.Load()
+	if client == nil {
+		return DefaultClient
+	}
+	return client
+}
+
+// SetDefault installs the client returned by [Default], such as to apply an
+// organization-wide retry policy once at startup. The client must not be
+// modified afterwards; use [Client.UpdatePolicy] to change its retry
+// parameters instead. If the client is nil, [DefaultClient] is restored.
+func SetDefault(client *Client) {
+	defaultClient.Store(client)
+}
+
+// Get issues a GET to the specified URL with the client returned by
+// [Default], as a drop-in replacement for [net/http.Get].
+func Get(url string) (response *http.Response, err error) {
+	return Default().Get(url)
+}
+
+// Head issues a HEAD to the specified URL with the client returned by
+// [Default], as a drop-in replacement for [net/http.Head].
+func Head(url string) (response *http.Response, err error) {
+	return Default().Head(url)
+}
+
+// Post issues a POST to the specified URL with the client returned by
+// [Default], as a drop-in replacement for [net/http.Post].
+func Post(url string, contentType string, body io.Reader) (response *http.Response, err error) {
+	return Default().Post(url, contentType, body)
+}
+
+// PostForm issues a POST to the specified URL, with data's keys and values
+// URL-encoded as the request body, with the client returned by [Default], as
+// a drop-in replacement for [net/http.PostForm].
+func PostForm(url string, data url.Values) (response *http.Response, err error) {
+	return Default().PostForm(url, data)
+}
@@ -0,0 +1,58 @@
+package retryable
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSetDefault(test *testing.T) {
+	defer SetDefault(nil)
+
+	require.Same(test, DefaultClient, Default())
+
+	client := &Client{RetryCount: 7}
+	var group sync.WaitGroup
+	for index := 0; index < 10; index++ {
+		group.Add(1)
+		go func() {
+			defer group.Done()
+			SetDefault(client)
+			_ = Default()
+		}()
+	}
+	group.Wait()
+	require.Same(test, client, Default())
+	require.Equal(test, 7, DefaultConfig().RetryCount)
+
+	SetDefault(nil)
+	require.Same(test, DefaultClient, Default())
+	require.Equal(test, DefaultClient.RetryCount, DefaultConfig().RetryCount)
+}
+
+func TestGet(test *testing.T) {
+	defer SetDefault(nil)
+
+	var methods []string
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		methods = append(methods, request.Method+" "+request.Header.Get("Content-Type"))
+	}))
+	defer server.Close()
+
+	SetDefault(new(Client))
+	response, err := Get(server.URL)
+	require.NoError(test, err)
+	require.Equal(test, http.StatusOK, response.StatusCode)
+	_, err = Head(server.URL)
+	require.NoError(test, err)
+	_, err = Post(server.URL, "text/plain", strings.NewReader("xyz"))
+	require.NoError(test, err)
+	_, err = PostForm(server.URL, url.Values{"key": {"value"}})
+	require.NoError(test, err)
+	require.Equal(test, []string{"GET ", "HEAD ", "POST text/plain", "POST application/x-www-form-urlencoded"}, methods)
+}
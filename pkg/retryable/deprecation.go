@@ -0,0 +1,165 @@
+package retryable
+
+import (
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxDeprecations is the maximum number of deprecated endpoints that are
+// retained by a client.
+const maxDeprecations = 256
+
+// defaultDeprecationLogInterval is the default minimum interval between
+// warnings logged for the same deprecated endpoint.
+const defaultDeprecationLogInterval = time.Hour
+
+// Deprecation describes the deprecation of an endpoint, as announced by the
+// Deprecation (RFC 9745) and Sunset (RFC 8594) response headers.
+type Deprecation struct {
+	// Endpoint specifies the method and URL of the endpoint, without the
+	// query string.
+	Endpoint string
+
+	// Deprecated specifies whether the Deprecation header was present.
+	Deprecated bool
+
+	// Date specifies when the endpoint was or will be deprecated, if known.
+	Date time.Time
+
+	// Sunset specifies when the endpoint will stop responding, if known.
+	Sunset time.Time
+
+	// Link specifies the URL of the documentation of the deprecation or
+	// sunset, if any.
+	Link string
+
+	// Seen specifies when the headers were most recently received.
+	Seen time.Time
+
+	// Logged specifies when a warning was most recently logged for the
+	// endpoint, if any.
+	Logged time.Time
+}
+
+// Deprecations returns the deprecated endpoints that the client has received
+// responses from, sorted by endpoint, so that consumers of third-party APIs
+// can report impending endpoint removals in their own telemetry.
+func (client *Client) Deprecations() (deprecations []Deprecation) {
+	state := client.state()
+	state.mutex.Lock()
+	defer state.mutex.Unlock()
+	for _, deprecation := range state.deprecations {
+		deprecations = append(deprecations, deprecation)
+	}
+	sort.Slice(deprecations, func(i int, j int) bool {
+		return deprecations[i].Endpoint < deprecations[j].Endpoint
+	})
+	return deprecations
+}
+
+// checkDeprecation records the deprecation announced by the headers of the
+// response, notifies the deprecation hook, and logs a rate-limited warning.
+func (client *Client) checkDeprecation(response *http.Response) {
+	// Check for deprecation headers
+	deprecation, ok := parseDeprecation(response)
+	if !ok {
+		return
+	}
+	deprecation.Seen = client.receivedAt(response)
+
+	// Record deprecation, rate limiting warnings per endpoint
+	state := client.state()
+	state.mutex.Lock()
+	if state.deprecations == nil {
+		state.deprecations = make(map[string]Deprecation)
+	}
+	previous, seen := state.deprecations[deprecation.Endpoint]
+	if !seen && len(state.deprecations) >= maxDeprecations {
+		for existing := range state.deprecations {
+			delete(state.deprecations, existing)
+			break
+		}
+	}
+	deprecation.Logged = previous.Logged
+	warn := client.DeprecationLogger != nil &&
+		(previous.Logged.IsZero() || deprecation.Seen.Sub(previous.Logged) >= client.deprecationLogInterval())
+	if warn {
+		deprecation.Logged = deprecation.Seen
+	}
+	state.deprecations[deprecation.Endpoint] = deprecation
+	state.mutex.Unlock()
+
+	// Notify hook and log warning
+	if client.OnDeprecation != nil {
+		client.OnDeprecation(deprecation)
+	}
+	if warn {
+		client.DeprecationLogger.Print(deprecationWarning(deprecation))
+	}
+}
+
+// deprecationLogInterval returns the minimum interval between warnings for the
+// same deprecated endpoint.
+func (client *Client) deprecationLogInterval() time.Duration {
+	if client.DeprecationLogInterval <= 0 {
+		return defaultDeprecationLogInterval
+	}
+	return client.DeprecationLogInterval
+}
+
+// deprecationWarning returns the warning logged for the deprecation.
+func deprecationWarning(deprecation Deprecation) string {
+	warning := "retryable: endpoint is deprecated: " + deprecation.Endpoint
+	if !deprecation.Sunset.IsZero() {
+		warning += " (sunset " + deprecation.Sunset.UTC().Format(time.RFC3339) + ")"
+	}
+	if deprecation.Link != "" {
+		warning += " see " + deprecation.Link
+	}
+	return warning
+}
+
+// parseDeprecation parses the Deprecation, Sunset, and Link headers of the
+// response, and reports whether the endpoint is deprecated or has a sunset.
+func parseDeprecation(response *http.Response) (deprecation Deprecation, ok bool) {
+	// Check for deprecation headers
+	if response == nil || response.Request == nil || response.Request.URL == nil {
+		return deprecation, false
+	}
+	value := strings.TrimSpace(response.Header.Get("Deprecation"))
+	sunset := strings.TrimSpace(response.Header.Get("Sunset"))
+	if value == "" && sunset == "" {
+		return deprecation, false
+	}
+
+	// Identify endpoint without query string
+	endpoint := *response.Request.URL
+	endpoint.RawQuery = ""
+	endpoint.Fragment = ""
+	endpoint.User = nil
+	method := response.Request.Method
+	if method == "" {
+		method = http.MethodGet
+	}
+	deprecation.Endpoint = method + " " + endpoint.String()
+
+	// Parse structured date, legacy boolean, or HTTP date
+	if value != "" && value != "false" {
+		deprecation.Deprecated = true
+		if seconds, err := strconv.ParseInt(strings.TrimPrefix(value, "@"), 10, 64); err == nil && strings.HasPrefix(value, "@") {
+			deprecation.Date = time.Unix(seconds, 0).UTC()
+		} else if date, err := http.ParseTime(value); err == nil {
+			deprecation.Date = date
+		}
+	}
+	if date, err := http.ParseTime(sunset); err == nil {
+		deprecation.Sunset = date
+	}
+
+	// Parse documentation link
+	deprecation.Link = parseLink(response.Header, "deprecation", "sunset")
+	return deprecation, deprecation.Deprecated || !deprecation.Sunset.IsZero()
+}
@@ -0,0 +1,88 @@
+package retryable
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// Doer defines the methods used to send requests with a [Client], so that
+// consumers can accept an interface and be tested with test doubles such as
+// [NoopClient] and [ErrClient].
+type Doer interface {
+	// Do sends an HTTP request and returns an HTTP response.
+	Do(request *http.Request) (response *http.Response, err error)
+
+	// Get issues a GET to the specified URL.
+	Get(url string) (response *http.Response, err error)
+
+	// Head issues a HEAD to the specified URL.
+	Head(url string) (response *http.Response, err error)
+
+	// Post issues a POST to the specified URL.
+	Post(url string, contentType string, body io.Reader) (response *http.Response, err error)
+
+	// PostForm issues a POST to the specified URL, with data's keys and values
+	// URL-encoded as the request body.
+	PostForm(url string, data url.Values) (response *http.Response, err error)
+}
+
+// DoerFunc is an adapter that allows a function to be used as a [Doer], with
+// the convenience methods implemented on top of the function.
+type DoerFunc func(request *http.Request) (response *http.Response, err error)
+
+// Do calls the function with the request.
+func (do DoerFunc) Do(request *http.Request) (response *http.Response, err error) {
+	return do(request)
+}
+
+// Get issues a GET to the specified URL.
+func (do DoerFunc) Get(url string) (response *http.Response, err error) {
+	// Construct and send HTTP request
+	request, err := http.NewRequestWithContext(context.Background(), http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("%w: unable to construct request: %w", ErrNonRetryable, err)
+	}
+	return do(request)
+}
+
+// Head issues a HEAD to the specified URL.
+func (do DoerFunc) Head(url string) (response *http.Response, err error) {
+	// Construct and send HTTP request
+	request, err := http.NewRequestWithContext(context.Background(), http.MethodHead, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("%w: unable to construct request: %w", ErrNonRetryable, err)
+	}
+	return do(request)
+}
+
+// Post issues a POST to the specified URL.
+func (do DoerFunc) Post(url string, contentType string, body io.Reader) (response *http.Response, err error) {
+	// Construct and send HTTP request
+	request, err := http.NewRequestWithContext(context.Background(), http.MethodPost, url, body)
+	if err != nil {
+		return nil, fmt.Errorf("%w: unable to construct request: %w", ErrNonRetryable, err)
+	}
+	request.Header.Set("Content-Type", contentType)
+	return do(request)
+}
+
+// PostForm issues a POST to the specified URL, with data's keys and values
+// URL-encoded as the request body.
+func (do DoerFunc) PostForm(url string, data url.Values) (response *http.Response, err error) {
+	if data != nil {
+		return do.Post(url, "application/x-www-form-urlencoded", strings.NewReader(data.Encode()))
+	}
+	return do.Post(url, "application/x-www-form-urlencoded", nil)
+}
+
+// Ensure clients and test doubles implement the Doer interface.
+var (
+	_ Doer = (*Client)(nil)
+	_ Doer = (*NoopClient)(nil)
+	_ Doer = (*ErrClient)(nil)
+	_ Doer = DoerFunc(nil)
+)
@@ -0,0 +1,28 @@
+package retryable
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDoer(test *testing.T) {
+	test.Parallel()
+
+	status := func(doer Doer) (int, error) {
+		response, err := doer.Get("https://www.github.com/")
+		if err != nil {
+			return 0, err
+		}
+		return response.StatusCode, nil
+	}
+
+	code, err := status(&NoopClient{StatusCode: http.StatusAccepted})
+	require.NoError(test, err)
+	require.Equal(test, http.StatusAccepted, code)
+
+	code, err = status(new(ErrClient))
+	require.ErrorIs(test, err, ErrNonRetryable)
+	require.Zero(test, code)
+}
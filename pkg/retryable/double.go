@@ -0,0 +1,121 @@
+package retryable
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// NoopClient is a test double for [Client] that never sends requests, and
+// always returns a copy of the canned response.
+type NoopClient struct {
+	// StatusCode specifies the status code of the response. If the status code
+	// is zero, [net/http.StatusOK] is used.
+	StatusCode int
+
+	// Header specifies the headers of the response.
+	Header http.Header
+
+	// Body specifies the body of the response.
+	Body []byte
+}
+
+// Get returns the canned response for a GET to the specified URL.
+func (client *NoopClient) Get(url string) (response *http.Response, err error) {
+	return doubleRequest(client, http.MethodGet, url, "", nil)
+}
+
+// Head returns the canned response for a HEAD to the specified URL.
+func (client *NoopClient) Head(url string) (response *http.Response, err error) {
+	return doubleRequest(client, http.MethodHead, url, "", nil)
+}
+
+// Post returns the canned response for a POST to the specified URL.
+func (client *NoopClient) Post(url string, contentType string, body io.Reader) (response *http.Response, err error) {
+	return doubleRequest(client, http.MethodPost, url, contentType, body)
+}
+
+// PostForm returns the canned response for a POST to the specified URL.
+func (client *NoopClient) PostForm(url string, data url.Values) (response *http.Response, err error) {
+	return doubleRequest(client, http.MethodPost, url, "application/x-www-form-urlencoded", strings.NewReader(data.Encode()))
+}
+
+// Do returns the canned response for the specified request.
+func (client *NoopClient) Do(request *http.Request) (response *http.Response, err error) {
+	// Check for valid request
+	if request == nil {
+		return nil, fmt.Errorf("%w: invalid request", ErrNonRetryable)
+	}
+
+	// Construct canned response
+	status := client.StatusCode
+	if status == 0 {
+		status = http.StatusOK
+	}
+	body := bytes.Clone(client.Body)
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", status, http.StatusText(status)),
+		StatusCode:    status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        client.Header.Clone(),
+		Body:          newBufferedBody(body),
+		ContentLength: int64(len(body)),
+		Request:       request,
+	}, nil
+}
+
+// ErrClient is a test double for [Client] that never sends requests, and
+// always returns the specified error.
+type ErrClient struct {
+	// Err specifies the error that is returned. If the error is nil, a
+	// non-retryable error is returned.
+	Err error
+}
+
+// Get returns the error for a GET to the specified URL.
+func (client *ErrClient) Get(url string) (response *http.Response, err error) {
+	return doubleRequest(client, http.MethodGet, url, "", nil)
+}
+
+// Head returns the error for a HEAD to the specified URL.
+func (client *ErrClient) Head(url string) (response *http.Response, err error) {
+	return doubleRequest(client, http.MethodHead, url, "", nil)
+}
+
+// Post returns the error for a POST to the specified URL.
+func (client *ErrClient) Post(url string, contentType string, body io.Reader) (response *http.Response, err error) {
+	return doubleRequest(client, http.MethodPost, url, contentType, body)
+}
+
+// PostForm returns the error for a POST to the specified URL.
+func (client *ErrClient) PostForm(url string, data url.Values) (response *http.Response, err error) {
+	return doubleRequest(client, http.MethodPost, url, "application/x-www-form-urlencoded", strings.NewReader(data.Encode()))
+}
+
+// Do returns the error for the specified request.
+func (client *ErrClient) Do(_ *http.Request) (response *http.Response, err error) {
+	if client.Err == nil {
+		return nil, fmt.Errorf("%w: request failed", ErrNonRetryable)
+	}
+	return nil, client.Err
+}
+
+// doubleRequest constructs a request for a test double, and passes it to the
+// Do method of the test double.
+func doubleRequest(client Doer, method string, url string, contentType string, body io.Reader) (response *http.Response, err error) {
+	// Construct and send HTTP request
+	request, err := http.NewRequestWithContext(context.Background(), method, url, body)
+	if err != nil {
+		return nil, fmt.Errorf("%w: unable to construct request: %w", ErrNonRetryable, err)
+	}
+	if contentType != "" {
+		request.Header.Set("Content-Type", contentType)
+	}
+	return client.Do(request)
+}
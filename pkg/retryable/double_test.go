@@ -0,0 +1,74 @@
+package retryable
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNoopClient(test *testing.T) {
+	test.Parallel()
+
+	client := new(NoopClient)
+	response, err := client.Get("https://www.github.com/")
+	require.NoError(test, err)
+	require.Equal(test, http.StatusOK, response.StatusCode)
+	require.Equal(test, "200 OK", response.Status)
+	require.Equal(test, http.MethodGet, response.Request.Method)
+
+	client.StatusCode = http.StatusCreated
+	client.Header = http.Header{"Content-Type": {"text/plain"}}
+	client.Body = []byte("xyz")
+	response, err = client.Post("https://www.github.com/", "text/plain", nil)
+	require.NoError(test, err)
+	require.Equal(test, http.StatusCreated, response.StatusCode)
+	require.Equal(test, "text/plain", response.Header.Get("Content-Type"))
+	require.Equal(test, "text/plain", response.Request.Header.Get("Content-Type"))
+	require.Equal(test, int64(3), response.ContentLength)
+
+	buffer, err := io.ReadAll(response.Body)
+	require.NoError(test, err)
+	require.Equal(test, "xyz", string(buffer))
+
+	response, err = client.Head("https://www.github.com/")
+	require.NoError(test, err)
+	require.Equal(test, http.MethodHead, response.Request.Method)
+
+	response, err = client.PostForm("https://www.github.com/", url.Values{"x": {"y"}})
+	require.NoError(test, err)
+	require.Equal(test, "application/x-www-form-urlencoded", response.Request.Header.Get("Content-Type"))
+
+	response, err = client.Get(string([]byte{0x7F}))
+	require.ErrorIs(test, err, ErrNonRetryable)
+	require.Nil(test, response)
+
+	response, err = client.Do(nil)
+	require.ErrorIs(test, err, ErrNonRetryable)
+	require.Nil(test, response)
+}
+
+func TestErrClient(test *testing.T) {
+	test.Parallel()
+
+	client := new(ErrClient)
+	response, err := client.Get("https://www.github.com/")
+	require.ErrorIs(test, err, ErrNonRetryable)
+	require.Nil(test, response)
+
+	client.Err = errors.New("xyz")
+	response, err = client.Head("https://www.github.com/")
+	require.ErrorIs(test, err, client.Err)
+	require.Nil(test, response)
+
+	response, err = client.Post("https://www.github.com/", "text/plain", nil)
+	require.ErrorIs(test, err, client.Err)
+	require.Nil(test, response)
+
+	response, err = client.PostForm("https://www.github.com/", nil)
+	require.ErrorIs(test, err, client.Err)
+	require.Nil(test, response)
+}
@@ -0,0 +1,144 @@
+package retryable
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// ErrDraining defines an error for retries that were abandoned because the
+// client is draining.
+var ErrDraining = errors.New("client is draining")
+
+// Drain switches the client into drain mode, typically in response to an
+// infrastructure signal such as SIGTERM or a spot-instance interruption
+// notice. In drain mode no new retries are scheduled, and pending retry
+// delays are interrupted, but in-flight attempts are allowed to finish. Drain
+// mode cannot be reversed.
+func (client *Client) Drain() {
+	state := client.state()
+	state.drainOnce.Do(func() {
+		close(state.drainChannel())
+	})
+}
+
+// Draining returns true if the client is in drain mode.
+func (client *Client) Draining() bool {
+	select {
+	case <-client.state().drainChannel():
+		return true
+	default:
+		return false
+	}
+}
+
+// DrainOnSignal switches the client into drain mode when any of the specified
+// signals are received. If no signals are specified, [os.Interrupt] and
+// [syscall.SIGTERM] are used. The returned function stops listening for the
+// signals.
+func (client *Client) DrainOnSignal(signals ...os.Signal) (stop func()) {
+	// Apply default signals
+	if len(signals) == 0 {
+		signals = []os.Signal{os.Interrupt, syscall.SIGTERM}
+	}
+
+	// Listen for signals in the background
+	channel := make(chan os.Signal, 1)
+	signal.Notify(channel, signals...)
+	return client.drainOnReceive(channel, func() {
+		signal.Stop(channel)
+	})
+}
+
+// drainOnReceive switches the client into drain mode when a signal is
+// received on the channel. The returned function calls the release function
+// once and stops listening for signals.
+func (client *Client) drainOnReceive(channel <-chan os.Signal, release func()) (stop func()) {
+	// Listen for signals in the background
+	done := make(chan struct{})
+	go func() {
+		select {
+		case <-channel:
+			client.Drain()
+		case <-done:
+		}
+	}()
+
+	// Stop listening for signals
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			release()
+			close(done)
+		})
+	}
+}
+
+// drainChannel returns a channel that is closed when the client starts
+// draining, initializing it if required.
+func (state *clientState) drainChannel() chan struct{} {
+	state.mutex.Lock()
+	defer state.mutex.Unlock()
+	if state.draining == nil {
+		state.draining = make(chan struct{})
+	}
+	return state.draining
+}
+
+// sleepUnlessDraining pauses the current goroutine for the specified
+// duration, or until the context is canceled or the client starts draining.
+// Since shutting down the client also drains it, the delay is interrupted by
+// [Client.Shutdown] as well.
+func (client *Client) sleepUnlessDraining(ctx context.Context, duration time.Duration) (err error) {
+	// Check for drain mode
+	draining := client.state().drainChannel()
+	if client.Draining() {
+		return ErrDraining
+	}
+
+	// Sleep for the specified duration
+	interrupted, err := client.interruptibleSleep(ctx, duration, draining)
+	if interrupted {
+		return ErrDraining
+	}
+	return err
+}
+
+// interruptibleSleep pauses the current goroutine for the specified duration,
+// or until the context is canceled or the interrupt channel is closed, and
+// reports whether the interrupt channel ended the sleep.
+func (client *Client) interruptibleSleep(ctx context.Context, duration time.Duration, interrupt <-chan struct{}) (interrupted bool, err error) {
+	// Sleep without watching the interrupt channel if there is no delay
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if duration <= 0 {
+		return false, client.clock().Sleep(ctx, duration)
+	}
+
+	// Cancel the delay when the interrupt channel is closed
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	go func() {
+		select {
+		case <-interrupt:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	// Sleep for the specified duration
+	err = client.clock().Sleep(ctx, duration)
+	if err != nil {
+		select {
+		case <-interrupt:
+			return true, err
+		default:
+		}
+	}
+	return false, err
+}
@@ -0,0 +1,69 @@
+package retryable
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestClient_Drain(test *testing.T) {
+	test.Parallel()
+
+	var attempts atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, _ *http.Request) {
+		attempts.Add(1)
+		writer.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	client := new(Client)
+	client.RetryCount = 3
+	client.RetryDelay = time.Hour
+	client.RetryStatus = []int{http.StatusServiceUnavailable}
+	require.False(test, client.Draining())
+
+	go func() {
+		for attempts.Load() == 0 {
+			time.Sleep(time.Millisecond)
+		}
+		client.Drain()
+	}()
+	timestamp := time.Now()
+	response, err := client.Get(server.URL)
+	require.ErrorIs(test, err, ErrNonRetryable)
+	require.ErrorIs(test, err, ErrDraining)
+	require.NotNil(test, response)
+	require.Less(test, time.Since(timestamp), time.Minute)
+	require.Equal(test, int32(1), attempts.Load())
+	require.True(test, client.Draining())
+
+	client.Drain()
+	response, err = client.Get(server.URL)
+	require.ErrorIs(test, err, ErrDraining)
+	require.NotNil(test, response)
+	require.Equal(test, int32(2), attempts.Load())
+}
+
+func TestClient_DrainOnSignal(test *testing.T) {
+	test.Parallel()
+
+	client := new(Client)
+	stop := client.DrainOnSignal()
+	stop()
+	stop()
+	require.False(test, client.Draining())
+
+	released := 0
+	channel := make(chan os.Signal, 1)
+	stop = client.drainOnReceive(channel, func() { released++ })
+	channel <- os.Interrupt
+	require.Eventually(test, client.Draining, time.Second, time.Millisecond)
+	stop()
+	stop()
+	require.Equal(test, 1, released)
+}
@@ -0,0 +1,302 @@
+package retryable
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+)
+
+// EndpointSelector defines the selection of a backend endpoint for each
+// attempt of a request, so that retries can fail over between multiple
+// backends, such as primary and secondary regions. Each endpoint is sent on
+// its own copy of the transport. If the selector also implements
+// [EndpointHealth], the idle connections to an endpoint are closed whenever
+// it becomes unhealthy, so that later retries are not sent on pooled
+// connections to the failed backend.
+type EndpointSelector interface {
+	// SelectEndpoint returns the endpoint for the attempt of the request, or
+	// nil to keep the URL of the request. Only the scheme and host of the
+	// endpoint are used.
+	SelectEndpoint(attempt int, request *http.Request) (endpoint *url.URL, err error)
+
+	// ReportEndpoint reports the outcome of an attempt sent to the endpoint,
+	// where a nil error indicates that the attempt did not fail with a
+	// retryable error.
+	ReportEndpoint(endpoint *url.URL, err error)
+}
+
+// maxEndpointTransports is the maximum number of endpoint transports that are
+// retained by a client.
+const maxEndpointTransports = 64
+
+// endpointTransportKey identifies the copy of a transport that is used for an
+// endpoint.
+type endpointTransportKey struct {
+	// base specifies the transport that is copied.
+	base *http.Transport
+
+	// host specifies the normalized host of the endpoint.
+	host string
+}
+
+// EndpointHealth defines an optional interface of an [EndpointSelector] that
+// reports the health of its endpoints.
+type EndpointHealth interface {
+	// Healthy reports whether the endpoint is currently considered healthy.
+	Healthy(endpoint *url.URL) bool
+}
+
+// FailoverEndpointSelector is an [EndpointSelector] that prefers endpoints in
+// order, and fails over to the next healthy endpoint on each retry. Endpoints
+// are marked unhealthy after repeated consecutive failures, and are skipped
+// until the cooldown period has elapsed. If every endpoint is unhealthy, all
+// endpoints are used.
+type FailoverEndpointSelector struct {
+	// Endpoints specifies the endpoints in order of preference.
+	Endpoints []*url.URL
+
+	// FailureThreshold specifies the number of consecutive failures after
+	// which an endpoint is marked unhealthy. If the threshold is zero, an
+	// endpoint is marked unhealthy after three consecutive failures.
+	FailureThreshold int
+
+	// Cooldown specifies how long an unhealthy endpoint is skipped. If the
+	// cooldown is zero, unhealthy endpoints are skipped for one minute.
+	Cooldown time.Duration
+
+	// Clock specifies the time source. If the clock is nil, the system time
+	// is used.
+	Clock Clock
+
+	// mutex guards access to the health of the endpoints.
+	mutex sync.Mutex
+
+	// health contains the health of each endpoint.
+	health map[string]*endpointHealth
+}
+
+// endpointHealth contains the consecutive failures of an endpoint.
+type endpointHealth struct {
+	// failures specifies the number of consecutive failures.
+	failures int
+
+	// unhealthy specifies when the endpoint was marked unhealthy.
+	unhealthy time.Time
+}
+
+// SelectEndpoint returns the healthy endpoint for the attempt, starting with
+// the most preferred healthy endpoint on the first attempt.
+func (selector *FailoverEndpointSelector) SelectEndpoint(attempt int, _ *http.Request) (endpoint *url.URL, err error) {
+	// Check for endpoints
+	if len(selector.Endpoints) == 0 {
+		return nil, nil
+	}
+	selector.mutex.Lock()
+	defer selector.mutex.Unlock()
+
+	// Select from healthy endpoints, or all endpoints if none are healthy
+	healthy := make([]*url.URL, 0, len(selector.Endpoints))
+	for _, candidate := range selector.Endpoints {
+		if selector.healthy(candidate) {
+			healthy = append(healthy, candidate)
+		}
+	}
+	if len(healthy) == 0 {
+		healthy = selector.Endpoints
+	}
+	return healthy[attempt%len(healthy)], nil
+}
+
+// ReportEndpoint records the failure of the endpoint, marking it unhealthy
+// once the failure threshold is reached, or clears its failures on success.
+func (selector *FailoverEndpointSelector) ReportEndpoint(endpoint *url.URL, err error) {
+	// Check for endpoint
+	if endpoint == nil {
+		return
+	}
+	selector.mutex.Lock()
+	defer selector.mutex.Unlock()
+
+	// Record outcome
+	key := endpoint.String()
+	if err == nil {
+		delete(selector.health, key)
+		return
+	}
+	if selector.health == nil {
+		selector.health = make(map[string]*endpointHealth)
+	}
+	health, ok := selector.health[key]
+	if !ok {
+		health = new(endpointHealth)
+		selector.health[key] = health
+	}
+	health.failures++
+	if health.failures >= selector.failureThreshold() {
+		health.unhealthy = selector.now()
+	}
+}
+
+// Healthy reports whether the endpoint is currently considered healthy.
+func (selector *FailoverEndpointSelector) Healthy(endpoint *url.URL) bool {
+	selector.mutex.Lock()
+	defer selector.mutex.Unlock()
+	return selector.healthy(endpoint)
+}
+
+// healthy reports whether the endpoint is healthy, while holding the lock.
+func (selector *FailoverEndpointSelector) healthy(endpoint *url.URL) bool {
+	health, ok := selector.health[endpoint.String()]
+	if !ok || health.unhealthy.IsZero() {
+		return true
+	}
+	return selector.now().Sub(health.unhealthy) >= selector.cooldown()
+}
+
+// failureThreshold returns the number of consecutive failures after which an
+// endpoint is marked unhealthy.
+func (selector *FailoverEndpointSelector) failureThreshold() int {
+	if selector.FailureThreshold <= 0 {
+		return 3
+	}
+	return selector.FailureThreshold
+}
+
+// cooldown returns the cooldown period of unhealthy endpoints.
+func (selector *FailoverEndpointSelector) cooldown() time.Duration {
+	if selector.Cooldown <= 0 {
+		return time.Minute
+	}
+	return selector.Cooldown
+}
+
+// now returns the current time of the clock.
+func (selector *FailoverEndpointSelector) now() time.Time {
+	if selector.Clock == nil {
+		return time.Now()
+	}
+	return selector.Clock.Now()
+}
+
+// selectEndpoint rewrites the scheme and host of the request to the endpoint
+// selected for the attempt, and records the endpoint in the attempt metadata.
+func (client *Client) selectEndpoint(request *http.Request) (endpoint *url.URL, err error) {
+	// Check for endpoint selector
+	if client.EndpointSelector == nil {
+		return nil, nil
+	}
+
+	// Select endpoint for attempt, unless determined by an endpoint race
+	ctx := request.Context()
+	endpoint = racedEndpoint(ctx)
+	if endpoint == nil {
+		endpoint, err = client.EndpointSelector.SelectEndpoint(attemptNumber(ctx), request)
+		if err != nil {
+			return nil, fmt.Errorf("%w: unable to select endpoint: %w", ErrRetryable, err)
+		}
+	}
+	if endpoint == nil {
+		return nil, nil
+	}
+
+	// Rewrite request to endpoint
+	request.URL.Scheme = endpoint.Scheme
+	request.URL.Host = endpoint.Host
+	request.Host = ""
+	client.recordEndpoint(ctx, endpoint)
+	return endpoint, nil
+}
+
+// reportEndpoint reports the outcome of the attempt to the endpoint selector,
+// treating retryable errors and timeouts as failures of the endpoint, and
+// ignoring attempts that were canceled or failed with a permanent protocol
+// error. Idle connections to the endpoint are closed once it becomes
+// unhealthy.
+func (client *Client) reportEndpoint(endpoint *url.URL, err *error) {
+	// Check for selected endpoint
+	if client.EndpointSelector == nil || endpoint == nil || errors.Is(*err, context.Canceled) || errors.Is(*err, ErrPermanentStatus) {
+		return
+	}
+
+	// Report outcome
+	if !errors.Is(*err, ErrRetryable) && !errors.Is(*err, context.DeadlineExceeded) {
+		client.EndpointSelector.ReportEndpoint(endpoint, nil)
+		return
+	}
+	health, ok := client.EndpointSelector.(EndpointHealth)
+	healthy := ok && health.Healthy(endpoint)
+	client.EndpointSelector.ReportEndpoint(endpoint, *err)
+
+	// Evict pooled connections once the endpoint becomes unhealthy
+	if healthy && !health.Healthy(endpoint) {
+		client.closeEndpointConnections(endpoint)
+	}
+}
+
+// endpointTransport returns the copy of the transport that is used for the
+// endpoint, so that idle connections to the endpoint can be closed without
+// closing the connections to other endpoints. If no endpoint is selected, or
+// the transport is not an [net/http.Transport], it is returned unchanged.
+func (client *Client) endpointTransport(endpoint *url.URL, base http.RoundTripper) http.RoundTripper {
+	// Check for selected endpoint
+	if endpoint == nil {
+		return base
+	}
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	transport, ok := base.(*http.Transport)
+	if !ok {
+		return base
+	}
+
+	// Reuse endpoint transport, so that connections are pooled
+	key := endpointTransportKey{base: transport, host: normalizeHost(endpoint.Scheme, endpoint.Host)}
+	state := client.state()
+	state.mutex.Lock()
+	defer state.mutex.Unlock()
+	if existing, ok := state.endpointTransports[key]; ok {
+		return existing
+	}
+
+	// Store copy of the transport, evicting an arbitrary copy if required
+	if state.endpointTransports == nil {
+		state.endpointTransports = make(map[endpointTransportKey]*http.Transport)
+	}
+	if len(state.endpointTransports) >= maxEndpointTransports {
+		for other, evicted := range state.endpointTransports {
+			evicted.CloseIdleConnections()
+			delete(state.endpointTransports, other)
+			break
+		}
+	}
+	copied := transport.Clone()
+	state.endpointTransports[key] = copied
+	return copied
+}
+
+// closeEndpointConnections closes the idle connections of the transports that
+// are used for the endpoint, without closing connections to other endpoints.
+func (client *Client) closeEndpointConnections(endpoint *url.URL) {
+	host := normalizeHost(endpoint.Scheme, endpoint.Host)
+	for _, transport := range client.state().endpointTransportsFor(host) {
+		transport.CloseIdleConnections()
+	}
+}
+
+// endpointTransportsFor returns the transports that are used for the host, or
+// every endpoint transport if the host is empty.
+func (state *clientState) endpointTransportsFor(host string) (transports []*http.Transport) {
+	state.mutex.Lock()
+	defer state.mutex.Unlock()
+	for key, transport := range state.endpointTransports {
+		if host == "" || key.host == host {
+			transports = append(transports, transport)
+		}
+	}
+	return transports
+}
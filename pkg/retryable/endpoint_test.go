@@ -0,0 +1,177 @@
+package retryable
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestClient_EndpointSelector(test *testing.T) {
+	test.Parallel()
+
+	primary := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		writer.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer primary.Close()
+	secondary := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		_, _ = writer.Write([]byte(request.URL.Path))
+	}))
+	defer secondary.Close()
+	primaryURL, err := url.Parse(primary.URL)
+	require.NoError(test, err)
+	secondaryURL, err := url.Parse(secondary.URL)
+	require.NoError(test, err)
+
+	var attempts []Attempt
+	selector := &FailoverEndpointSelector{Endpoints: []*url.URL{primaryURL, secondaryURL}, FailureThreshold: 2}
+	client := new(Client)
+	client.RetryCount = 1
+	client.RetryStatus = []int{http.StatusServiceUnavailable}
+	client.EndpointSelector = selector
+	client.OnAttempt = func(attempt Attempt) {
+		attempts = append(attempts, attempt)
+	}
+	for index := 0; index < 2; index++ {
+		response, err := client.Get("http://service.invalid/resource")
+		require.NoError(test, err)
+		require.NoError(test, response.Body.Close())
+	}
+	require.Len(test, attempts, 4)
+	require.Equal(test, primaryURL.String(), attempts[0].Endpoint)
+	require.Equal(test, secondaryURL.String(), attempts[1].Endpoint)
+	require.False(test, selector.Healthy(primaryURL))
+	require.True(test, selector.Healthy(secondaryURL))
+
+	attempts = nil
+	response, err := client.Get("http://service.invalid/resource")
+	require.NoError(test, err)
+	require.NoError(test, response.Body.Close())
+	require.Len(test, attempts, 1)
+	require.Equal(test, secondaryURL.String(), attempts[0].Endpoint)
+}
+
+func TestFailoverEndpointSelector(test *testing.T) {
+	test.Parallel()
+
+	primary, secondary := &url.URL{Scheme: "https", Host: "primary"}, &url.URL{Scheme: "https", Host: "secondary"}
+	clock := &MockClock{now: time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC)}
+	selector := &FailoverEndpointSelector{Clock: clock}
+	endpoint, err := selector.SelectEndpoint(0, nil)
+	require.NoError(test, err)
+	require.Nil(test, endpoint)
+
+	selector.Endpoints = []*url.URL{primary, secondary}
+	endpoint, err = selector.SelectEndpoint(1, nil)
+	require.NoError(test, err)
+	require.Same(test, secondary, endpoint)
+
+	for index := 0; index < 3; index++ {
+		selector.ReportEndpoint(primary, errors.New("failed"))
+	}
+	require.False(test, selector.Healthy(primary))
+	endpoint, err = selector.SelectEndpoint(0, nil)
+	require.NoError(test, err)
+	require.Same(test, secondary, endpoint)
+
+	for index := 0; index < 3; index++ {
+		selector.ReportEndpoint(secondary, errors.New("failed"))
+	}
+	endpoint, err = selector.SelectEndpoint(0, nil)
+	require.NoError(test, err)
+	require.Same(test, primary, endpoint)
+
+	clock.now = clock.now.Add(time.Minute)
+	require.True(test, selector.Healthy(primary))
+	selector.ReportEndpoint(secondary, nil)
+	require.True(test, selector.Healthy(secondary))
+	selector.ReportEndpoint(nil, errors.New("ignored"))
+}
+
+func TestClient_ReportEndpoint(test *testing.T) {
+	test.Parallel()
+
+	endpoint := &url.URL{Scheme: "https", Host: "primary"}
+	selector := &FailoverEndpointSelector{Endpoints: []*url.URL{endpoint}, FailureThreshold: 1}
+	client := new(Client)
+	client.EndpointSelector = selector
+
+	err := error(context.Canceled)
+	client.reportEndpoint(endpoint, &err)
+	require.True(test, selector.Healthy(endpoint))
+	err = ErrNonRetryable
+	client.reportEndpoint(endpoint, &err)
+	require.True(test, selector.Healthy(endpoint))
+	err = context.DeadlineExceeded
+	client.reportEndpoint(endpoint, &err)
+	require.False(test, selector.Healthy(endpoint))
+}
+
+func TestClient_EndpointEviction(test *testing.T) {
+	test.Parallel()
+
+	// Count connections opened and closed by each endpoint
+	var primaryClosed, secondaryOpened, secondaryClosed atomic.Int32
+	primary := httptest.NewUnstartedServer(http.HandlerFunc(func(writer http.ResponseWriter, _ *http.Request) {
+		writer.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	primary.Config.ConnState = func(_ net.Conn, state http.ConnState) {
+		if state == http.StateClosed {
+			primaryClosed.Add(1)
+		}
+	}
+	primary.Start()
+	defer primary.Close()
+	secondary := httptest.NewUnstartedServer(http.HandlerFunc(func(writer http.ResponseWriter, _ *http.Request) {
+		writer.WriteHeader(http.StatusOK)
+	}))
+	secondary.Config.ConnState = func(_ net.Conn, state http.ConnState) {
+		switch state {
+		case http.StateNew:
+			secondaryOpened.Add(1)
+		case http.StateClosed:
+			secondaryClosed.Add(1)
+		}
+	}
+	secondary.Start()
+	defer secondary.Close()
+	primaryURL, err := url.Parse(primary.URL)
+	require.NoError(test, err)
+	secondaryURL, err := url.Parse(secondary.URL)
+	require.NoError(test, err)
+
+	client := new(Client)
+	client.Transport = &http.Transport{}
+	client.RetryCount = 1
+	client.RetryStatus = []int{http.StatusServiceUnavailable}
+	client.EndpointSelector = &FailoverEndpointSelector{Endpoints: []*url.URL{primaryURL, secondaryURL}, FailureThreshold: 2}
+
+	// Keep idle connections while the primary endpoint is healthy
+	response, err := client.Get("http://service.invalid/")
+	require.NoError(test, err)
+	require.NoError(test, response.Body.Close())
+	time.Sleep(50 * time.Millisecond)
+	require.Zero(test, primaryClosed.Load())
+
+	// Close only the idle connections to the unhealthy primary endpoint
+	response, err = client.Get("http://service.invalid/")
+	require.NoError(test, err)
+	require.NoError(test, response.Body.Close())
+	require.Eventually(test, func() bool {
+		return primaryClosed.Load() == 1
+	}, time.Second, 10*time.Millisecond)
+	require.Zero(test, secondaryClosed.Load())
+	require.Equal(test, int32(1), secondaryOpened.Load())
+
+	client.CloseIdleConnections()
+	require.Eventually(test, func() bool {
+		return secondaryClosed.Load() == 1
+	}, time.Second, 10*time.Millisecond)
+}
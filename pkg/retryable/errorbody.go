@@ -0,0 +1,125 @@
+package retryable
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// DefaultErrorBodySize defines the default maximum number of bytes of the
+// response body attached to errors for invalid status codes.
+const DefaultErrorBodySize = 512
+
+// DefaultErrorHeaders contains the default response headers attached to errors
+// for invalid status codes, which identify the failed request to the server.
+var DefaultErrorHeaders = []string{
+	"Content-Type",
+	"Retry-After",
+	"X-Request-Id",
+	"X-Amzn-Requestid",
+	"Cf-Ray",
+}
+
+// statusError is an error for an invalid status code, with the truncated
+// response body and selected response headers attached.
+type statusError struct {
+	error
+
+	// body specifies the truncated response body.
+	body []byte
+
+	// truncated specifies whether the response body was truncated.
+	truncated bool
+
+	// header specifies the selected response headers.
+	header http.Header
+}
+
+// Error returns the error message, followed by the selected response headers
+// and the truncated response body.
+func (err *statusError) Error() string {
+	// Append selected response headers
+	var builder strings.Builder
+	builder.WriteString(err.error.Error())
+	if len(err.header) > 0 {
+		names := make([]string, 0, len(err.header))
+		for name, values := range err.header {
+			names = append(names, name+": "+strings.Join(values, ", "))
+		}
+		sort.Strings(names)
+		builder.WriteString(" [" + strings.Join(names, "; ") + "]")
+	}
+
+	// Append truncated response body
+	if len(err.body) > 0 {
+		builder.WriteString(fmt.Sprintf(": %q", err.body))
+		if err.truncated {
+			builder.WriteString("...")
+		}
+	}
+	return builder.String()
+}
+
+// Unwrap returns the underlying error.
+func (err *statusError) Unwrap() error {
+	return err.error
+}
+
+// ErrorBody returns the truncated response body attached to an error for an
+// invalid status code, or nil if none was attached.
+func ErrorBody(err error) (body []byte) {
+	var target *statusError
+	if errors.As(err, &target) {
+		return target.body
+	}
+	return nil
+}
+
+// ErrorHeader returns the selected response headers attached to an error for
+// an invalid status code, or nil if none were attached.
+func ErrorHeader(err error) (header http.Header) {
+	var target *statusError
+	if errors.As(err, &target) {
+		return target.header
+	}
+	return nil
+}
+
+// attachErrorBody attaches the truncated response body and the selected
+// response headers to an error for an invalid status code.
+func (client *Client) attachErrorBody(err error, response *http.Response, buffer []byte) error {
+	// Check for disabled capture
+	size := client.ErrorBodySize
+	if size < 0 {
+		return err
+	}
+	if size == 0 {
+		size = DefaultErrorBodySize
+	}
+
+	// Truncate response body
+	result := &statusError{error: err}
+	if len(buffer) > size {
+		buffer = buffer[:size]
+		result.truncated = true
+	}
+	result.body = append([]byte(nil), buffer...)
+
+	// Select response headers
+	names := client.ErrorHeaders
+	if names == nil {
+		names = DefaultErrorHeaders
+	}
+	for _, name := range names {
+		values := response.Header.Values(name)
+		if len(values) > 0 {
+			if result.header == nil {
+				result.header = make(http.Header)
+			}
+			result.header[http.CanonicalHeaderKey(name)] = append([]string(nil), values...)
+		}
+	}
+	return result
+}
@@ -0,0 +1,49 @@
+package retryable
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestErrorBody(test *testing.T) {
+	test.Parallel()
+
+	require.Nil(test, ErrorBody(nil))
+	require.Nil(test, ErrorHeader(errors.New("error")))
+
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		writer.Header().Set("Content-Type", "application/json")
+		writer.Header().Set("X-Request-Id", "abc")
+		writer.Header().Set("X-Other", "other")
+		writer.WriteHeader(http.StatusBadRequest)
+		_, _ = writer.Write([]byte(`{"message":"` + strings.Repeat("a", 1024) + `"}`))
+	}))
+	defer server.Close()
+
+	client := new(Client)
+	_, err := client.Get(server.URL)
+	require.ErrorIs(test, err, ErrNonRetryable)
+	require.Len(test, ErrorBody(err), DefaultErrorBodySize)
+	require.Equal(test, `{"message":"aaa`, string(ErrorBody(err)[:15]))
+	require.Equal(test, "abc", ErrorHeader(err).Get("X-Request-Id"))
+	require.Empty(test, ErrorHeader(err).Get("X-Other"))
+	require.Contains(test, err.Error(), `invalid status code (400) [Content-Type: application/json; X-Request-Id: abc]: "{\"message\":\"aaa`)
+	require.True(test, strings.HasSuffix(err.Error(), `aaa"...`))
+
+	client.ErrorBodySize = 4
+	client.ErrorHeaders = []string{"x-other"}
+	_, err = client.Get(server.URL)
+	require.Equal(test, `{"me`, string(ErrorBody(err)))
+	require.Equal(test, http.Header{"X-Other": {"other"}}, ErrorHeader(err))
+
+	client.ErrorBodySize = -1
+	_, err = client.Get(server.URL)
+	require.ErrorIs(test, err, ErrNonRetryable)
+	require.Nil(test, ErrorBody(err))
+	require.Nil(test, ErrorHeader(err))
+}
@@ -0,0 +1,110 @@
+package retryable
+
+import (
+	"net/http"
+	"time"
+)
+
+// defaultEventBuffer defines the capacity of the event channel, if the client
+// does not specify it.
+const defaultEventBuffer = 256
+
+// EventKind specifies the kind of a retry lifecycle event.
+type EventKind string
+
+// Retry lifecycle event kinds.
+const (
+	// EventAttemptStarted is emitted before each attempt is sent.
+	EventAttemptStarted EventKind = "attempt started"
+
+	// EventAttemptFailed is emitted after each attempt that failed.
+	EventAttemptFailed EventKind = "attempt failed"
+
+	// EventSleeping is emitted before the delay preceding a retry.
+	EventSleeping EventKind = "sleeping"
+
+	// EventGaveUp is emitted when a failed request is not retried, because
+	// the error is not retryable, the retry count or delay budget is
+	// exhausted, or a delay before the next attempt was interrupted.
+	EventGaveUp EventKind = "gave up"
+)
+
+// Event describes a step in the retry lifecycle of a request.
+type Event struct {
+	// Time specifies when the event occurred.
+	Time time.Time
+
+	// Kind specifies the kind of the event.
+	Kind EventKind
+
+	// Method specifies the method of the request.
+	Method string
+
+	// URL specifies the redacted URL of the request.
+	URL string
+
+	// Operation specifies the operation name of the request, if any.
+	Operation string
+
+	// Attempt specifies the attempt number, starting from zero.
+	Attempt int
+
+	// StatusCode specifies the status code of the response, or zero if no
+	// response was received.
+	StatusCode int
+
+	// Err specifies the error of the attempt, if any.
+	Err error
+
+	// Delay specifies the retry delay, if the event kind is
+	// [EventSleeping].
+	Delay time.Duration
+}
+
+// Events returns a channel that receives the retry lifecycle events of all
+// requests sent by the client, as an alternative to hooks for consumers that
+// prefer channel pipelines. Events are delivered without blocking requests,
+// so events that do not fit in the channel are dropped and counted in
+// [Stats]. No events are emitted until Events is first called, and the
+// channel is never closed.
+func (client *Client) Events() <-chan Event {
+	state := client.state()
+	state.mutex.Lock()
+	defer state.mutex.Unlock()
+	if state.events.Load() == nil {
+		capacity := client.EventBuffer
+		if capacity <= 0 {
+			capacity = defaultEventBuffer
+		}
+		events := make(chan Event, capacity)
+		state.events.Store(&events)
+	}
+	return *state.events.Load()
+}
+
+// emitEvent delivers the event of the request to the event channel, if any,
+// dropping the event if the channel is full.
+func (client *Client) emitEvent(request *http.Request, event Event, response *http.Response) {
+	// Check for event channel
+	state := client.state()
+	events := state.events.Load()
+	if events == nil {
+		return
+	}
+
+	// Describe request and response
+	event.Time = client.clock().Now()
+	event.Method = request.Method
+	event.URL = request.URL.Redacted()
+	event.Operation, _ = request.Context().Value(operationKey{}).(string)
+	if response != nil {
+		event.StatusCode = response.StatusCode
+	}
+
+	// Deliver without blocking
+	select {
+	case *events <- event:
+	default:
+		state.stats.droppedEvents.Add(1)
+	}
+}
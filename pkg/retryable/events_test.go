@@ -0,0 +1,105 @@
+package retryable
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestClient_Events(test *testing.T) {
+	test.Parallel()
+
+	var count atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		if count.Add(1) == 1 {
+			writer.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer server.Close()
+
+	client := new(Client)
+	client.RetryCount = 1
+	client.RetryDelay = time.Millisecond
+	client.RetryStatus = []int{http.StatusServiceUnavailable}
+	events := client.Events()
+	require.Equal(test, events, client.Events())
+	response, err := client.Get(server.URL)
+	require.NoError(test, err)
+	require.NoError(test, response.Body.Close())
+
+	kinds := []EventKind{EventAttemptStarted, EventAttemptFailed, EventSleeping, EventAttemptStarted}
+	for index, kind := range kinds {
+		event := <-events
+		require.Equal(test, kind, event.Kind)
+		require.Equal(test, http.MethodGet, event.Method)
+		require.Equal(test, server.URL, event.URL)
+		require.Equal(test, index/3, event.Attempt)
+		require.False(test, event.Time.IsZero())
+	}
+	require.Empty(test, events)
+}
+
+func TestClient_EventsGaveUp(test *testing.T) {
+	test.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		writer.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	client := new(Client)
+	client.RetryCount = 1
+	client.RetryDelay = time.Millisecond
+	client.RetryStatus = []int{http.StatusServiceUnavailable}
+	events := client.Events()
+	response, err := client.Get(server.URL)
+	require.ErrorIs(test, err, ErrRetryable)
+	require.NoError(test, response.Body.Close())
+
+	var event Event
+	for len(events) > 0 {
+		event = <-events
+	}
+	require.Equal(test, EventGaveUp, event.Kind)
+	require.Equal(test, 1, event.Attempt)
+	require.Equal(test, http.StatusServiceUnavailable, event.StatusCode)
+	require.ErrorIs(test, event.Err, ErrRetryable)
+
+	// Emit when the retry delay is interrupted
+	client.RetryDelay = time.Minute
+	client.RetryTimeout = 20 * time.Millisecond
+	_, err = client.Get(server.URL)
+	require.ErrorIs(test, err, context.DeadlineExceeded)
+	for len(events) > 0 {
+		event = <-events
+	}
+	require.Equal(test, EventGaveUp, event.Kind)
+	require.Equal(test, 0, event.Attempt)
+	require.ErrorIs(test, event.Err, context.DeadlineExceeded)
+}
+
+func TestClient_EventsDropped(test *testing.T) {
+	test.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		writer.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	client := new(Client)
+	client.RetryCount = 2
+	client.RetryDelay = time.Millisecond
+	client.RetryStatus = []int{http.StatusServiceUnavailable}
+	client.EventBuffer = 1
+	events := client.Events()
+	response, err := client.Get(server.URL)
+	require.ErrorIs(test, err, ErrRetryable)
+	require.NoError(test, response.Body.Close())
+	require.Equal(test, EventAttemptStarted, (<-events).Kind)
+	require.Equal(test, uint64(8), client.Stats().DroppedEvents)
+}
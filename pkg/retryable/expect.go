@@ -0,0 +1,78 @@
+package retryable
+
+import (
+	"net/http"
+	"strings"
+)
+
+// applyExpectContinue sends the Expect: 100-continue header with request
+// bodies of at least the minimum size, or of unknown size, so that the server
+// can reject the request before the body is transmitted. The header is not
+// sent to hosts that previously rejected the expectation.
+func (client *Client) applyExpectContinue(request *http.Request) {
+	// Check for large request body
+	if client.ExpectContinue <= 0 || request.Body == nil || request.Body == http.NoBody ||
+		request.Header.Get("Expect") != "" {
+		return
+	}
+	if request.ContentLength > 0 && request.ContentLength < client.ExpectContinue {
+		return
+	}
+
+	// Check for host that rejected the expectation
+	host := normalizeHost(request.URL.Scheme, request.URL.Host)
+	state := client.state()
+	state.mutex.Lock()
+	_, rejected := state.expectRejected[host]
+	state.mutex.Unlock()
+	if rejected {
+		return
+	}
+	request.Header.Set("Expect", "100-continue")
+}
+
+// isExpectationFailed returns true if the server rejected the Expect:
+// 100-continue header of the request.
+func isExpectationFailed(request *http.Request, response *http.Response) bool {
+	return response != nil && response.StatusCode == http.StatusExpectationFailed &&
+		strings.EqualFold(request.Header.Get("Expect"), "100-continue")
+}
+
+// resendWithoutExpect resends the request without the Expect header after the
+// server rejected the expectation, and remembers the host so that subsequent
+// requests do not send the header. Since the request body was not
+// transmitted, the request is resent immediately. If the request body cannot
+// be reset, the rejected response is returned instead.
+func (client *Client) resendWithoutExpect(base http.Client, request *http.Request, response *http.Response) (*http.Response, error) {
+	// Remember host, evicting an arbitrary host if required
+	host := normalizeHost(request.URL.Scheme, request.URL.Host)
+	state := client.state()
+	state.mutex.Lock()
+	if state.expectRejected == nil {
+		state.expectRejected = make(map[string]struct{})
+	}
+	if len(state.expectRejected) >= maxHostStatus {
+		for existing := range state.expectRejected {
+			delete(state.expectRejected, existing)
+			break
+		}
+	}
+	state.expectRejected[host] = struct{}{}
+	state.mutex.Unlock()
+
+	// Check that request body can be reset
+	if request.GetBody == nil {
+		return response, nil
+	}
+	body, err := request.GetBody()
+	if err != nil {
+		return response, nil
+	}
+	_ = response.Body.Close()
+
+	// Resend request without the Expect header
+	resent := request.Clone(request.Context())
+	resent.Header.Del("Expect")
+	resent.Body = body
+	return base.Do(resent)
+}
@@ -0,0 +1,82 @@
+package retryable
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestClient_ExpectContinue(test *testing.T) {
+	test.Parallel()
+
+	var count atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		if count.Add(1) == 1 {
+			writer.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		_, _ = io.Copy(io.Discard, request.Body)
+	}))
+	defer server.Close()
+
+	payload := bytes.Repeat([]byte("upload"), 1024)
+	var sent atomic.Int64
+	client := new(Client)
+	client.RetryCount = 1
+	client.RetryStatus = []int{http.StatusServiceUnavailable}
+	client.ExpectContinue = 1024
+	client.Transport = &http.Transport{ExpectContinueTimeout: time.Minute}
+	request, err := http.NewRequest(http.MethodPut, server.URL, nil)
+	require.NoError(test, err)
+	request.ContentLength = int64(len(payload))
+	request.GetBody = func() (io.ReadCloser, error) {
+		return io.NopCloser(&countingReader{reader: bytes.NewReader(payload), count: &sent}), nil
+	}
+	request.Body, _ = request.GetBody()
+	response, err := client.Do(request)
+	require.NoError(test, err)
+	require.NoError(test, response.Body.Close())
+	require.Equal(test, int32(2), count.Load())
+	require.Equal(test, int64(len(payload)), sent.Load())
+}
+
+func TestClient_ExpectationFailed(test *testing.T) {
+	test.Parallel()
+
+	var expects []string
+	client := new(Client)
+	client.ExpectContinue = 1
+	client.Transport = MockTransport(func(request *http.Request) (*http.Response, error) {
+		expects = append(expects, request.Header.Get("Expect"))
+		status := http.StatusOK
+		if request.Header.Get("Expect") != "" {
+			status = http.StatusExpectationFailed
+		}
+		return &http.Response{StatusCode: status, Body: http.NoBody, Header: make(http.Header), Request: request}, nil
+	})
+	for range []int{0, 1} {
+		response, err := client.Post("http://example.invalid/", "text/plain", bytes.NewReader([]byte("body")))
+		require.NoError(test, err)
+		require.NoError(test, response.Body.Close())
+	}
+	require.Equal(test, []string{"100-continue", "", ""}, expects)
+}
+
+// countingReader is a reader that counts the bytes read.
+type countingReader struct {
+	reader io.Reader
+	count  *atomic.Int64
+}
+
+// Read reads from the reader and counts the bytes read.
+func (reader *countingReader) Read(buffer []byte) (count int, err error) {
+	count, err = reader.reader.Read(buffer)
+	reader.count.Add(int64(count))
+	return count, err
+}
@@ -0,0 +1,183 @@
+package retryable
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/url"
+)
+
+// forcedEndpointKey is the context key for the endpoint of a racing attempt.
+type forcedEndpointKey struct{}
+
+// stickyEndpointKey is the context key for the winner of an endpoint race.
+type stickyEndpointKey struct{}
+
+// stickyEndpoint contains the endpoint that responded first to the first
+// attempt, which is used for the remaining attempts of the request.
+type stickyEndpoint struct {
+	// url specifies the URL of the endpoint.
+	url *url.URL
+}
+
+// endpointResult contains the outcome of an attempt sent to an endpoint.
+type endpointResult struct {
+	// index specifies the position of the attempt in the race.
+	index int
+
+	// endpoint specifies the endpoint of the attempt.
+	endpoint *url.URL
+
+	// cancel specifies the function that cancels the attempt.
+	cancel context.CancelFunc
+
+	// response specifies the response of the attempt, if any.
+	response *http.Response
+
+	// err specifies the error of the attempt, if any.
+	err error
+}
+
+// withEndpointRace returns a copy of the context that records the winner of
+// the endpoint race, if endpoint racing is enabled.
+func (client *Client) withEndpointRace(ctx context.Context) context.Context {
+	if client.EndpointSelector == nil || client.EndpointRaceDelay <= 0 {
+		return ctx
+	}
+	return context.WithValue(ctx, stickyEndpointKey{}, new(stickyEndpoint))
+}
+
+// racedEndpoint returns the endpoint forced for a racing attempt, or the
+// winner of the endpoint race, if any.
+func racedEndpoint(ctx context.Context) *url.URL {
+	if endpoint, ok := ctx.Value(forcedEndpointKey{}).(*url.URL); ok {
+		return endpoint
+	}
+	if sticky, ok := ctx.Value(stickyEndpointKey{}).(*stickyEndpoint); ok {
+		return sticky.url
+	}
+	return nil
+}
+
+// sendAttempt sends the attempt, racing the first attempt to the first two
+// endpoints of the endpoint selector if endpoint racing is enabled.
+func (client *Client) sendAttempt(ctx context.Context, request *http.Request) (response *http.Response, err error) {
+	// Check for first attempt of endpoint race
+	sticky, ok := ctx.Value(stickyEndpointKey{}).(*stickyEndpoint)
+	if !ok || sticky.url != nil || attemptNumber(ctx) != 0 {
+		return client.sendRequest(ctx, request)
+	}
+
+	// Select endpoints to race
+	first, err := client.EndpointSelector.SelectEndpoint(0, request)
+	if err != nil || first == nil {
+		return client.sendRequest(ctx, request)
+	}
+	second, err := client.EndpointSelector.SelectEndpoint(1, request)
+	if err != nil || second == nil || second.String() == first.String() {
+		return client.sendRequest(ctx, request)
+	}
+
+	// Send to first endpoint, then to second endpoint after the stagger or a
+	// failure of the first endpoint
+	results := make(chan endpointResult, 2)
+	cancels := make([]context.CancelFunc, 0, 2)
+	launch := func(request *http.Request, endpoint *url.URL) {
+		endpointCtx, cancel := context.WithCancel(context.WithValue(ctx, forcedEndpointKey{}, endpoint))
+		result := endpointResult{index: len(cancels), endpoint: endpoint, cancel: cancel}
+		cancels = append(cancels, cancel)
+		go client.sendToEndpoint(endpointCtx, request, result, results)
+	}
+	launch(request, first)
+	staggerCtx, cancelStagger := context.WithCancel(ctx)
+	defer cancelStagger()
+	stagger := make(chan struct{})
+	go func() {
+		if client.clock().Sleep(staggerCtx, client.EndpointRaceDelay) == nil {
+			close(stagger)
+		}
+	}()
+	for received := 0; received < len(cancels); {
+		select {
+		case <-stagger:
+			stagger = nil
+			launch(cloneAttempt(request), second)
+		case result := <-results:
+			received++
+			response, err = result.response, result.err
+
+			// Keep the first endpoint to respond for the remaining attempts,
+			// and cancel the loser
+			if result.response != nil {
+				sticky.url = result.endpoint
+				for index, cancel := range cancels {
+					if index != result.index {
+						cancel()
+					}
+				}
+				go discardEndpoints(len(cancels)-received, results)
+				client.recordEndpoint(ctx, result.endpoint)
+				if err != nil {
+					result.cancel()
+					return response, err
+				}
+				response.Body = cancelOnClose(response.Body, result.cancel)
+				return response, nil
+			}
+			result.cancel()
+
+			// Start second endpoint immediately if the first fails to respond
+			if stagger != nil {
+				stagger = nil
+				launch(cloneAttempt(request), second)
+			}
+		}
+	}
+	return response, err
+}
+
+// sendToEndpoint sends the attempt to the endpoint, and delivers the result
+// to the channel.
+func (client *Client) sendToEndpoint(ctx context.Context, request *http.Request, result endpointResult, results chan<- endpointResult) {
+	result.response, result.err = client.sendRequest(ctx, request)
+	results <- result
+}
+
+// cloneAttempt returns a copy of the request with a fresh request body, so
+// that it can be sent concurrently with the original request.
+func cloneAttempt(request *http.Request) *http.Request {
+	clone := request.Clone(request.Context())
+	if request.GetBody != nil && request.Body != nil && request.Body != http.NoBody {
+		body, err := request.GetBody()
+		if err == nil {
+			clone.Body = body
+		}
+	}
+	return clone
+}
+
+// discardEndpoints cancels the remaining attempts of an endpoint race, and
+// closes the response bodies of attempts that succeeded after the winner.
+func discardEndpoints(remaining int, results <-chan endpointResult) {
+	for ; remaining > 0; remaining-- {
+		result := <-results
+		if result.err == nil && result.response != nil {
+			_ = result.response.Body.Close()
+		}
+		result.cancel()
+	}
+}
+
+// recordEndpoint records the endpoint of the attempt in the attempt metadata.
+func (client *Client) recordEndpoint(ctx context.Context, endpoint *url.URL) {
+	if recorder := attemptRecorderFrom(ctx); recorder != nil {
+		var record *net.SRV
+		if selector, ok := client.EndpointSelector.(*SRVEndpointSelector); ok {
+			record = selector.record(endpoint)
+		}
+		recorder.update(func(attempt *Attempt) {
+			attempt.Endpoint = endpoint.Redacted()
+			attempt.SRV = record
+		})
+	}
+}
@@ -0,0 +1,88 @@
+package retryable
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestClient_EndpointRace(test *testing.T) {
+	test.Parallel()
+
+	canceled := make(chan struct{})
+	primary := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		select {
+		case <-request.Context().Done():
+			close(canceled)
+		case <-time.After(10 * time.Second):
+		}
+	}))
+	defer primary.Close()
+	var count atomic.Int32
+	secondary := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		if count.Add(1) == 1 {
+			writer.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer secondary.Close()
+	primaryURL, err := url.Parse(primary.URL)
+	require.NoError(test, err)
+	secondaryURL, err := url.Parse(secondary.URL)
+	require.NoError(test, err)
+
+	var attempts []Attempt
+	client := new(Client)
+	client.RetryCount = 2
+	client.RetryStatus = []int{http.StatusServiceUnavailable}
+	client.EndpointSelector = &FailoverEndpointSelector{Endpoints: []*url.URL{primaryURL, secondaryURL}}
+	client.EndpointRaceDelay = 10 * time.Millisecond
+	client.OnAttempt = func(attempt Attempt) {
+		attempts = append(attempts, attempt)
+	}
+	response, err := client.Get("http://service.invalid/resource")
+	require.NoError(test, err)
+	require.NoError(test, response.Body.Close())
+	require.Len(test, attempts, 2)
+	require.Equal(test, http.StatusServiceUnavailable, attempts[0].StatusCode)
+	require.Equal(test, secondaryURL.String(), attempts[0].Endpoint)
+	require.Equal(test, secondaryURL.String(), attempts[1].Endpoint)
+	require.Equal(test, int32(2), count.Load())
+	select {
+	case <-canceled:
+	case <-time.After(5 * time.Second):
+		test.Fatal("primary endpoint was not canceled")
+	}
+}
+
+func TestClient_EndpointRaceFailure(test *testing.T) {
+	test.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		_, _ = writer.Write([]byte("ok"))
+	}))
+	defer server.Close()
+	healthy, err := url.Parse(server.URL)
+	require.NoError(test, err)
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(test, err)
+	dead, err := url.Parse("http://" + listener.Addr().String())
+	require.NoError(test, err)
+	require.NoError(test, listener.Close())
+
+	client := new(Client)
+	client.EndpointSelector = &FailoverEndpointSelector{Endpoints: []*url.URL{dead, healthy}}
+	client.EndpointRaceDelay = time.Hour
+	response, err := client.Get("http://service.invalid/resource")
+	require.NoError(test, err)
+	require.NoError(test, response.Body.Close())
+
+	client.EndpointSelector = &FailoverEndpointSelector{Endpoints: []*url.URL{dead, dead}}
+	_, err = client.Get("http://service.invalid/resource")
+	require.ErrorIs(test, err, ErrRetryable)
+}
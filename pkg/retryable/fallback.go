@@ -0,0 +1,87 @@
+package retryable
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// fallbackConn is a connection to a static fallback address.
+type fallbackConn struct {
+	net.Conn
+}
+
+// transport returns the transport used to send requests, which dials the
+// static fallback addresses when a hostname cannot be resolved, and connects
+// through the proxy selected for the attempt. If neither fallback addresses
+// nor a proxy selector are specified, or the transport is not an
+// [net/http.Transport], the transport of the base HTTP client is returned.
+func (client *Client) transport() http.RoundTripper {
+	// Check for fallback addresses or proxy selector
+	if len(client.FallbackAddresses) == 0 && client.ProxySelector == nil {
+		return client.Transport
+	}
+	base := client.Transport
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	transport, ok := base.(*http.Transport)
+	if !ok {
+		return client.Transport
+	}
+
+	// Reuse wrapped transport, so that connections are pooled
+	state := client.state()
+	state.mutex.Lock()
+	defer state.mutex.Unlock()
+	state.fallbackAddresses = client.FallbackAddresses
+	if state.wrappedBase == base && state.wrappedTransport != nil {
+		return state.wrappedTransport
+	}
+
+	// Wrap dialer and proxy of a copy of the transport
+	dial := transport.DialContext
+	if dial == nil {
+		dial = (&net.Dialer{Timeout: 30 * time.Second, KeepAlive: 30 * time.Second}).DialContext
+	}
+	wrapped := transport.Clone()
+	wrapped.DialContext = state.fallbackDialer(dial)
+	wrapped.Proxy = attemptProxy(transport.Proxy)
+	state.wrappedBase = base
+	state.wrappedTransport = wrapped
+	return wrapped
+}
+
+// fallbackDialer returns a dial function that dials the static fallback
+// addresses of the hostname if the hostname cannot be resolved.
+func (state *clientState) fallbackDialer(dial func(ctx context.Context, network string, address string) (net.Conn, error)) func(ctx context.Context, network string, address string) (net.Conn, error) {
+	return func(ctx context.Context, network string, address string) (conn net.Conn, err error) {
+		// Check for resolution failure
+		conn, err = dial(ctx, network, address)
+		var dnsErr *net.DNSError
+		if err == nil || !errors.As(err, &dnsErr) {
+			return conn, err
+		}
+
+		// Check for fallback addresses
+		host, port, splitErr := net.SplitHostPort(address)
+		if splitErr != nil {
+			return nil, err
+		}
+		state.mutex.Lock()
+		addresses := state.fallbackAddresses[strings.ToLower(host)]
+		state.mutex.Unlock()
+
+		// Dial fallback addresses in order
+		for _, fallback := range addresses {
+			conn, fallbackErr := dial(ctx, network, net.JoinHostPort(fallback, port))
+			if fallbackErr == nil {
+				return &fallbackConn{Conn: conn}, nil
+			}
+		}
+		return nil, err
+	}
+}
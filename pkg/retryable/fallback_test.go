@@ -0,0 +1,63 @@
+package retryable
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestClient_FallbackAddresses(test *testing.T) {
+	test.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		_, _ = writer.Write([]byte(request.Host))
+	}))
+	defer server.Close()
+	address, err := url.Parse(server.URL)
+	require.NoError(test, err)
+
+	var attempts []Attempt
+	client := new(Client)
+	client.OnAttempt = func(attempt Attempt) {
+		attempts = append(attempts, attempt)
+	}
+	_, err = client.Get("http://fallback.invalid:" + address.Port())
+	require.ErrorIs(test, err, ErrRetryable)
+
+	client.FallbackAddresses = map[string][]string{"fallback.invalid": {"127.0.0.1"}}
+	for index := 0; index < 2; index++ {
+		response, err := client.Get("http://fallback.invalid:" + address.Port())
+		require.NoError(test, err)
+		require.NoError(test, response.Body.Close())
+	}
+	require.Len(test, attempts, 3)
+	require.Empty(test, attempts[0].FallbackAddress)
+	require.Equal(test, net.JoinHostPort("127.0.0.1", address.Port()), attempts[1].FallbackAddress)
+	require.Equal(test, net.JoinHostPort("127.0.0.1", address.Port()), attempts[2].FallbackAddress)
+	require.Same(test, client.transport(), client.transport())
+
+	response, err := client.Get(server.URL)
+	require.NoError(test, err)
+	require.NoError(test, response.Body.Close())
+	require.Empty(test, attempts[3].FallbackAddress)
+}
+
+func TestClient_Transport(test *testing.T) {
+	test.Parallel()
+
+	client := new(Client)
+	require.Nil(test, client.transport())
+
+	client.FallbackAddresses = map[string][]string{"example.com": {"127.0.0.1"}}
+	client.Transport = http.NewFileTransport(http.Dir("."))
+	require.Equal(test, client.Transport, client.transport())
+
+	client.Transport = nil
+	transport, ok := client.transport().(*http.Transport)
+	require.True(test, ok)
+	require.NotNil(test, transport.DialContext)
+}
@@ -0,0 +1,41 @@
+package retryable
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"syscall"
+)
+
+// ErrFreeRetry defines an error for retryable outcomes that do not consume
+// the retry count, such as waits mandated by the server. Errors that wrap
+// both [ErrRetryable] and ErrFreeRetry, such as errors returned by a
+// [ResponseCheck] or the PrepareAttempt hook, are retried for free.
+var ErrFreeRetry = errors.New("free retry")
+
+// isFreeRetry reports whether the failed attempt is retried without consuming
+// the retry count: errors that wrap [ErrFreeRetry], responses with a
+// server-specified retry delay if enabled, and requests that failed because
+// the server closed a reused connection.
+func (client *Client) isFreeRetry(ctx context.Context, response *http.Response, err error) bool {
+	// Check for explicitly free retry
+	if errors.Is(err, ErrFreeRetry) {
+		return true
+	}
+
+	// Check for server-mandated wait
+	if client.FreeRetryAfter && response != nil {
+		if _, ok := client.remainingRetryDelay(response); ok {
+			return true
+		}
+	}
+
+	// Check for connection-reuse race
+	recorder := attemptRecorderFrom(ctx)
+	if response != nil || recorder == nil || !recorder.snapshot().Reused {
+		return false
+	}
+	return errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, syscall.ECONNRESET) || errors.Is(err, syscall.EPIPE)
+}
@@ -0,0 +1,88 @@
+package retryable
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestClient_FreeRetryAfter(test *testing.T) {
+	test.Parallel()
+
+	var attempts atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		if attempts.Add(1) <= 2 {
+			writer.Header().Set("Retry-After", "1")
+			writer.WriteHeader(http.StatusTooManyRequests)
+		}
+	}))
+	defer server.Close()
+
+	clock := &MockClock{now: time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC)}
+	client := new(Client)
+	client.Clock = clock
+	client.RetryStatus = []int{http.StatusTooManyRequests}
+	client.FreeRetries = 5
+	_, err := client.Get(server.URL)
+	require.ErrorIs(test, err, ErrRetryable)
+	require.Equal(test, int32(1), attempts.Load())
+
+	attempts.Store(0)
+	client.FreeRetryAfter = true
+	response, err := client.Get(server.URL)
+	require.NoError(test, err)
+	require.NoError(test, response.Body.Close())
+	require.Equal(test, int32(3), attempts.Load())
+	require.Contains(test, clock.sleeps, time.Second)
+}
+
+func TestClient_FreeRetries(test *testing.T) {
+	test.Parallel()
+
+	var attempts atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		attempts.Add(1)
+	}))
+	defer server.Close()
+
+	var numbers []int
+	client := new(Client)
+	client.Clock = &MockClock{now: time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC)}
+	client.RetryCount = 1
+	client.FreeRetries = 2
+	client.CheckResponse = func(*http.Response, []byte) error {
+		return fmt.Errorf("%w: %w", ErrRetryable, ErrFreeRetry)
+	}
+	client.OnAttempt = func(attempt Attempt) {
+		numbers = append(numbers, attempt.Number)
+	}
+	_, err := client.Get(server.URL)
+	require.ErrorIs(test, err, ErrFreeRetry)
+	require.Equal(test, int32(4), attempts.Load())
+	require.Equal(test, []int{0, 0, 0, 1}, numbers)
+}
+
+func TestClient_IsFreeRetry(test *testing.T) {
+	test.Parallel()
+
+	client := new(Client)
+	ctx := client.startAttempt(context.Background(), 0, "")
+	require.False(test, client.isFreeRetry(ctx, nil, io.EOF))
+	require.True(test, client.isFreeRetry(ctx, nil, fmt.Errorf("%w: %w", ErrRetryable, ErrFreeRetry)))
+
+	attemptRecorderFrom(ctx).update(func(attempt *Attempt) {
+		attempt.Reused = true
+	})
+	require.True(test, client.isFreeRetry(ctx, nil, fmt.Errorf("%w: %w", ErrRetryable, io.EOF)))
+	require.False(test, client.isFreeRetry(ctx, nil, errors.New("refused")))
+	require.False(test, client.isFreeRetry(context.Background(), nil, io.EOF))
+	require.False(test, client.isFreeRetry(ctx, &http.Response{Header: http.Header{"Retry-After": {"1"}}}, io.EOF))
+}
@@ -0,0 +1,139 @@
+package retryable
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// AttemptHeaders specifies request headers that are regenerated or removed on
+// each attempt.
+type AttemptHeaders struct {
+	// Date specifies whether the Date header is set to the current time on
+	// each attempt.
+	Date bool
+
+	// IdempotencyKey specifies whether a random Idempotency-Key header is
+	// generated for each request, unless already present, and reused for each
+	// attempt.
+	IdempotencyKey bool
+
+	// Attempt specifies the name of a header, such as X-Attempt, that is set to
+	// the attempt number on each attempt, starting from zero. If the name is
+	// empty, the header is not set.
+	Attempt string
+
+	// Reason specifies the name of a header, such as X-Retry-Reason, that is
+	// set on each retry to the reason the previous attempt failed, such as
+	// "status 503" or "timeout". If the name is empty, the header is not set.
+	Reason string
+
+	// Traceparent specifies whether the parent ID of the traceparent header is
+	// regenerated on each attempt, so that each attempt is a separate span of
+	// the same trace. If the header is not present, a new trace is started.
+	Traceparent bool
+
+	// Strip specifies the headers that are removed from each retry, but sent
+	// with the first attempt.
+	Strip []string
+}
+
+// prepareRequestHeaders returns a copy of the request with the headers that
+// are generated once per request. If no headers are generated, the request is
+// returned unmodified.
+func (client *Client) prepareRequestHeaders(request *http.Request) (prepared *http.Request, err error) {
+	// Check for headers generated once per request
+	spec := client.AttemptHeaders
+	idempotency := spec.IdempotencyKey && request.Header.Get("Idempotency-Key") == ""
+	traceparent := spec.Traceparent && request.Header.Get("Traceparent") == ""
+	if !idempotency && !traceparent {
+		return request, nil
+	}
+
+	// Generate headers
+	prepared = request.Clone(request.Context())
+	if prepared.Header == nil {
+		prepared.Header = make(http.Header)
+	}
+	if idempotency {
+		key, err := randomUUID()
+		if err != nil {
+			return nil, err
+		}
+		prepared.Header.Set("Idempotency-Key", key)
+	}
+	if traceparent {
+		trace, err := randomHex(16)
+		if err != nil {
+			return nil, err
+		}
+		prepared.Header.Set("Traceparent", "00-"+trace+"-0000000000000000-00")
+	}
+	return prepared, nil
+}
+
+// applyAttemptHeaders regenerates or removes the request headers for the
+// specified attempt, and the reason the previous attempt failed.
+func (client *Client) applyAttemptHeaders(request *http.Request, attempt int, reason string) (err error) {
+	// Check for valid request headers
+	spec := client.AttemptHeaders
+	if request.Header == nil {
+		request.Header = make(http.Header)
+	}
+
+	// Remove headers from retries
+	if attempt > 0 {
+		for _, name := range spec.Strip {
+			request.Header.Del(name)
+		}
+	}
+
+	// Regenerate headers
+	if spec.Date {
+		request.Header.Set("Date", client.clock().Now().UTC().Format(http.TimeFormat))
+	}
+	if spec.Attempt != "" {
+		request.Header.Set(spec.Attempt, strconv.Itoa(attempt))
+	}
+	if spec.Reason != "" && reason != "" {
+		request.Header.Set(spec.Reason, reason)
+	}
+	if spec.Traceparent {
+		fields := strings.Split(request.Header.Get("Traceparent"), "-")
+		if len(fields) == 4 {
+			fields[2], err = randomHex(8)
+			if err != nil {
+				return err
+			}
+			request.Header.Set("Traceparent", strings.Join(fields, "-"))
+		}
+	}
+	return nil
+}
+
+// randomHex returns the specified number of random bytes, encoded as
+// hexadecimal.
+func randomHex(size int) (value string, err error) {
+	buffer := make([]byte, size)
+	_, err = rand.Read(buffer)
+	if err != nil {
+		return "", fmt.Errorf("%w: unable to generate random value: %w", ErrNonRetryable, err)
+	}
+	return hex.EncodeToString(buffer), nil
+}
+
+// randomUUID returns a random version 4 UUID.
+func randomUUID() (value string, err error) {
+	buffer := make([]byte, 16)
+	_, err = rand.Read(buffer)
+	if err != nil {
+		return "", fmt.Errorf("%w: unable to generate random value: %w", ErrNonRetryable, err)
+	}
+	buffer[6] = (buffer[6] & 0x0F) | 0x40
+	buffer[8] = (buffer[8] & 0x3F) | 0x80
+	key := hex.EncodeToString(buffer)
+	return fmt.Sprintf("%s-%s-%s-%s-%s", key[0:8], key[8:12], key[12:16], key[16:20], key[20:32]), nil
+}
@@ -0,0 +1,102 @@
+package retryable
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestClient_PrepareRequestHeaders(test *testing.T) {
+	test.Parallel()
+
+	client := new(Client)
+	request, err := http.NewRequest(http.MethodPost, "https://www.github.com/", nil)
+	require.NoError(test, err)
+	prepared, err := client.prepareRequestHeaders(request)
+	require.NoError(test, err)
+	require.Equal(test, request, prepared)
+
+	client.AttemptHeaders.IdempotencyKey = true
+	client.AttemptHeaders.Traceparent = true
+	prepared, err = client.prepareRequestHeaders(request)
+	require.NoError(test, err)
+	require.NotEqual(test, request, prepared)
+	require.Regexp(test, "^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$", prepared.Header.Get("Idempotency-Key"))
+	require.Regexp(test, "^00-[0-9a-f]{32}-0{16}-00$", prepared.Header.Get("Traceparent"))
+	require.Empty(test, request.Header.Get("Idempotency-Key"))
+
+	again, err := client.prepareRequestHeaders(prepared)
+	require.NoError(test, err)
+	require.Equal(test, prepared, again)
+}
+
+func TestClient_ApplyAttemptHeaders(test *testing.T) {
+	test.Parallel()
+
+	client := new(Client)
+	client.Clock = &MockClock{now: time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC)}
+	client.AttemptHeaders = AttemptHeaders{
+		Date:        true,
+		Attempt:     "X-Attempt",
+		Reason:      "X-Retry-Reason",
+		Traceparent: true,
+		Strip:       []string{"Expect"},
+	}
+	request := new(http.Request)
+	err := client.applyAttemptHeaders(request, 0, "")
+	require.NoError(test, err)
+	require.Equal(test, "Mon, 01 Jan 2024 00:00:00 GMT", request.Header.Get("Date"))
+	require.Equal(test, "0", request.Header.Get("X-Attempt"))
+	require.Empty(test, request.Header.Get("X-Retry-Reason"))
+	require.Empty(test, request.Header.Get("Traceparent"))
+
+	request.Header.Set("Expect", "100-continue")
+	request.Header.Set("Traceparent", "00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01")
+	err = client.applyAttemptHeaders(request, 0, "")
+	require.NoError(test, err)
+	require.Equal(test, "100-continue", request.Header.Get("Expect"))
+	traceparent := request.Header.Get("Traceparent")
+	require.True(test, strings.HasPrefix(traceparent, "00-0af7651916cd43dd8448eb211c80319c-"))
+	require.True(test, strings.HasSuffix(traceparent, "-01"))
+	require.NotContains(test, traceparent, "b7ad6b7169203331")
+
+	err = client.applyAttemptHeaders(request, 2, "status 503")
+	require.NoError(test, err)
+	require.Empty(test, request.Header.Get("Expect"))
+	require.Equal(test, "2", request.Header.Get("X-Attempt"))
+	require.Equal(test, "status 503", request.Header.Get("X-Retry-Reason"))
+}
+
+func TestClient_AttemptHeaders(test *testing.T) {
+	test.Parallel()
+
+	headers := make(chan http.Header, 2)
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		headers <- request.Header
+		if len(headers) == 1 {
+			writer.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer server.Close()
+
+	client := new(Client)
+	client.RetryCount = 1
+	client.RetryStatus = []int{http.StatusServiceUnavailable}
+	client.AttemptHeaders = AttemptHeaders{IdempotencyKey: true, Attempt: "X-Attempt", Reason: "X-Retry-Reason", Traceparent: true}
+	_, err := client.Post(server.URL, "text/plain", strings.NewReader("xyz"))
+	require.NoError(test, err)
+
+	first, second := <-headers, <-headers
+	require.Equal(test, "0", first.Get("X-Attempt"))
+	require.Equal(test, "1", second.Get("X-Attempt"))
+	require.Empty(test, first.Get("X-Retry-Reason"))
+	require.Equal(test, "status 503", second.Get("X-Retry-Reason"))
+	require.NotEmpty(test, first.Get("Idempotency-Key"))
+	require.Equal(test, first.Get("Idempotency-Key"), second.Get("Idempotency-Key"))
+	require.Equal(test, first.Get("Traceparent")[:36], second.Get("Traceparent")[:36])
+	require.NotEqual(test, first.Get("Traceparent"), second.Get("Traceparent"))
+}
@@ -0,0 +1,125 @@
+package retryable
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// maxHostStatus is the maximum number of hosts for which the most recent
+// failure is retained by a client.
+const maxHostStatus = 256
+
+// ErrorKind classifies the cause of a failed attempt.
+type ErrorKind string
+
+// Error kinds reported by [Client.HostStatus].
+const (
+	ErrorKindStatus   ErrorKind = "status"
+	ErrorKindTimeout  ErrorKind = "timeout"
+	ErrorKindCanceled ErrorKind = "canceled"
+	ErrorKindRedirect ErrorKind = "redirect"
+	ErrorKindNetwork  ErrorKind = "network"
+	ErrorKindOther    ErrorKind = "other"
+)
+
+// HostStatus describes the most recent failed attempt to a host.
+type HostStatus struct {
+	// Time specifies when the attempt failed.
+	Time time.Time
+
+	// StatusCode specifies the status code of the response, or zero if no
+	// response was received.
+	StatusCode int
+
+	// Class specifies the class of the status code, or [ClassNone] if no
+	// response was received.
+	Class Class
+
+	// Kind specifies the cause of the failure.
+	Kind ErrorKind
+
+	// Err specifies the error returned by the attempt.
+	Err error
+}
+
+// HostStatus returns the most recent failed attempt to the specified host,
+// such as "api.github.com" or "localhost:8080", so that health checks can
+// report the status of a dependency. The default port of the scheme is
+// omitted from the host. If no attempt to the host has failed, ok is false.
+func (client *Client) HostStatus(host string) (status HostStatus, ok bool) {
+	state := client.state()
+	state.mutex.Lock()
+	defer state.mutex.Unlock()
+	status, ok = state.hosts[normalizeHost("", host)]
+	return status, ok
+}
+
+// recordFailure records the failed attempt as the most recent failure for
+// the host of the request.
+func (client *Client) recordFailure(request *http.Request, response *http.Response, err error) {
+	// Check for disabled host status, missing host, or permanent protocol error
+	if client.DisableHostStatus || request.URL == nil || client.isPermanentStatus(response) {
+		return
+	}
+
+	// Classify failure
+	status := HostStatus{
+		Time: client.clock().Now(),
+		Kind: classifyError(response, err),
+		Err:  err,
+	}
+	if response != nil {
+		status.StatusCode = response.StatusCode
+		status.Class = Classify(response.StatusCode)
+	}
+
+	// Store failure, evicting an arbitrary host if required
+	state := client.state()
+	state.mutex.Lock()
+	defer state.mutex.Unlock()
+	if state.hosts == nil {
+		state.hosts = make(map[string]HostStatus)
+	}
+	host := normalizeHost(request.URL.Scheme, request.URL.Host)
+	if _, ok := state.hosts[host]; !ok && len(state.hosts) >= maxHostStatus {
+		for existing := range state.hosts {
+			delete(state.hosts, existing)
+			break
+		}
+	}
+	state.hosts[host] = status
+}
+
+// classifyError determines the cause of a failed attempt.
+func classifyError(response *http.Response, err error) (kind ErrorKind) {
+	var netErr net.Error
+	switch {
+	case errors.Is(err, context.Canceled):
+		return ErrorKindCanceled
+	case errors.Is(err, context.DeadlineExceeded):
+		return ErrorKindTimeout
+	case errors.Is(err, ErrTooManyRedirects), errors.Is(err, ErrUnsafeRedirect), errors.Is(err, ErrMalformedRedirect):
+		return ErrorKindRedirect
+	case errors.As(err, &netErr) && netErr.Timeout():
+		return ErrorKindTimeout
+	case response != nil && response.StatusCode >= http.StatusBadRequest:
+		return ErrorKindStatus
+	case netErr != nil:
+		return ErrorKindNetwork
+	}
+	return ErrorKindOther
+}
+
+// retryReason describes the cause of a failed attempt, such as "status 503"
+// or "timeout".
+func retryReason(response *http.Response, err error) string {
+	kind := classifyError(response, err)
+	if kind == ErrorKindStatus {
+		return "status " + strconv.Itoa(response.StatusCode)
+	}
+	return string(kind)
+}
@@ -0,0 +1,88 @@
+package retryable
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestClient_HostStatus(test *testing.T) {
+	test.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, _ *http.Request) {
+		writer.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+	address, err := url.Parse(server.URL)
+	require.NoError(test, err)
+
+	clock := &MockClock{now: time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC)}
+	client := new(Client)
+	client.Clock = clock
+	client.RetryStatus = []int{http.StatusServiceUnavailable}
+	_, ok := client.HostStatus(address.Host)
+	require.False(test, ok)
+
+	_, err = client.Get(server.URL)
+	require.ErrorIs(test, err, ErrRetryable)
+	status, ok := client.HostStatus(address.Host)
+	require.True(test, ok)
+	require.Equal(test, clock.Now(), status.Time)
+	require.Equal(test, http.StatusServiceUnavailable, status.StatusCode)
+	require.Equal(test, ClassServerError, status.Class)
+	require.Equal(test, ErrorKindStatus, status.Kind)
+	require.ErrorIs(test, status.Err, ErrRetryable)
+
+	server.Close()
+	_, err = client.Get(server.URL)
+	require.Error(test, err)
+	status, ok = client.HostStatus(address.Host)
+	require.True(test, ok)
+	require.Zero(test, status.StatusCode)
+	require.Equal(test, ErrorKindNetwork, status.Kind)
+}
+
+func TestClient_RecordFailure(test *testing.T) {
+	test.Parallel()
+
+	client := new(Client)
+	request, err := http.NewRequest(http.MethodGet, "https://WWW.GITHUB.COM:443/", nil)
+	require.NoError(test, err)
+	client.recordFailure(request, nil, errors.New("failed"))
+	status, ok := client.HostStatus("www.github.com")
+	require.True(test, ok)
+	require.Equal(test, ErrorKindOther, status.Kind)
+
+	for index := 0; index <= maxHostStatus; index++ {
+		request.URL.Host = fmt.Sprintf("host%d.example.com", index)
+		client.recordFailure(request, nil, nil)
+	}
+	require.Len(test, client.state().hosts, maxHostStatus)
+
+	client = new(Client)
+	client.recordFailure(new(http.Request), nil, errors.New("failed"))
+	require.Empty(test, client.state().hosts)
+
+	client = new(Client)
+	client.DisableHostStatus = true
+	client.recordFailure(request, nil, errors.New("failed"))
+	require.Empty(test, client.state().hosts)
+}
+
+func TestClassifyError(test *testing.T) {
+	test.Parallel()
+
+	response := &http.Response{StatusCode: http.StatusTooManyRequests}
+	require.Equal(test, ErrorKindCanceled, classifyError(nil, context.Canceled))
+	require.Equal(test, ErrorKindTimeout, classifyError(nil, context.DeadlineExceeded))
+	require.Equal(test, ErrorKindRedirect, classifyError(response, ErrUnsafeRedirect))
+	require.Equal(test, ErrorKindStatus, classifyError(response, ErrRetryable))
+	require.Equal(test, ErrorKindOther, classifyError(nil, ErrRetryable))
+}
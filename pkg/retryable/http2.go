@@ -0,0 +1,115 @@
+package retryable
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// http2Refused contains the messages of HTTP/2 errors that indicate that the
+// server did not process the request (RFC 9113 section 8.7), which are always
+// safe to retry.
+var http2Refused = []string{
+	"REFUSED_STREAM",
+	"server sent GOAWAY",
+	"graceful shutdown GOAWAY",
+}
+
+// classifySendError classifies an error sending a request. HTTP/2 errors that
+// indicate that the request was not processed, such as REFUSED_STREAM or a
+// GOAWAY before the response, are retryable. Other HTTP/2 stream errors, such
+// as INTERNAL_ERROR, are only retryable if the request is idempotent. Other
+// errors are retryable.
+func classifySendError(request *http.Request, err error) error {
+	// Check for HTTP/2 error
+	if !isHTTP2Error(err) {
+		return fmt.Errorf("%w: unable to send request: %w", ErrRetryable, err)
+	}
+	message := err.Error()
+
+	// Check for unprocessed request
+	for _, refused := range http2Refused {
+		if strings.Contains(message, refused) {
+			return fmt.Errorf("%w: request not processed by HTTP/2 server: %w", ErrRetryable, err)
+		}
+	}
+
+	// Check for idempotent request
+	if isIdempotent(request) {
+		return fmt.Errorf("%w: HTTP/2 stream error: %w", ErrRetryable, err)
+	}
+	return fmt.Errorf("%w: HTTP/2 stream error on non-idempotent request: %w", ErrNonRetryable, err)
+}
+
+// isHTTP2Error reports whether the error is an HTTP/2 transport error. The
+// error types are not exported by the standard library, so the message of the
+// error is inspected instead.
+func isHTTP2Error(err error) bool {
+	if err == nil {
+		return false
+	}
+	message := err.Error()
+	return strings.Contains(message, "http2:") || strings.Contains(message, "stream error:")
+}
+
+// downgradeKey is the context key for attempts that are sent over HTTP/1.1.
+type downgradeKey struct{}
+
+// withDowngrade returns a copy of the context that sends the attempt over
+// HTTP/1.1, and records the downgrade in the attempt metadata.
+func withDowngrade(ctx context.Context) context.Context {
+	if recorder := attemptRecorderFrom(ctx); recorder != nil {
+		recorder.update(func(attempt *Attempt) {
+			attempt.Downgraded = true
+		})
+	}
+	return context.WithValue(ctx, downgradeKey{}, true)
+}
+
+// downgradeTransport returns a copy of the transport with HTTP/2 disabled if
+// the attempt is sent over HTTP/1.1. If the transport is not an
+// [net/http.Transport], it is returned unchanged.
+func (client *Client) downgradeTransport(ctx context.Context, base http.RoundTripper) http.RoundTripper {
+	// Check for downgraded attempt
+	if downgraded, _ := ctx.Value(downgradeKey{}).(bool); !downgraded {
+		return base
+	}
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	transport, ok := base.(*http.Transport)
+	if !ok {
+		return base
+	}
+
+	// Reuse HTTP/1.1 transport, so that connections are pooled
+	state := client.state()
+	state.mutex.Lock()
+	defer state.mutex.Unlock()
+	if state.http1Base == transport && state.http1Transport != nil {
+		return state.http1Transport
+	}
+
+	// Disable HTTP/2 on a copy of the transport
+	http1 := transport.Clone()
+	http1.ForceAttemptHTTP2 = false
+	http1.TLSNextProto = make(map[string]func(string, *tls.Conn) http.RoundTripper)
+	if http1.TLSClientConfig != nil {
+		http1.TLSClientConfig.NextProtos = []string{"http/1.1"}
+	}
+	state.http1Base = transport
+	state.http1Transport = http1
+	return http1
+}
+
+// isIdempotent reports whether the request can be replayed safely, because
+// its method is idempotent or it has an idempotency key.
+func isIdempotent(request *http.Request) bool {
+	switch request.Method {
+	case "", http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return request.Header.Get("Idempotency-Key") != "" || request.Header.Get("X-Idempotency-Key") != ""
+}
@@ -0,0 +1,96 @@
+package retryable
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestClassifySendError(test *testing.T) {
+	test.Parallel()
+
+	get, err := http.NewRequest(http.MethodGet, "https://example.com", nil)
+	require.NoError(test, err)
+	post, err := http.NewRequest(http.MethodPost, "https://example.com", nil)
+	require.NoError(test, err)
+
+	err = classifySendError(post, errors.New("dial tcp: connection refused"))
+	require.ErrorIs(test, err, ErrRetryable)
+	require.ErrorContains(test, err, "unable to send request")
+
+	refused := errors.New("stream error: stream ID 3; REFUSED_STREAM")
+	require.ErrorIs(test, classifySendError(post, refused), ErrRetryable)
+	goAway := errors.New(`http2: server sent GOAWAY and closed the connection; LastStreamID=1, ErrCode=NO_ERROR, debug=""`)
+	require.ErrorIs(test, classifySendError(post, goAway), ErrRetryable)
+	require.ErrorContains(test, classifySendError(post, goAway), "not processed")
+
+	internal := errors.New("stream error: stream ID 5; INTERNAL_ERROR; received from peer")
+	require.ErrorIs(test, classifySendError(get, internal), ErrRetryable)
+	require.ErrorIs(test, classifySendError(post, internal), ErrNonRetryable)
+	post.Header.Set("Idempotency-Key", "key")
+	require.ErrorIs(test, classifySendError(post, internal), ErrRetryable)
+}
+
+func TestClient_HTTP2Fallback(test *testing.T) {
+	test.Parallel()
+
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		if request.ProtoMajor == 2 {
+			panic(http.ErrAbortHandler)
+		}
+		_, _ = writer.Write([]byte(request.Proto))
+	}))
+	server.EnableHTTP2 = true
+	server.StartTLS()
+	defer server.Close()
+
+	var attempts []Attempt
+	client := new(Client)
+	client.Transport = server.Client().Transport
+	client.RetryCount = 2
+	client.OnAttempt = func(attempt Attempt) {
+		attempts = append(attempts, attempt)
+	}
+	_, err := client.Get(server.URL)
+	require.ErrorIs(test, err, ErrRetryable)
+	require.Len(test, attempts, 3)
+	require.False(test, attempts[2].Downgraded)
+
+	attempts = nil
+	client.HTTP2Fallback = 2
+	response, err := client.Get(server.URL)
+	require.NoError(test, err)
+	body, err := io.ReadAll(response.Body)
+	require.NoError(test, err)
+	require.NoError(test, response.Body.Close())
+	require.Equal(test, "HTTP/1.1", string(body))
+	require.Len(test, attempts, 3)
+	require.False(test, attempts[1].Downgraded)
+	require.True(test, attempts[2].Downgraded)
+	require.Same(test, client.downgradeTransport(withDowngrade(context.Background()), client.Transport),
+		client.downgradeTransport(withDowngrade(context.Background()), client.Transport))
+}
+
+func TestIsIdempotent(test *testing.T) {
+	test.Parallel()
+
+	for method, expected := range map[string]bool{
+		"":                 true,
+		http.MethodGet:     true,
+		http.MethodPut:     true,
+		http.MethodDelete:  true,
+		http.MethodPost:    false,
+		http.MethodPatch:   false,
+		http.MethodConnect: false,
+	} {
+		request := &http.Request{Method: method, Header: make(http.Header)}
+		require.Equal(test, expected, isIdempotent(request), method)
+	}
+	request := &http.Request{Method: http.MethodPatch, Header: http.Header{"X-Idempotency-Key": {"key"}}}
+	require.True(test, isIdempotent(request))
+}
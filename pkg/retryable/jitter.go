@@ -0,0 +1,82 @@
+package retryable
+
+import (
+	"math/rand"
+	"time"
+
+	"github.com/cholland1989/go-delay/pkg/delay"
+)
+
+// JitterMode defines how random jitter is applied to the retry delay.
+type JitterMode int
+
+const (
+	// ProportionalJitter applies the retry jitter proportionally around the
+	// retry delay, so that a jitter of 0.5 results in a delay between half and
+	// one and a half times the retry delay.
+	ProportionalJitter JitterMode = iota
+
+	// FullJitter results in a random delay between zero and the retry delay,
+	// ignoring the retry jitter, which spreads simultaneous retries the most.
+	FullJitter
+
+	// EqualJitter results in a random delay between half the retry delay and
+	// the retry delay, ignoring the retry jitter, which spreads simultaneous
+	// retries while keeping a minimum delay.
+	EqualJitter
+)
+
+// applyJitter applies random jitter to the retry delay according to the
+// jitter mode.
+func (client *Client) applyJitter(duration time.Duration) time.Duration {
+	// Check for positive delay
+	if duration <= 0 {
+		return duration
+	}
+
+	// Apply jitter mode
+	switch client.JitterMode {
+	case FullJitter:
+		return time.Duration(client.randomInt63n(int64(duration) + 1))
+	case EqualJitter:
+		half := duration / 2
+		return duration - half + time.Duration(client.randomInt63n(int64(half)+1))
+	default:
+		return client.randomJitter(duration, client.RetryJitter)
+	}
+}
+
+// randomJitter returns the duration plus or minus random jitter, as
+// proportion of the duration, using the random source of the client.
+func (client *Client) randomJitter(duration time.Duration, jitter float64) time.Duration {
+	if jitter == 0.0 || client.RandSource == nil {
+		return delay.RandomJitter(duration, jitter)
+	}
+	return delay.FloatToDuration(float64(duration) * (1.0 + jitter*(1.0-2.0*client.randomFloat64())))
+}
+
+// randomFloat64 returns a random number in [0, 1) from the random source of
+// the client, or the global random source if unset. The random source is used
+// while holding the lock, since random sources are not safe for concurrent
+// use.
+func (client *Client) randomFloat64() float64 {
+	if client.RandSource == nil {
+		return rand.Float64()
+	}
+	state := client.state()
+	state.mutex.Lock()
+	defer state.mutex.Unlock()
+	return rand.New(client.RandSource).Float64()
+}
+
+// randomInt63n returns a random number in [0, n) from the random source of
+// the client, or the global random source if unset.
+func (client *Client) randomInt63n(n int64) int64 {
+	if client.RandSource == nil {
+		return rand.Int63n(n)
+	}
+	state := client.state()
+	state.mutex.Lock()
+	defer state.mutex.Unlock()
+	return rand.New(client.RandSource).Int63n(n)
+}
@@ -0,0 +1,62 @@
+package retryable
+
+import (
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestClient_JitterMode(test *testing.T) {
+	test.Parallel()
+
+	client := new(Client)
+	client.RetryJitter = 0.5
+	for index := 0; index < 1000; index++ {
+		client.JitterMode = ProportionalJitter
+		require.InDelta(test, float64(time.Second), float64(client.applyJitter(time.Second)), float64(time.Second/2))
+		client.JitterMode = FullJitter
+		require.InDelta(test, float64(time.Second/2), float64(client.applyJitter(time.Second)), float64(time.Second/2))
+		client.JitterMode = EqualJitter
+		require.InDelta(test, float64(time.Second*3/4), float64(client.applyJitter(time.Second)), float64(time.Second/4))
+	}
+	require.Zero(test, client.applyJitter(0))
+
+	client.UpdatePolicy(Policy{JitterMode: FullJitter})
+	require.Equal(test, FullJitter, client.Policy().JitterMode)
+	require.Equal(test, FullJitter, client.snapshot().JitterMode)
+}
+
+type sliceSource struct {
+	rand.Source
+	tags []string
+}
+
+func TestClient_RandSource(test *testing.T) {
+	test.Parallel()
+
+	delays := func(seed int64, mode JitterMode) (delays []time.Duration) {
+		client := new(Client)
+		client.RetryJitter = 0.5
+		client.JitterMode = mode
+		client.RandSource = rand.NewSource(seed)
+		for index := 0; index < 5; index++ {
+			delays = append(delays, client.applyJitter(time.Second))
+		}
+		delays = append(delays, client.randomJitter(time.Second, 0.5))
+		return delays
+	}
+	for _, mode := range []JitterMode{ProportionalJitter, FullJitter, EqualJitter} {
+		require.Equal(test, delays(1, mode), delays(1, mode))
+		require.NotEqual(test, delays(1, mode), delays(2, mode))
+	}
+
+	client := new(Client)
+	client.RetryJitter = 0.5
+	client.RandSource = sliceSource{Source: rand.NewSource(1), tags: []string{"test"}}
+	require.NotPanics(test, func() {
+		client.applyJitter(time.Second)
+		client.applyJitter(time.Second)
+	})
+}
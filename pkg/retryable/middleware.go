@@ -0,0 +1,41 @@
+package retryable
+
+import (
+	"context"
+	"net/http"
+)
+
+// Middleware defines a function that wraps a [Doer], such as to add
+// authentication, logging, or headers to requests, or to inspect responses.
+// Middleware that only needs to send requests can return a [DoerFunc].
+type Middleware func(next Doer) Doer
+
+// Chain returns a [Doer] that passes requests through the middleware before
+// the specified doer, where the first middleware is the outermost.
+func Chain(next Doer, middleware ...Middleware) Doer {
+	return chain(middleware, next)
+}
+
+// chain wraps the doer with the middleware, where the first middleware is the
+// outermost.
+func chain(middleware []Middleware, next Doer) Doer {
+	for index := len(middleware) - 1; index >= 0; index-- {
+		next = middleware[index](next)
+	}
+	return next
+}
+
+// doAttempt sends a single attempt of the request through the attempt
+// middleware of the client, if any.
+func (client *Client) doAttempt(ctx context.Context, request *http.Request) (response *http.Response, err error) {
+	// Check for attempt middleware
+	if len(client.AttemptMiddleware) == 0 {
+		return client.sendAttempt(ctx, request)
+	}
+
+	// Send attempt through middleware
+	attempt := DoerFunc(func(request *http.Request) (*http.Response, error) {
+		return client.sendAttempt(request.Context(), request)
+	})
+	return chain(client.AttemptMiddleware, attempt).Do(request.Clone(ctx))
+}
@@ -0,0 +1,78 @@
+package retryable
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestClient_Middleware(test *testing.T) {
+	test.Parallel()
+
+	var count atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		writer.Header().Set("X-Seen", strings.Join(request.Header.Values("X-Trace"), ","))
+		if count.Add(1) == 1 {
+			writer.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer server.Close()
+
+	var events []string
+	record := func(name string) Middleware {
+		return func(next Doer) Doer {
+			return DoerFunc(func(request *http.Request) (*http.Response, error) {
+				events = append(events, name+" before")
+				response, err := next.Do(request)
+				events = append(events, name+" after")
+				return response, err
+			})
+		}
+	}
+	client := new(Client)
+	client.RetryCount = 1
+	client.RetryStatus = []int{http.StatusServiceUnavailable}
+	client.Middleware = []Middleware{record("outer"), record("inner")}
+	client.AttemptMiddleware = []Middleware{func(next Doer) Doer {
+		return DoerFunc(func(request *http.Request) (*http.Response, error) {
+			attempt, ok := AttemptFromContext(request.Context())
+			require.True(test, ok)
+			request.Header.Add("X-Trace", "attempt-"+strconv.Itoa(attempt.Number))
+			events = append(events, "attempt "+strconv.Itoa(attempt.Number))
+			return next.Do(request)
+		})
+	}}
+	response, err := client.Get(server.URL)
+	require.NoError(test, err)
+	require.NoError(test, response.Body.Close())
+	require.Equal(test, "attempt-1", response.Header.Get("X-Seen"))
+	require.Equal(test, []string{"outer before", "inner before", "attempt 0", "attempt 1", "inner after", "outer after"}, events)
+}
+
+func TestChain(test *testing.T) {
+	test.Parallel()
+
+	doer := Chain(new(NoopClient), func(next Doer) Doer {
+		return DoerFunc(func(request *http.Request) (*http.Response, error) {
+			request.Header.Set("X-Test", "value")
+			return next.Do(request)
+		})
+	})
+	response, err := doer.Get("http://example.invalid/")
+	require.NoError(test, err)
+	require.NoError(test, response.Body.Close())
+	require.Equal(test, "value", response.Request.Header.Get("X-Test"))
+
+	response, err = doer.PostForm("http://example.invalid/", nil)
+	require.NoError(test, err)
+	require.NoError(test, response.Body.Close())
+	require.Equal(test, http.MethodPost, response.Request.Method)
+
+	_, err = DoerFunc(nil).Head("://invalid")
+	require.ErrorIs(test, err, ErrNonRetryable)
+}
@@ -0,0 +1,203 @@
+package retryable
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Multipart builds a multipart/form-data request body whose parts are
+// streamed from their sources, such as files, on each attempt. Unlike
+// [mime/multipart.Writer], the body is never buffered in memory, so large
+// uploads can be retried without exhausting memory.
+type Multipart struct {
+	// boundary contains the boundary that separates the parts.
+	boundary string
+
+	// parts contains the parts in order.
+	parts []multipartPart
+}
+
+// multipartPart contains the header and the source of a part.
+type multipartPart struct {
+	// header contains the MIME header of the part.
+	header textproto.MIMEHeader
+
+	// open opens the content of the part.
+	open func() (io.ReadCloser, error)
+
+	// size contains the size of the content in bytes, or -1 if unknown.
+	size int64
+}
+
+// NewMultipart returns an empty multipart form with a random boundary.
+func NewMultipart() (form *Multipart) {
+	return &Multipart{boundary: multipart.NewWriter(io.Discard).Boundary()}
+}
+
+// AddField adds a form field with the specified value.
+func (form *Multipart) AddField(name string, value string) {
+	header := make(textproto.MIMEHeader)
+	header.Set("Content-Disposition", fmt.Sprintf(`form-data; name="%s"`, escapeQuotes(name)))
+	form.parts = append(form.parts, multipartPart{
+		header: header,
+		open: func() (io.ReadCloser, error) {
+			return io.NopCloser(strings.NewReader(value)), nil
+		},
+		size: int64(len(value)),
+	})
+}
+
+// AddFile adds a file field whose content is read from the file at the
+// specified path on each attempt. The file is not opened until the request
+// body is read, but its size must not change between attempts.
+func (form *Multipart) AddFile(name string, path string) (err error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return fmt.Errorf("%w: unable to read file: %w", ErrNonRetryable, err)
+	}
+	form.AddReader(name, filepath.Base(path), info.Size(), func() (io.ReadCloser, error) {
+		return os.Open(path)
+	})
+	return nil
+}
+
+// AddReader adds a file field with the specified filename, whose content is
+// opened on each attempt. The size specifies the length of the content in
+// bytes, or -1 if unknown, in which case the request is sent without a
+// content length.
+func (form *Multipart) AddReader(name string, filename string, size int64, open func() (io.ReadCloser, error)) {
+	header := make(textproto.MIMEHeader)
+	header.Set("Content-Disposition", fmt.Sprintf(`form-data; name="%s"; filename="%s"`,
+		escapeQuotes(name), escapeQuotes(filename)))
+	header.Set("Content-Type", "application/octet-stream")
+	form.parts = append(form.parts, multipartPart{header: header, open: open, size: size})
+}
+
+// ContentType returns the Content-Type header of the form, including its
+// boundary.
+func (form *Multipart) ContentType() string {
+	return "multipart/form-data; boundary=" + form.boundary
+}
+
+// Size returns the length of the encoded form in bytes, or -1 if the size of
+// any part is unknown.
+func (form *Multipart) Size() (size int64) {
+	for _, chunk := range form.delimiters() {
+		size += int64(len(chunk))
+	}
+	for _, part := range form.parts {
+		if part.size < 0 {
+			return -1
+		}
+		size += part.size
+	}
+	return size
+}
+
+// Reader returns a reader of the encoded form, which opens the content of
+// each part when it is first read, and closes it when it is exhausted.
+func (form *Multipart) Reader() (reader io.ReadCloser) {
+	delimiters := form.delimiters()
+	body := &multipartBody{}
+	readers := make([]io.Reader, 0, 2*len(form.parts)+1)
+	for index, part := range form.parts {
+		readers = append(readers, bytes.NewReader(delimiters[index]), &lazyPart{body: body, open: part.open})
+	}
+	body.Reader = io.MultiReader(append(readers, bytes.NewReader(delimiters[len(form.parts)]))...)
+	return body
+}
+
+// NewRequest returns a request with the encoded form as its body, and a
+// GetBody method that rebuilds the body for each attempt.
+func (form *Multipart) NewRequest(ctx context.Context, method string, url string) (request *http.Request, err error) {
+	request, err = http.NewRequestWithContext(ctx, method, url, form.Reader())
+	if err != nil {
+		return nil, err
+	}
+	request.Header.Set("Content-Type", form.ContentType())
+	request.ContentLength = form.Size()
+	request.GetBody = func() (io.ReadCloser, error) {
+		return form.Reader(), nil
+	}
+	return request, nil
+}
+
+// delimiters returns the boundary and header preceding each part, followed by
+// the closing boundary.
+func (form *Multipart) delimiters() (delimiters [][]byte) {
+	var buffer bytes.Buffer
+	writer := multipart.NewWriter(&buffer)
+	_ = writer.SetBoundary(form.boundary)
+	for _, part := range form.parts {
+		_, _ = writer.CreatePart(part.header)
+		delimiters = append(delimiters, append([]byte(nil), buffer.Bytes()...))
+		buffer.Reset()
+	}
+	_ = writer.Close()
+	return append(delimiters, buffer.Bytes())
+}
+
+// escapeQuotes escapes the quotes and backslashes of a form field name or
+// filename.
+func escapeQuotes(value string) string {
+	return strings.NewReplacer("\\", "\\\\", `"`, "\\\"").Replace(value)
+}
+
+// multipartBody is a request body that reads the encoded form, and closes the
+// content of the current part when it is closed.
+type multipartBody struct {
+	io.Reader
+
+	// current contains the content of the part being read, if any.
+	current io.Closer
+}
+
+// Close closes the content of the part being read, if any.
+func (body *multipartBody) Close() (err error) {
+	if body.current == nil {
+		return nil
+	}
+	err = body.current.Close()
+	body.current = nil
+	return err
+}
+
+// lazyPart is a reader that opens the content of a part when it is first
+// read, and closes it when it is exhausted.
+type lazyPart struct {
+	// body contains the request body that closes the current part.
+	body *multipartBody
+
+	// open opens the content of the part.
+	open func() (io.ReadCloser, error)
+
+	// content contains the opened content, if any.
+	content io.ReadCloser
+}
+
+// Read reads from the content of the part, opening it if required.
+func (part *lazyPart) Read(buffer []byte) (count int, err error) {
+	// Open content on first read
+	if part.content == nil {
+		part.content, err = part.open()
+		if err != nil {
+			return 0, fmt.Errorf("unable to open multipart content: %w", err)
+		}
+		part.body.current = part.content
+	}
+
+	// Close content when exhausted
+	count, err = part.content.Read(buffer)
+	if err == io.EOF {
+		_ = part.body.Close()
+	}
+	return count, err
+}
@@ -0,0 +1,64 @@
+package retryable
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMultipart(test *testing.T) {
+	test.Parallel()
+
+	path := filepath.Join(test.TempDir(), "artifact.bin")
+	require.NoError(test, os.WriteFile(path, []byte(strings.Repeat("artifact", 1024)), 0o600))
+
+	var count atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		require.NoError(test, request.ParseMultipartForm(1024))
+		require.Equal(test, `quoted "name"`, request.FormValue("name"))
+		file, header, err := request.FormFile("artifact")
+		require.NoError(test, err)
+		require.Equal(test, "artifact.bin", header.Filename)
+		content, err := io.ReadAll(file)
+		require.NoError(test, err)
+		require.NoError(test, file.Close())
+		require.Equal(test, strings.Repeat("artifact", 1024), string(content))
+		if count.Add(1) == 1 {
+			writer.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer server.Close()
+
+	form := NewMultipart()
+	form.AddField("name", `quoted "name"`)
+	require.NoError(test, form.AddFile("artifact", path))
+	require.ErrorIs(test, form.AddFile("missing", filepath.Join(test.TempDir(), "missing")), ErrNonRetryable)
+	body, err := io.ReadAll(form.Reader())
+	require.NoError(test, err)
+	require.Equal(test, int64(len(body)), form.Size())
+
+	client := new(Client)
+	client.RetryCount = 1
+	client.RetryStatus = []int{http.StatusServiceUnavailable}
+	request, err := form.NewRequest(context.Background(), http.MethodPost, server.URL)
+	require.NoError(test, err)
+	require.Equal(test, form.Size(), request.ContentLength)
+	response, err := client.Do(request)
+	require.NoError(test, err)
+	require.NoError(test, response.Body.Close())
+	require.Equal(test, int32(2), count.Load())
+
+	// Send without a content length if a size is unknown
+	form.AddReader("stream", "stream.txt", -1, func() (io.ReadCloser, error) {
+		return io.NopCloser(strings.NewReader("streamed")), nil
+	})
+	require.Equal(test, int64(-1), form.Size())
+}
@@ -0,0 +1,141 @@
+package retryable
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// Preference defines a value of a content negotiation header with its
+// quality value, such as "en-US" with a quality of 0.8.
+type Preference struct {
+	// Value specifies the media type, language tag, or content coding.
+	Value string
+
+	// Quality specifies the relative weight of the value, between zero and
+	// one, where zero marks the value as not acceptable.
+	Quality float64
+}
+
+// Negotiation defines the content negotiation preferences of requests. The
+// headers are only set if the request does not already specify them.
+type Negotiation struct {
+	// Accept specifies the preferred media types.
+	Accept []Preference
+
+	// AcceptLanguage specifies the preferred languages.
+	AcceptLanguage []Preference
+
+	// AcceptEncoding specifies the preferred content codings. Specifying
+	// content codings disables the transparent decompression of the
+	// transport, so compressed response bodies must be decoded by the caller.
+	AcceptEncoding []Preference
+}
+
+// negotiationKey is the context key for the negotiation preferences.
+type negotiationKey struct{}
+
+// WithNegotiation returns a copy of the context with the specified content
+// negotiation preferences, which replace the preferences of the client for
+// each header that they specify, for requests sent with the context.
+func WithNegotiation(ctx context.Context, negotiation Negotiation) context.Context {
+	return context.WithValue(ctx, negotiationKey{}, negotiation)
+}
+
+// Ranked returns preferences for the values in order of preference, with
+// quality values descending from one in steps of 0.1, or smaller steps if
+// there are more than ten values.
+func Ranked(values ...string) (preferences []Preference) {
+	step := 0.1
+	if len(values) > 10 {
+		step = math.Max(math.Floor(900.0/float64(len(values)-1))/1000.0, 0.001)
+	}
+	for index, value := range values {
+		quality := math.Max(math.Round((1.0-float64(index)*step)*1000.0)/1000.0, 0.001)
+		preferences = append(preferences, Preference{Value: value, Quality: quality})
+	}
+	return preferences
+}
+
+// FormatPreferences formats the preferences as the value of a content
+// negotiation header, such as "en-US, en;q=0.9, *;q=0.1". Quality values are
+// clamped between zero and one, rounded to three decimals, and omitted if
+// they are one.
+func FormatPreferences(preferences ...Preference) string {
+	parts := make([]string, 0, len(preferences))
+	for _, preference := range preferences {
+		// Check for valid value
+		value := strings.TrimSpace(preference.Value)
+		if value == "" {
+			continue
+		}
+
+		// Format quality value
+		quality := math.Round(math.Min(math.Max(preference.Quality, 0.0), 1.0)*1000.0) / 1000.0
+		if quality < 1.0 {
+			value += ";q=" + strconv.FormatFloat(quality, 'f', -1, 64)
+		}
+		parts = append(parts, value)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// applyNegotiation returns a copy of the request with the content negotiation
+// headers of the request context or client, unless the request already
+// specifies them. If no headers are set, the request is returned unmodified.
+func (client *Client) applyNegotiation(request *http.Request) *http.Request {
+	// Merge preferences of request and client
+	negotiation := client.Negotiation
+	if override, ok := request.Context().Value(negotiationKey{}).(Negotiation); ok {
+		if override.Accept != nil {
+			negotiation.Accept = override.Accept
+		}
+		if override.AcceptLanguage != nil {
+			negotiation.AcceptLanguage = override.AcceptLanguage
+		}
+		if override.AcceptEncoding != nil {
+			negotiation.AcceptEncoding = override.AcceptEncoding
+		}
+	}
+
+	// Collect headers that are not already specified
+	headers := make(map[string]string)
+	for name, preferences := range map[string][]Preference{
+		"Accept":          negotiation.Accept,
+		"Accept-Language": negotiation.AcceptLanguage,
+		"Accept-Encoding": negotiation.AcceptEncoding,
+	} {
+		value := FormatPreferences(preferences...)
+		if value != "" && request.Header.Get(name) == "" {
+			headers[name] = value
+		}
+	}
+	if len(headers) == 0 {
+		return request
+	}
+
+	// Set headers on a copy of the request
+	prepared := request.Clone(request.Context())
+	if prepared.Header == nil {
+		prepared.Header = make(http.Header)
+	}
+	for name, value := range headers {
+		prepared.Header.Set(name, value)
+	}
+	return prepared
+}
+
+// parseVary returns the header names of the Vary header of the response.
+func parseVary(header http.Header) (names []string) {
+	for _, value := range header.Values("Vary") {
+		for _, name := range strings.Split(value, ",") {
+			name = strings.TrimSpace(name)
+			if name != "" {
+				names = append(names, name)
+			}
+		}
+	}
+	return names
+}
@@ -0,0 +1,66 @@
+package retryable
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFormatPreferences(test *testing.T) {
+	test.Parallel()
+
+	require.Equal(test, "en-US, en;q=0.9, *;q=0.8", FormatPreferences(Ranked("en-US", "en", "*")...))
+	require.Equal(test, "gzip, identity;q=0", FormatPreferences(Preference{Value: "gzip", Quality: 2}, Preference{Value: "identity"}, Preference{Value: " "}))
+	require.Equal(test, "a;q=0.333", FormatPreferences(Preference{Value: "a", Quality: 1.0 / 3.0}))
+	require.Empty(test, FormatPreferences())
+
+	preferences := Ranked("a", "b", "c", "d", "e", "f", "g", "h", "i", "j", "k", "l")
+	require.Len(test, preferences, 12)
+	require.Equal(test, 1.0, preferences[0].Quality)
+	require.Equal(test, 0.109, preferences[11].Quality)
+}
+
+func TestClient_Negotiation(test *testing.T) {
+	test.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		writer.Header().Set("Content-Language", "de")
+		writer.Header().Set("Vary", "Accept, Accept-Language")
+		_, _ = writer.Write([]byte(request.Header.Get("Accept") + "|" + request.Header.Get("Accept-Language")))
+	}))
+	defer server.Close()
+
+	var attempts []Attempt
+	client := new(Client)
+	client.Negotiation = Negotiation{
+		Accept:         []Preference{{Value: "application/json", Quality: 1}},
+		AcceptLanguage: Ranked("en", "de"),
+	}
+	client.OnAttempt = func(attempt Attempt) {
+		attempts = append(attempts, attempt)
+	}
+	get := func(ctx context.Context, header http.Header) string {
+		request, err := http.NewRequestWithContext(ctx, http.MethodGet, server.URL, nil)
+		require.NoError(test, err)
+		for name, values := range header {
+			request.Header[name] = values
+		}
+		response, err := client.Do(request)
+		require.NoError(test, err)
+		body, err := io.ReadAll(response.Body)
+		require.NoError(test, err)
+		return string(body)
+	}
+
+	require.Equal(test, "application/json|en, de;q=0.9", get(context.Background(), nil))
+	require.Equal(test, "de", attempts[0].ContentLanguage)
+	require.Equal(test, []string{"Accept", "Accept-Language"}, attempts[0].Vary)
+
+	ctx := WithNegotiation(context.Background(), Negotiation{AcceptLanguage: Ranked("fr")})
+	require.Equal(test, "application/json|fr", get(ctx, nil))
+	require.Equal(test, "text/plain|fr", get(ctx, http.Header{"Accept": {"text/plain"}}))
+}
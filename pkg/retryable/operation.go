@@ -0,0 +1,122 @@
+package retryable
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// ResponseCheck defines a custom success criterion for the responses of an
+// operation, such as a header or a field in the response body. It is called
+// with the buffered response body after the status code has been accepted.
+// If it returns an error, the attempt is retried, unless the error wraps
+// [ErrNonRetryable].
+type ResponseCheck func(response *http.Response, body []byte) (err error)
+
+// operationKey is the context key for the operation name.
+type operationKey struct{}
+
+// WithOperation returns a copy of the context with the specified operation
+// name, so that requests sent with the context are checked with the response
+// check registered for the operation.
+func WithOperation(ctx context.Context, name string) context.Context {
+	return context.WithValue(ctx, operationKey{}, name)
+}
+
+// responseCheckKey is the context key for the response check of a request.
+type responseCheckKey struct{}
+
+// WithResponseCheck returns a copy of the context with the specified response
+// check, so that responses of requests sent with the context are checked with
+// it after the response checks of the client and the operation, if any. It
+// allows checking the responses of a single request without registering an
+// operation on a shared client.
+func WithResponseCheck(ctx context.Context, check ResponseCheck) context.Context {
+	return context.WithValue(ctx, responseCheckKey{}, check)
+}
+
+// RegisterOperation registers the response check for the named operation,
+// replacing any existing response check for the operation.
+func (client *Client) RegisterOperation(name string, check ResponseCheck) {
+	state := client.state()
+	state.mutex.Lock()
+	defer state.mutex.Unlock()
+	if state.operations == nil {
+		state.operations = make(map[string]ResponseCheck)
+	}
+	state.operations[name] = check
+}
+
+// operationCheck returns the response check for the operation of the
+// request, or nil if the request has no operation. It returns an error if the
+// operation is not registered.
+func (client *Client) operationCheck(request *http.Request) (check ResponseCheck, err error) {
+	// Check for operation name
+	if request == nil {
+		return nil, nil
+	}
+	name, ok := request.Context().Value(operationKey{}).(string)
+	if !ok {
+		return nil, nil
+	}
+
+	// Check for registered operation
+	state := client.state()
+	state.mutex.Lock()
+	defer state.mutex.Unlock()
+	check, ok = state.operations[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: unknown operation (%s)", ErrNonRetryable, name)
+	}
+	return check, nil
+}
+
+// responseCheck returns the response check of the client, followed by the
+// response check of the operation of the request and the response check of
+// the request context, or nil if none is specified. It returns an error if
+// the operation is not registered.
+func (client *Client) responseCheck(request *http.Request) (check ResponseCheck, err error) {
+	// Collect response checks
+	operation, err := client.operationCheck(request)
+	if err != nil {
+		return nil, err
+	}
+	var scoped ResponseCheck
+	if request != nil {
+		scoped, _ = request.Context().Value(responseCheckKey{}).(ResponseCheck)
+	}
+	var checks []ResponseCheck
+	for _, check := range []ResponseCheck{client.CheckResponse, operation, scoped} {
+		if check != nil {
+			checks = append(checks, check)
+		}
+	}
+	switch len(checks) {
+	case 0:
+		return nil, nil
+	case 1:
+		return checks[0], nil
+	}
+
+	// Chain response checks
+	return func(response *http.Response, body []byte) (err error) {
+		for _, check := range checks {
+			err = check(response, body)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	}, nil
+}
+
+// applyResponseCheck applies the response check to the response, classifying
+// errors that are not already classified as retryable.
+func applyResponseCheck(check ResponseCheck, response *http.Response, body []byte) (err error) {
+	err = check(response, body)
+	if err == nil || errors.Is(err, ErrNonRetryable) || errors.Is(err, ErrRetryable) {
+		return err
+	}
+	return fmt.Errorf("%w: response check failed: %w", ErrRetryable, err)
+}
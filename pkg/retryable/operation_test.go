@@ -0,0 +1,147 @@
+package retryable
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestClient_RegisterOperation(test *testing.T) {
+	test.Parallel()
+
+	var attempts atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, _ *http.Request) {
+		_, _ = fmt.Fprintf(writer, `{"ok": %t}`, attempts.Add(1) >= 3)
+	}))
+	defer server.Close()
+
+	client := new(Client)
+	client.RetryCount = 5
+	client.StreamResponse = true
+	client.RequestTimeout = time.Minute
+	client.RegisterOperation("legacy", func(_ *http.Response, body []byte) error {
+		var result struct {
+			OK bool `json:"ok"`
+		}
+		err := json.Unmarshal(body, &result)
+		if err != nil {
+			return fmt.Errorf("%w: %w", ErrNonRetryable, err)
+		}
+		if !result.OK {
+			return errors.New("ok=false")
+		}
+		return nil
+	})
+
+	request, err := http.NewRequestWithContext(WithOperation(context.Background(), "legacy"), http.MethodGet, server.URL, nil)
+	require.NoError(test, err)
+	response, err := client.Do(request)
+	require.NoError(test, err)
+	require.Equal(test, int32(3), attempts.Load())
+	body, err := io.ReadAll(response.Body)
+	require.NoError(test, err)
+	require.JSONEq(test, `{"ok": true}`, string(body))
+
+	client.RetryCount = 0
+	attempts.Store(0)
+	_, err = client.Do(request)
+	require.ErrorIs(test, err, ErrRetryable)
+	require.ErrorContains(test, err, "ok=false")
+
+	response, err = client.Get(server.URL)
+	require.NoError(test, err)
+	require.IsType(test, new(cancelBody), response.Body)
+
+	request, err = http.NewRequestWithContext(WithOperation(context.Background(), "unknown"), http.MethodGet, server.URL, nil)
+	require.NoError(test, err)
+	attempts.Store(0)
+	_, err = client.Do(request)
+	require.ErrorIs(test, err, ErrNonRetryable)
+	require.ErrorContains(test, err, "unknown operation")
+	require.Zero(test, attempts.Load())
+}
+
+func TestApplyResponseCheck(test *testing.T) {
+	test.Parallel()
+
+	check := func(err error) ResponseCheck {
+		return func(*http.Response, []byte) error { return err }
+	}
+	require.NoError(test, applyResponseCheck(check(nil), nil, nil))
+	require.ErrorIs(test, applyResponseCheck(check(errors.New("failed")), nil, nil), ErrRetryable)
+	require.ErrorIs(test, applyResponseCheck(check(ErrNonRetryable), nil, nil), ErrNonRetryable)
+	require.NotErrorIs(test, applyResponseCheck(check(ErrNonRetryable), nil, nil), ErrRetryable)
+}
+
+func TestClient_CheckResponse(test *testing.T) {
+	test.Parallel()
+
+	var attempts atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		if attempts.Add(1) == 1 {
+			_, _ = writer.Write([]byte("fault"))
+			return
+		}
+		_, _ = writer.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	var calls []string
+	client := new(Client)
+	client.RetryCount = 2
+	client.StreamResponse = true
+	client.CheckResponse = func(response *http.Response, body []byte) error {
+		calls = append(calls, "client")
+		if string(body) == "fault" {
+			return errors.New("fault")
+		}
+		return nil
+	}
+	client.RegisterOperation("operation", func(response *http.Response, body []byte) error {
+		calls = append(calls, "operation")
+		return nil
+	})
+	response, err := client.Get(server.URL)
+	require.NoError(test, err)
+	require.NoError(test, response.Body.Close())
+	require.Equal(test, int32(2), attempts.Load())
+	require.Equal(test, []string{"client", "client"}, calls)
+
+	request, err := http.NewRequestWithContext(WithOperation(context.Background(), "operation"), http.MethodGet, server.URL, nil)
+	require.NoError(test, err)
+	calls = nil
+	response, err = client.Do(request)
+	require.NoError(test, err)
+	require.NoError(test, response.Body.Close())
+	require.Equal(test, []string{"client", "operation"}, calls)
+
+	ctx := WithResponseCheck(WithOperation(context.Background(), "operation"), func(response *http.Response, body []byte) error {
+		calls = append(calls, "request")
+		return nil
+	})
+	request, err = http.NewRequestWithContext(ctx, http.MethodGet, server.URL, nil)
+	require.NoError(test, err)
+	calls = nil
+	response, err = client.Do(request)
+	require.NoError(test, err)
+	require.NoError(test, response.Body.Close())
+	require.Equal(test, []string{"client", "operation", "request"}, calls)
+
+	client.CheckResponse = nil
+	request, err = http.NewRequestWithContext(WithResponseCheck(context.Background(), func(response *http.Response, body []byte) error {
+		return errors.New("fault")
+	}), http.MethodGet, server.URL, nil)
+	require.NoError(test, err)
+	_, err = client.Do(request)
+	require.ErrorIs(test, err, ErrRetryable)
+	require.ErrorContains(test, err, "response check failed: fault")
+}
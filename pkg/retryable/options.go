@@ -0,0 +1,73 @@
+package retryable
+
+import (
+	"context"
+	"net"
+	"net/http"
+)
+
+// Option defines a function that configures a client, such as an option that
+// configures the transport of the base HTTP client.
+type Option func(client *Client)
+
+// NewClient returns a new client with the default configuration of
+// [DefaultConfig], and the specified options applied in order.
+func NewClient(options ...Option) (client *Client) {
+	client, _ = FromConfig(DefaultConfig())
+	for _, option := range options {
+		option(client)
+	}
+	return client
+}
+
+// WithDialer returns an option that dials connections with the specified
+// function, such as the DialContext method of a [net.Dialer], on a copy of
+// the transport of the base HTTP client.
+func WithDialer(dial func(ctx context.Context, network string, address string) (net.Conn, error)) Option {
+	return func(client *Client) {
+		transport := client.httpTransport()
+		transport.DialContext = dial
+	}
+}
+
+// WithUnixSocket returns an option that connects to the Unix domain socket at
+// the specified path for every request, regardless of the host of the request
+// URL, such as "/var/run/docker.sock". Requests should use a placeholder
+// host, such as "http://localhost/v1.43/containers/json". Proxies are disabled,
+// since they cannot be reached through the socket.
+func WithUnixSocket(path string) Option {
+	return func(client *Client) {
+		var dialer net.Dialer
+		transport := client.httpTransport()
+		transport.Proxy = nil
+		transport.DialContext = func(ctx context.Context, _ string, _ string) (net.Conn, error) {
+			return dialer.DialContext(ctx, "unix", path)
+		}
+	}
+}
+
+// WithPolicy returns an option that replaces the retry parameters of the
+// client with the specified policy, such as to derive a client with a
+// different retry policy with [Client.With].
+func WithPolicy(policy Policy) Option {
+	return func(client *Client) {
+		policy.RetryStatus = append([]int(nil), policy.RetryStatus...)
+		policy.RetryRanges = append([]StatusRange(nil), policy.RetryRanges...)
+		policy.NoRetryStatus = append([]int(nil), policy.NoRetryStatus...)
+		client.setPolicy(policy)
+	}
+}
+
+// httpTransport replaces the transport of the base HTTP client with a copy
+// that can be configured without affecting other clients, and returns it. If
+// the transport is not an [net/http.Transport], it is replaced with a copy of
+// [net/http.DefaultTransport].
+func (client *Client) httpTransport() *http.Transport {
+	transport, ok := client.Transport.(*http.Transport)
+	if !ok {
+		transport, _ = http.DefaultTransport.(*http.Transport)
+	}
+	transport = transport.Clone()
+	client.Transport = transport
+	return transport
+}
@@ -0,0 +1,84 @@
+package retryable
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewClient(test *testing.T) {
+	test.Parallel()
+
+	client := NewClient()
+	require.Equal(test, DefaultClient.RetryCount, client.RetryCount)
+	require.Equal(test, DefaultClient.RetryStatus, client.RetryStatus)
+	require.Nil(test, client.Transport)
+
+	var options []string
+	client = NewClient(func(client *Client) {
+		options = append(options, "first")
+		client.RetryCount = 1
+	}, func(client *Client) {
+		options = append(options, "second")
+	})
+	require.Equal(test, []string{"first", "second"}, options)
+	require.Equal(test, 1, client.RetryCount)
+}
+
+func TestWithUnixSocket(test *testing.T) {
+	test.Parallel()
+
+	directory, err := os.MkdirTemp("", "retryable")
+	require.NoError(test, err)
+	defer os.RemoveAll(directory)
+	path := filepath.Join(directory, "daemon.sock")
+	listener, err := net.Listen("unix", path)
+	require.NoError(test, err)
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		_, _ = writer.Write([]byte(request.URL.Path))
+	}))
+	server.Listener = listener
+	server.Start()
+	defer server.Close()
+
+	client := NewClient(WithUnixSocket(path))
+	transport, ok := client.Transport.(*http.Transport)
+	require.True(test, ok)
+	require.Nil(test, transport.Proxy)
+	require.NotSame(test, http.DefaultTransport, client.Transport)
+	response, err := client.Get("http://localhost/v1.43/containers/json")
+	require.NoError(test, err)
+	body, err := io.ReadAll(response.Body)
+	require.NoError(test, err)
+	require.NoError(test, response.Body.Close())
+	require.Equal(test, "/v1.43/containers/json", string(body))
+}
+
+func TestWithDialer(test *testing.T) {
+	test.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {}))
+	defer server.Close()
+
+	var addresses []string
+	var dialer net.Dialer
+	client := NewClient(WithDialer(func(ctx context.Context, network string, address string) (net.Conn, error) {
+		addresses = append(addresses, address)
+		return dialer.DialContext(ctx, network, address)
+	}))
+	response, err := client.Get(server.URL)
+	require.NoError(test, err)
+	require.NoError(test, response.Body.Close())
+	require.Equal(test, []string{server.Listener.Addr().String()}, addresses)
+
+	client.Transport = http.NewFileTransport(http.Dir("."))
+	WithDialer(dialer.DialContext)(client)
+	require.IsType(test, new(http.Transport), client.Transport)
+}
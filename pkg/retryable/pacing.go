@@ -0,0 +1,90 @@
+package retryable
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// maxPacedHosts is the maximum number of hosts for which a server-specified
+// delay is retained by a client.
+const maxPacedHosts = 256
+
+// applyHostPacing delays the request until the most recent server-specified
+// delay for the host of the request has elapsed, returning an error if the
+// context is canceled or the client is shut down.
+func (client *Client) applyHostPacing(ctx context.Context, request *http.Request) (err error) {
+	// Check for host pacing or missing host
+	if !client.HostPacing || request.URL == nil {
+		return nil
+	}
+
+	// Check for pending delay
+	until, ok := client.state().pacedUntil(normalizeHost(request.URL.Scheme, request.URL.Host))
+	if !ok {
+		return nil
+	}
+	duration := until.Sub(client.clock().Now())
+	if duration <= 0 {
+		return nil
+	}
+
+	// Sleep until the delay elapses
+	err = client.sleepUnlessClosed(ctx, duration)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrNonRetryable, err)
+	}
+	return nil
+}
+
+// pacedUntil returns the time until which requests to the host are delayed,
+// if any.
+func (state *clientState) pacedUntil(host string) (until time.Time, ok bool) {
+	state.mutex.Lock()
+	defer state.mutex.Unlock()
+	until, ok = state.pacing[host]
+	return until, ok
+}
+
+// recordHostPacing records the server-specified delay of the response, if
+// any, so that subsequent requests to the same host are delayed.
+func (client *Client) recordHostPacing(request *http.Request, response *http.Response) {
+	// Check for server-specified delay
+	if !client.HostPacing && client.OnRetryAfter == nil {
+		return
+	}
+	duration := client.parseRetryDelay(response)
+	if duration <= 0 {
+		return
+	}
+
+	// Notify callback
+	host := normalizeHost(request.URL.Scheme, request.URL.Host)
+	if client.OnRetryAfter != nil {
+		client.OnRetryAfter(host, duration)
+	}
+	if !client.HostPacing {
+		return
+	}
+
+	// Store delay, evicting an arbitrary host if required
+	until := client.receivedAt(response).Add(duration)
+	state := client.state()
+	state.mutex.Lock()
+	defer state.mutex.Unlock()
+	if state.pacing == nil {
+		state.pacing = make(map[string]time.Time)
+	}
+	existing, ok := state.pacing[host]
+	if ok && existing.After(until) {
+		return
+	}
+	if !ok && len(state.pacing) >= maxPacedHosts {
+		for existing := range state.pacing {
+			delete(state.pacing, existing)
+			break
+		}
+	}
+	state.pacing[host] = until
+}
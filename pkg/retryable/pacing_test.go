@@ -0,0 +1,81 @@
+package retryable
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestClient_HostPacing(test *testing.T) {
+	test.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		if request.URL.Path == "/limited" {
+			writer.Header().Set("Retry-After", "2")
+			writer.WriteHeader(http.StatusTooManyRequests)
+		}
+	}))
+	defer server.Close()
+	address, err := url.Parse(server.URL)
+	require.NoError(test, err)
+
+	var hosts []string
+	clock := &MockClock{now: time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC)}
+	client := new(Client)
+	client.Clock = clock
+	client.OnRetryAfter = func(host string, delay time.Duration) {
+		hosts = append(hosts, host)
+		require.Equal(test, 2*time.Second, delay)
+	}
+	_, err = client.Get(server.URL + "/limited")
+	require.ErrorIs(test, err, ErrNonRetryable)
+	_, err = client.Get(server.URL + "/other")
+	require.NoError(test, err)
+	require.NotContains(test, clock.sleeps, 2*time.Second)
+
+	client.HostPacing = true
+	_, err = client.Get(server.URL + "/limited")
+	require.ErrorIs(test, err, ErrNonRetryable)
+	_, err = client.Get(server.URL + "/other")
+	require.NoError(test, err)
+	require.Contains(test, clock.sleeps, 2*time.Second)
+	require.Equal(test, []string{address.Host, address.Host}, hosts)
+
+	_, err = client.Get(server.URL + "/other")
+	require.NoError(test, err)
+	require.Equal(test, []time.Duration{0, 0, 0, 0, 2 * time.Second, 0}, clock.sleeps)
+}
+
+func TestClient_ApplyHostPacing(test *testing.T) {
+	test.Parallel()
+
+	clock := &MockClock{now: time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC)}
+	client := new(Client)
+	client.Clock = clock
+	client.HostPacing = true
+	request, err := http.NewRequest(http.MethodGet, "https://WWW.GITHUB.COM/", nil)
+	require.NoError(test, err)
+	response := &http.Response{Header: http.Header{"Retry-After": {"10"}}}
+	client.recordHostPacing(request, response)
+	response.Header.Set("Retry-After", "5")
+	client.recordHostPacing(request, response)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err = client.applyHostPacing(ctx, request)
+	require.ErrorIs(test, err, ErrNonRetryable)
+	require.ErrorIs(test, err, context.Canceled)
+	require.Equal(test, []time.Duration{10 * time.Second}, clock.sleeps)
+
+	err = client.applyHostPacing(context.Background(), request)
+	require.NoError(test, err)
+	require.Len(test, clock.sleeps, 1)
+
+	err = client.applyHostPacing(context.Background(), new(http.Request))
+	require.NoError(test, err)
+}
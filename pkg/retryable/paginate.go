@@ -0,0 +1,60 @@
+package retryable
+
+import (
+	"net/http"
+	"strings"
+)
+
+// NextLink returns a GET request for the URL of the Link header of the
+// response with relation type "next", resolved relative to the URL of the
+// request, and reports whether the response has a next page. It is intended
+// to be used as the next function of [Client.Paginate].
+func NextLink(response *http.Response) (request *http.Request, ok bool) {
+	// Check for next link
+	if response == nil || response.Request == nil || response.Request.URL == nil {
+		return nil, false
+	}
+	target := parseLink(response.Header, "next")
+	if target == "" {
+		return nil, false
+	}
+
+	// Resolve next link relative to request
+	location, err := response.Request.URL.Parse(target)
+	if err != nil {
+		return nil, false
+	}
+	request, err = http.NewRequestWithContext(response.Request.Context(), http.MethodGet, location.String(), nil)
+	if err != nil {
+		return nil, false
+	}
+	return request, true
+}
+
+// parseLink returns the target of the first link in the Link headers with
+// any of the specified relation types, or an empty string if there is no
+// such link.
+func parseLink(header http.Header, rels ...string) (target string) {
+	for _, link := range header.Values("Link") {
+		for _, part := range strings.Split(link, ",") {
+			target, params, found := strings.Cut(part, ";")
+			if !found {
+				continue
+			}
+			for _, param := range strings.Split(params, ";") {
+				name, value, _ := strings.Cut(strings.TrimSpace(param), "=")
+				if !strings.EqualFold(name, "rel") {
+					continue
+				}
+				for _, rel := range strings.Fields(strings.ToLower(strings.Trim(value, `"`))) {
+					for _, expected := range rels {
+						if rel == expected {
+							return strings.Trim(strings.TrimSpace(target), "<>")
+						}
+					}
+				}
+			}
+		}
+	}
+	return ""
+}
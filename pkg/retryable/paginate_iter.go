@@ -0,0 +1,65 @@
+//go:build go1.23
+
+package retryable
+
+import (
+	"context"
+	"io"
+	"iter"
+	"net/http"
+)
+
+// Paginate returns an iterator over the pages of a paginated resource,
+// starting with the specified request. Each page is fetched with retries, and
+// if a successful page reports an exhausted rate limit through the retry
+// delay parsers, the next page is not requested until the rate limit resets.
+// After each page is yielded, the next function returns the request for the
+// following page, and reports whether there is one, such as with [NextLink].
+// Headers of the first request that are not set on subsequent requests are
+// copied to them. The body of each page is closed once the iteration
+// advances, so it must be read before then. Iteration stops after the first
+// error. It requires Go 1.23 or later.
+func (client *Client) Paginate(ctx context.Context, request *http.Request, next func(response *http.Response) (request *http.Request, ok bool)) iter.Seq2[*http.Response, error] {
+	return func(yield func(*http.Response, error) bool) {
+		header := request.Header
+		page := request
+		for {
+			// Fetch page with retries
+			response, err := client.Do(page.WithContext(ctx))
+			if err != nil {
+				yield(nil, err)
+				return
+			}
+			if !yield(response, nil) {
+				_ = response.Body.Close()
+				return
+			}
+
+			// Determine next page
+			following, ok := next(response)
+			_, _ = io.Copy(io.Discard, response.Body)
+			_ = response.Body.Close()
+			if !ok || following == nil {
+				return
+			}
+			page = following
+			if page.Header == nil {
+				page.Header = make(http.Header)
+			}
+			for name, values := range header {
+				if _, ok := page.Header[name]; !ok {
+					page.Header[name] = append([]string(nil), values...)
+				}
+			}
+
+			// Wait for exhausted rate limit to reset
+			if delay := client.parseCustomRetryDelay(response); delay > 0 {
+				err = client.sleepUnlessClosed(ctx, delay)
+				if err != nil {
+					yield(nil, err)
+					return
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,79 @@
+//go:build go1.23
+
+package retryable
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestClient_Paginate(test *testing.T) {
+	test.Parallel()
+
+	var failures atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		if request.Header.Get("Accept") != "application/json" {
+			writer.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		page, _ := strconv.Atoi(request.URL.Query().Get("page"))
+		if page == 2 && failures.Add(1) == 1 {
+			writer.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		if page < 3 {
+			writer.Header().Set("Link", fmt.Sprintf(`</items?page=%d>; rel="next last"`, page+1))
+		}
+		if page == 1 {
+			writer.Header().Set("X-RateLimit-Remaining", "0")
+			writer.Header().Set("X-RateLimit-Reset", "30")
+		}
+		_, _ = writer.Write([]byte(strconv.Itoa(page)))
+	}))
+	defer server.Close()
+
+	clock := &MockClock{now: time.Unix(0, 0)}
+	client := new(Client)
+	client.RetryCount = 1
+	client.RetryStatus = []int{http.StatusServiceUnavailable}
+	client.RetryDelayParsers = []RetryDelayParser{ParseRateLimitReset("X-RateLimit-Remaining", "X-RateLimit-Reset")}
+	client.Clock = clock
+	request, err := http.NewRequest(http.MethodGet, server.URL+"/items?page=1", nil)
+	require.NoError(test, err)
+	request.Header.Set("Accept", "application/json")
+
+	var pages []string
+	for response, err := range client.Paginate(context.Background(), request, NextLink) {
+		require.NoError(test, err)
+		body, err := io.ReadAll(response.Body)
+		require.NoError(test, err)
+		pages = append(pages, string(body))
+	}
+	require.Equal(test, []string{"1", "2", "3"}, pages)
+	require.Equal(test, int32(2), failures.Load())
+	require.Contains(test, clock.sleeps, 30*time.Second)
+
+	pages = nil
+	for response, err := range client.Paginate(context.Background(), request, NextLink) {
+		require.NoError(test, err)
+		pages = append(pages, response.Status)
+		break
+	}
+	require.Len(test, pages, 1)
+
+	request, err = http.NewRequest(http.MethodGet, server.URL+"/items?page=1", nil)
+	require.NoError(test, err)
+	for response, err := range client.Paginate(context.Background(), request, NextLink) {
+		require.Nil(test, response)
+		require.ErrorIs(test, err, ErrNonRetryable)
+	}
+}
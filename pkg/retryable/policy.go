@@ -0,0 +1,172 @@
+package retryable
+
+import (
+	"time"
+)
+
+// Policy contains the retry parameters of a client, which can be replaced
+// atomically while the client is sending requests.
+type Policy struct {
+	// RetryStatus specifies the status codes that are retryable.
+	RetryStatus []int
+
+	// RetryRanges specifies ranges of status codes that are retryable, in
+	// addition to the retryable status codes.
+	RetryRanges []StatusRange
+
+	// NoRetryStatus specifies status codes that are not retryable, which take
+	// precedence over the retryable status codes and ranges.
+	NoRetryStatus []int
+
+	// RetryCount specifies the maximum number of retries per request.
+	RetryCount int
+
+	// RetryDelay specifies the delay between retries.
+	RetryDelay time.Duration
+
+	// RetryMultiplier specifies the exponential backoff multiplier for the
+	// retry delay.
+	RetryMultiplier float64
+
+	// RetryJitter specifies the random jitter applied to the retry delay.
+	RetryJitter float64
+
+	// JitterMode specifies how random jitter is applied to the retry delay.
+	JitterMode JitterMode
+
+	// RetryTimeout specifies the maximum total duration of retries per request.
+	RetryTimeout time.Duration
+
+	// MaxRetryDelay specifies the maximum delay between retries.
+	MaxRetryDelay time.Duration
+
+	// MaxTotalDelay specifies the maximum sum of the retry delays per
+	// request.
+	MaxTotalDelay time.Duration
+
+	// RequestDelay specifies a fixed delay applied to each request.
+	RequestDelay time.Duration
+
+	// RequestJitter specifies the random jitter applied to the request delay.
+	RequestJitter float64
+
+	// RequestTimeout specifies the maximum duration per request.
+	RequestTimeout time.Duration
+
+	// RequestSize specifies the maximum request size in bytes.
+	RequestSize int64
+
+	// ResponseSize specifies the maximum response size in bytes.
+	ResponseSize int64
+
+	// RedirectCount specifies the maximum number of redirects per request,
+	// across all retries.
+	RedirectCount int
+}
+
+// Policy returns the current retry parameters of the client, either from the
+// most recent call to [Client.UpdatePolicy] or from the exported fields.
+func (client *Client) Policy() (policy Policy) {
+	// Check for updated policy
+	if current := client.state().policy.Load(); current != nil {
+		policy = *current
+		policy.RetryStatus = append([]int(nil), current.RetryStatus...)
+		policy.RetryRanges = append([]StatusRange(nil), current.RetryRanges...)
+		policy.NoRetryStatus = append([]int(nil), current.NoRetryStatus...)
+		return policy
+	}
+
+	// Read policy from exported fields
+	return Policy{
+		RetryStatus:     append([]int(nil), client.RetryStatus...),
+		RetryRanges:     append([]StatusRange(nil), client.RetryRanges...),
+		NoRetryStatus:   append([]int(nil), client.NoRetryStatus...),
+		RetryCount:      client.RetryCount,
+		RetryDelay:      client.RetryDelay,
+		RetryMultiplier: client.RetryMultiplier,
+		RetryJitter:     client.RetryJitter,
+		JitterMode:      client.JitterMode,
+		RetryTimeout:    client.RetryTimeout,
+		MaxRetryDelay:   client.MaxRetryDelay,
+		MaxTotalDelay:   client.MaxTotalDelay,
+		RequestDelay:    client.RequestDelay,
+		RequestJitter:   client.RequestJitter,
+		RequestTimeout:  client.RequestTimeout,
+		RequestSize:     client.RequestSize,
+		ResponseSize:    client.ResponseSize,
+		RedirectCount:   client.RedirectCount,
+	}
+}
+
+// UpdatePolicy atomically replaces the retry parameters of the client, which
+// take precedence over the exported fields. Unlike mutating the exported
+// fields, it is safe to call while the client is sending requests, such as in
+// response to a feature flag or an admin endpoint. Requests that are already
+// in progress continue to use the previous parameters.
+func (client *Client) UpdatePolicy(policy Policy) {
+	policy.RetryStatus = append([]int(nil), policy.RetryStatus...)
+	policy.RetryRanges = append([]StatusRange(nil), policy.RetryRanges...)
+	policy.NoRetryStatus = append([]int(nil), policy.NoRetryStatus...)
+	client.state().policy.Store(&policy)
+}
+
+// snapshot returns a copy of the client with the current policy applied to
+// the exported fields, so that a request observes a consistent configuration
+// even if the policy is updated while it is in progress. Slices are copied so
+// that modifying their elements does not affect the snapshot. The copy shares
+// the state of the client.
+func (client *Client) snapshot() *Client {
+	// Ensure shared state is initialized before copying
+	policy := client.state().policy.Load()
+	copied := *client
+
+	// Apply updated policy
+	if policy != nil {
+		copied.setPolicy(*policy)
+	}
+
+	// Copy slices
+	copied.RetryStatus = append([]int(nil), copied.RetryStatus...)
+	copied.RetryRanges = append([]StatusRange(nil), copied.RetryRanges...)
+	copied.NoRetryStatus = append([]int(nil), copied.NoRetryStatus...)
+	if copied.PermanentStatus != nil {
+		copied.PermanentStatus = append([]int{}, copied.PermanentStatus...)
+	}
+	if copied.ErrorHeaders != nil {
+		copied.ErrorHeaders = append([]string{}, copied.ErrorHeaders...)
+	}
+	if copied.DeduplicateHeaders != nil {
+		copied.DeduplicateHeaders = append([]string{}, copied.DeduplicateHeaders...)
+	}
+	copied.RetryDelayParsers = append([]RetryDelayParser(nil), copied.RetryDelayParsers...)
+	copied.AllowDowngradeHosts = append([]string(nil), copied.AllowDowngradeHosts...)
+	copied.AttemptHeaders.Strip = append([]string(nil), copied.AttemptHeaders.Strip...)
+	copied.AllowedMethods = append([]string(nil), copied.AllowedMethods...)
+	copied.DeniedMethods = append([]string(nil), copied.DeniedMethods...)
+	copied.BodyTransforms = append([]BodyTransform(nil), copied.BodyTransforms...)
+	copied.Middleware = append([]Middleware(nil), copied.Middleware...)
+	copied.AttemptMiddleware = append([]Middleware(nil), copied.AttemptMiddleware...)
+	return &copied
+}
+
+// setPolicy replaces the exported retry parameters of the client with the
+// policy.
+func (client *Client) setPolicy(policy Policy) {
+	client.RetryStatus = policy.RetryStatus
+	client.RetryRanges = policy.RetryRanges
+	client.NoRetryStatus = policy.NoRetryStatus
+	client.RetryCount = policy.RetryCount
+	client.RetryDelay = policy.RetryDelay
+	client.RetryMultiplier = policy.RetryMultiplier
+	client.RetryJitter = policy.RetryJitter
+	client.JitterMode = policy.JitterMode
+	client.RetryTimeout = policy.RetryTimeout
+	client.MaxRetryDelay = policy.MaxRetryDelay
+	client.MaxTotalDelay = policy.MaxTotalDelay
+	client.RequestDelay = policy.RequestDelay
+	client.RequestJitter = policy.RequestJitter
+	client.RequestTimeout = policy.RequestTimeout
+	client.RequestSize = policy.RequestSize
+	client.ResponseSize = policy.ResponseSize
+	client.RedirectCount = policy.RedirectCount
+}
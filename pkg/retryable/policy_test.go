@@ -0,0 +1,110 @@
+package retryable
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestClient_Policy(test *testing.T) {
+	test.Parallel()
+
+	client := new(Client)
+	client.RetryCount = 3
+	client.RetryStatus = []int{http.StatusServiceUnavailable}
+	policy := client.Policy()
+	require.Equal(test, 3, policy.RetryCount)
+	require.Equal(test, []int{http.StatusServiceUnavailable}, policy.RetryStatus)
+
+	policy.RetryStatus[0] = http.StatusTooManyRequests
+	require.Equal(test, []int{http.StatusServiceUnavailable}, client.RetryStatus)
+
+	policy.RetryCount = 5
+	client.UpdatePolicy(policy)
+	policy.RetryStatus[0] = http.StatusBadGateway
+	require.Equal(test, 5, client.Policy().RetryCount)
+	require.Equal(test, []int{http.StatusTooManyRequests}, client.Policy().RetryStatus)
+	require.Equal(test, 3, client.RetryCount)
+	require.Len(test, client.Schedule(10), 5)
+}
+
+func TestClient_UpdatePolicy(test *testing.T) {
+	test.Parallel()
+
+	var attempts atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, _ *http.Request) {
+		attempts.Add(1)
+		writer.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	client := new(Client)
+	client.RetryCount = 5
+	client.RetryStatus = []int{http.StatusServiceUnavailable}
+	client.UpdatePolicy(Policy{RetryCount: 1, RetryStatus: []int{http.StatusServiceUnavailable}})
+	_, err := client.Get(server.URL)
+	require.ErrorIs(test, err, ErrRetryable)
+	require.Equal(test, int32(2), attempts.Load())
+
+	var group sync.WaitGroup
+	for index := 0; index < 10; index++ {
+		group.Add(2)
+		go func() {
+			defer group.Done()
+			_, _ = client.Get(server.URL)
+		}()
+		go func(index int) {
+			defer group.Done()
+			client.UpdatePolicy(Policy{RetryCount: index % 3, RetryDelay: time.Millisecond})
+		}(index)
+	}
+	group.Wait()
+}
+
+func TestClient_Snapshot(test *testing.T) {
+	test.Parallel()
+
+	var attempts atomic.Int32
+	started := make(chan struct{})
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, _ *http.Request) {
+		if attempts.Add(1) == 1 {
+			close(started)
+			<-release
+		}
+		writer.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	client := new(Client)
+	client.RetryCount = 2
+	client.RetryStatus = []int{http.StatusServiceUnavailable}
+	done := make(chan error)
+	go func() {
+		_, err := client.Get(server.URL)
+		done <- err
+	}()
+
+	<-started
+	client.UpdatePolicy(Policy{RetryCount: 10, RetryDelay: time.Hour})
+	close(release)
+	err := <-done
+	require.ErrorIs(test, err, ErrRetryable)
+	require.Equal(test, int32(3), attempts.Load())
+
+	snapshot := client.snapshot()
+	require.Equal(test, 10, snapshot.RetryCount)
+	require.Equal(test, time.Hour, snapshot.RetryDelay)
+	require.Same(test, client.state(), snapshot.state())
+
+	client = new(Client)
+	client.RetryStatus = []int{http.StatusServiceUnavailable}
+	snapshot = client.snapshot()
+	client.RetryStatus[0] = http.StatusBadGateway
+	require.Equal(test, []int{http.StatusServiceUnavailable}, snapshot.RetryStatus)
+}
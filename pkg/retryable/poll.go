@@ -0,0 +1,62 @@
+package retryable
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// Poll sends the request repeatedly until the predicate is satisfied by a
+// response, and returns that response. Each request is retried as with
+// [Client.Do], and any error ends polling. Successful responses that do not
+// satisfy the predicate are discarded, and the next request is sent after
+// the poll interval with the request jitter applied, so that polling does not
+// need to be expressed as retryable status codes. The response body is read
+// into memory before the predicate is called, so the predicate can read it
+// without affecting the returned response.
+func (client *Client) Poll(ctx context.Context, request *http.Request, until func(response *http.Response) bool) (response *http.Response, err error) {
+	// Check for valid request
+	if request == nil {
+		return nil, fmt.Errorf("%w: invalid request", ErrNonRetryable)
+	}
+
+	// Poll until the predicate is satisfied
+	for {
+		// Send request with retries
+		response, err = client.Do(request.WithContext(ctx))
+		if err != nil {
+			return response, err
+		}
+
+		// Check predicate against buffered response body
+		buffer, ok := BodyBytes(response)
+		if !ok {
+			buffer, err = io.ReadAll(response.Body)
+			_ = response.Body.Close()
+			if err != nil {
+				return nil, fmt.Errorf("%w: unable to read response body: %w", ErrRetryable, err)
+			}
+		}
+		response.Body = newBufferedBody(buffer)
+		if until(response) {
+			response.Body = newBufferedBody(buffer)
+			return response, nil
+		}
+
+		// Sleep for the poll interval with random jitter
+		err = client.sleepUnlessClosed(ctx, client.randomJitter(client.pollInterval(), client.RequestJitter))
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrNonRetryable, err)
+		}
+	}
+}
+
+// pollInterval returns the delay between the requests of [Client.Poll].
+func (client *Client) pollInterval() time.Duration {
+	if client.PollInterval <= 0 {
+		return time.Second
+	}
+	return client.PollInterval
+}
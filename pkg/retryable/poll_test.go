@@ -0,0 +1,67 @@
+package retryable
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestClient_Poll(test *testing.T) {
+	test.Parallel()
+
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		count := requests.Add(1)
+		if count == 2 {
+			writer.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		if count >= 4 {
+			_, _ = writer.Write([]byte("done"))
+			return
+		}
+		_, _ = writer.Write([]byte("pending " + strconv.Itoa(int(count))))
+	}))
+	defer server.Close()
+
+	clock := &MockClock{now: time.Unix(0, 0)}
+	client := new(Client)
+	client.RetryCount = 1
+	client.RetryStatus = []int{http.StatusServiceUnavailable}
+	client.PollInterval = 5 * time.Second
+	client.Clock = clock
+	request, err := http.NewRequest(http.MethodGet, server.URL, nil)
+	require.NoError(test, err)
+	response, err := client.Poll(context.Background(), request, func(response *http.Response) bool {
+		body, err := io.ReadAll(response.Body)
+		require.NoError(test, err)
+		return string(body) == "done"
+	})
+	require.NoError(test, err)
+	body, err := io.ReadAll(response.Body)
+	require.NoError(test, err)
+	require.Equal(test, "done", string(body))
+	require.Equal(test, int32(4), requests.Load())
+
+	var polls []time.Duration
+	for _, duration := range clock.sleeps {
+		if duration == 5*time.Second {
+			polls = append(polls, duration)
+		}
+	}
+	require.Len(test, polls, 2)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = client.Poll(ctx, request, func(*http.Response) bool { return false })
+	require.ErrorIs(test, err, context.Canceled)
+	_, err = client.Poll(context.Background(), nil, nil)
+	require.ErrorIs(test, err, ErrNonRetryable)
+}
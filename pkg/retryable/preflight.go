@@ -0,0 +1,64 @@
+package retryable
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// sendPreflight sends a lightweight preflight request to the URL of the
+// request before a large request body is resent after the previous attempt
+// failed without a response, so that the request body is not sent into a dead
+// connection. The preflight request is prepared like the attempt, so it is
+// sent to the same endpoint and through the same proxy, with the same
+// credentials and signature. It returns a retryable error if the preflight
+// request fails, and ignores the status code of the preflight response.
+func (client *Client) sendPreflight(ctx context.Context, request *http.Request, unreachable bool) (err error) {
+	// Check for large request body after connectivity error
+	if !unreachable || client.PreflightSize <= 0 || request.ContentLength < client.PreflightSize {
+		return nil
+	}
+
+	// Apply request timeout to context
+	if client.RequestTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, client.RequestTimeout)
+		defer cancel()
+	}
+
+	// Construct preflight request without body, prepared like the attempt
+	method := client.PreflightMethod
+	if method == "" {
+		method = http.MethodHead
+	}
+	preflight := request.Clone(ctx)
+	preflight.Method = method
+	preflight.Body, preflight.GetBody, preflight.ContentLength = nil, nil, 0
+	preflight, endpoint, proxy, err := client.prepareAttempt(ctx, preflight)
+	defer client.reportEndpoint(endpoint, &err)
+	if err != nil {
+		return err
+	}
+
+	// Send preflight request without following redirects
+	base := client.Client
+	base.CheckRedirect = func(*http.Request, []*http.Request) error {
+		return http.ErrUseLastResponse
+	}
+	base.Transport = client.endpointTransport(endpoint, client.transport())
+	response, err := base.Do(preflight)
+	client.reportProxy(proxy, err)
+
+	// Check that context is valid
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return fmt.Errorf("%w: %w", ErrNonRetryable, err)
+	}
+
+	// Check for error sending preflight request
+	if err != nil {
+		return fmt.Errorf("%w: unable to send preflight request: %w", ErrRetryable, err)
+	}
+	_ = response.Body.Close()
+	return nil
+}
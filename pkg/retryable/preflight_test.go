@@ -0,0 +1,106 @@
+package retryable
+
+import (
+	"bytes"
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestClient_Preflight(test *testing.T) {
+	test.Parallel()
+
+	var mutex sync.Mutex
+	var methods []string
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		mutex.Lock()
+		methods = append(methods, request.Method)
+		count := len(methods)
+		mutex.Unlock()
+		if count == 1 {
+			conn, _, _ := writer.(http.Hijacker).Hijack()
+			_ = conn.Close()
+		}
+	}))
+	defer server.Close()
+
+	client := new(Client)
+	client.RetryCount = 1
+	client.PreflightSize = 4
+	response, err := client.Post(server.URL, "text/plain", bytes.NewReader([]byte("large")))
+	require.NoError(test, err)
+	require.NoError(test, response.Body.Close())
+	require.Equal(test, []string{http.MethodPost, http.MethodHead, http.MethodPost}, methods)
+
+	methods = nil
+	client.PreflightMethod = http.MethodOptions
+	response, err = client.Post(server.URL, "text/plain", bytes.NewReader([]byte("large")))
+	require.NoError(test, err)
+	require.NoError(test, response.Body.Close())
+	require.Equal(test, []string{http.MethodPost, http.MethodOptions, http.MethodPost}, methods)
+
+	methods = nil
+	response, err = client.Post(server.URL, "text/plain", bytes.NewReader([]byte("sm")))
+	require.NoError(test, err)
+	require.NoError(test, response.Body.Close())
+	require.Equal(test, []string{http.MethodPost, http.MethodPost}, methods)
+}
+
+func TestClient_PreflightPrepared(test *testing.T) {
+	test.Parallel()
+
+	var mutex sync.Mutex
+	var requests []string
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		mutex.Lock()
+		requests = append(requests, request.Method+" "+request.Header.Get("X-Signed"))
+		count := len(requests)
+		mutex.Unlock()
+		if count == 1 {
+			conn, _, _ := writer.(http.Hijacker).Hijack()
+			_ = conn.Close()
+		}
+	}))
+	defer server.Close()
+	endpoint, err := url.Parse(server.URL)
+	require.NoError(test, err)
+
+	client := new(Client)
+	client.RetryCount = 1
+	client.PreflightSize = 4
+	client.EndpointSelector = &FailoverEndpointSelector{Endpoints: []*url.URL{endpoint}}
+	client.Signer = SignerFunc(func(request *http.Request) error {
+		request.Header.Set("X-Signed", request.Method)
+		return nil
+	})
+	response, err := client.Post("http://service.invalid/", "text/plain", bytes.NewReader([]byte("large")))
+	require.NoError(test, err)
+	require.NoError(test, response.Body.Close())
+	require.Equal(test, []string{"POST POST", "HEAD HEAD", "POST POST"}, requests)
+}
+
+func TestClient_SendPreflight(test *testing.T) {
+	test.Parallel()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(test, err)
+	address := "http://" + listener.Addr().String()
+	require.NoError(test, listener.Close())
+
+	client := new(Client)
+	client.PreflightSize = 1
+	request, err := http.NewRequest(http.MethodPut, address, bytes.NewReader([]byte("large")))
+	require.NoError(test, err)
+	require.NoError(test, client.sendPreflight(context.Background(), request, false))
+	require.ErrorIs(test, client.sendPreflight(context.Background(), request, true), ErrRetryable)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	require.ErrorIs(test, client.sendPreflight(ctx, request, true), ErrNonRetryable)
+}
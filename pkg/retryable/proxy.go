@@ -0,0 +1,193 @@
+package retryable
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ProxySelector defines the selection of a proxy for each attempt of a
+// request, so that retries can go through a different proxy from a pool when
+// a specific egress proxy is failing.
+type ProxySelector interface {
+	// SelectProxy returns the proxy for the attempt of the request, or nil to
+	// connect directly.
+	SelectProxy(attempt int, request *http.Request) (proxy *url.URL, err error)
+
+	// ReportProxy reports the outcome of sending a request through the proxy,
+	// where a nil error indicates that a response was received.
+	ReportProxy(proxy *url.URL, err error)
+}
+
+// RoundRobinProxySelector is a [ProxySelector] that rotates through the
+// proxies in order, regardless of failures.
+type RoundRobinProxySelector struct {
+	// Proxies specifies the pool of proxies.
+	Proxies []*url.URL
+
+	// next contains the position of the next proxy.
+	next atomic.Uint64
+}
+
+// SelectProxy returns the next proxy of the pool, or nil if the pool is empty.
+func (selector *RoundRobinProxySelector) SelectProxy(_ int, _ *http.Request) (proxy *url.URL, err error) {
+	if len(selector.Proxies) == 0 {
+		return nil, nil
+	}
+	index := selector.next.Add(1) - 1
+	return selector.Proxies[index%uint64(len(selector.Proxies))], nil
+}
+
+// ReportProxy ignores the outcome of the request.
+func (selector *RoundRobinProxySelector) ReportProxy(_ *url.URL, _ error) {}
+
+// FailureAwareProxySelector is a [ProxySelector] that rotates through the
+// proxies in order, skipping proxies that failed within the cooldown period.
+// If every proxy failed within the cooldown period, the proxy that failed
+// first is selected.
+type FailureAwareProxySelector struct {
+	// Proxies specifies the pool of proxies.
+	Proxies []*url.URL
+
+	// Cooldown specifies how long a failed proxy is skipped. If the cooldown
+	// is zero, failed proxies are skipped for one minute.
+	Cooldown time.Duration
+
+	// Clock specifies the time source. If the clock is nil, the system time
+	// is used.
+	Clock Clock
+
+	// mutex guards access to the selection state.
+	mutex sync.Mutex
+
+	// next contains the position of the next proxy.
+	next int
+
+	// failures contains the time of the last failure per proxy.
+	failures map[string]time.Time
+}
+
+// SelectProxy returns the next proxy of the pool that has not failed within
+// the cooldown period, or nil if the pool is empty.
+func (selector *FailureAwareProxySelector) SelectProxy(_ int, _ *http.Request) (proxy *url.URL, err error) {
+	// Check for proxies
+	if len(selector.Proxies) == 0 {
+		return nil, nil
+	}
+	selector.mutex.Lock()
+	defer selector.mutex.Unlock()
+
+	// Select next healthy proxy, or the proxy that failed first
+	now := selector.now()
+	var oldest time.Time
+	for offset := 0; offset < len(selector.Proxies); offset++ {
+		candidate := selector.Proxies[(selector.next+offset)%len(selector.Proxies)]
+		failed, ok := selector.failures[candidate.String()]
+		if !ok || now.Sub(failed) >= selector.cooldown() {
+			selector.next = (selector.next + offset + 1) % len(selector.Proxies)
+			return candidate, nil
+		}
+		if proxy == nil || failed.Before(oldest) {
+			proxy, oldest = candidate, failed
+		}
+	}
+	return proxy, nil
+}
+
+// ReportProxy records the failure of the proxy, or clears it on success.
+func (selector *FailureAwareProxySelector) ReportProxy(proxy *url.URL, err error) {
+	// Check for proxy
+	if proxy == nil {
+		return
+	}
+	selector.mutex.Lock()
+	defer selector.mutex.Unlock()
+
+	// Record outcome
+	if err == nil {
+		delete(selector.failures, proxy.String())
+		return
+	}
+	if selector.failures == nil {
+		selector.failures = make(map[string]time.Time)
+	}
+	selector.failures[proxy.String()] = selector.now()
+}
+
+// cooldown returns the cooldown period of failed proxies.
+func (selector *FailureAwareProxySelector) cooldown() time.Duration {
+	if selector.Cooldown <= 0 {
+		return time.Minute
+	}
+	return selector.Cooldown
+}
+
+// now returns the current time of the clock.
+func (selector *FailureAwareProxySelector) now() time.Time {
+	if selector.Clock == nil {
+		return time.Now()
+	}
+	return selector.Clock.Now()
+}
+
+// proxyKey is the context key for the proxy selected for an attempt.
+type proxyKey struct{}
+
+// selectedProxy contains the proxy selected for an attempt, which is nil for
+// a direct connection.
+type selectedProxy struct {
+	// url specifies the URL of the proxy.
+	url *url.URL
+}
+
+// selectProxy returns a copy of the request with the proxy selected for the
+// attempt, and records the proxy in the attempt metadata.
+func (client *Client) selectProxy(ctx context.Context, request *http.Request) (_ *http.Request, proxy *url.URL, err error) {
+	// Check for proxy selector
+	if client.ProxySelector == nil {
+		return request, nil, nil
+	}
+
+	// Select proxy for attempt
+	proxy, err = client.ProxySelector.SelectProxy(attemptNumber(ctx), request)
+	if err != nil {
+		return request, nil, fmt.Errorf("%w: unable to select proxy: %w", ErrRetryable, err)
+	}
+	if recorder := attemptRecorderFrom(ctx); recorder != nil && proxy != nil {
+		recorder.update(func(attempt *Attempt) {
+			attempt.Proxy = proxy.Redacted()
+		})
+	}
+	ctx = context.WithValue(request.Context(), proxyKey{}, &selectedProxy{url: proxy})
+	return request.WithContext(ctx), proxy, nil
+}
+
+// reportProxy reports the outcome of sending a request through the proxy to
+// the proxy selector, ignoring requests that were canceled.
+func (client *Client) reportProxy(proxy *url.URL, err error) {
+	if client.ProxySelector == nil || errors.Is(err, context.Canceled) {
+		return
+	}
+	client.ProxySelector.ReportProxy(proxy, err)
+}
+
+// attemptProxy returns a proxy function that returns the proxy selected for
+// the attempt, falling back to the specified proxy function if no proxy was
+// selected.
+func attemptProxy(fallback func(*http.Request) (*url.URL, error)) func(*http.Request) (*url.URL, error) {
+	return func(request *http.Request) (proxy *url.URL, err error) {
+		selected, ok := request.Context().Value(proxyKey{}).(*selectedProxy)
+		if ok {
+			return selected.url, nil
+		}
+		if fallback == nil {
+			return nil, nil
+		}
+		return fallback(request)
+	}
+}
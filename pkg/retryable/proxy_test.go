@@ -0,0 +1,115 @@
+package retryable
+
+import (
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestClient_ProxySelector(test *testing.T) {
+	test.Parallel()
+
+	proxy := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		_, _ = writer.Write([]byte(request.URL.String()))
+	}))
+	defer proxy.Close()
+	healthy, err := url.Parse(proxy.URL)
+	require.NoError(test, err)
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(test, err)
+	dead, err := url.Parse("http://" + listener.Addr().String())
+	require.NoError(test, err)
+	require.NoError(test, listener.Close())
+
+	var attempts []Attempt
+	client := new(Client)
+	client.RetryCount = 1
+	client.ProxySelector = &RoundRobinProxySelector{Proxies: []*url.URL{dead, healthy}}
+	client.OnAttempt = func(attempt Attempt) {
+		attempts = append(attempts, attempt)
+	}
+	response, err := client.Get("http://example.invalid/resource")
+	require.NoError(test, err)
+	require.NoError(test, response.Body.Close())
+	require.Len(test, attempts, 2)
+	require.Equal(test, dead.String(), attempts[0].Proxy)
+	require.ErrorIs(test, attempts[0].Err, ErrRetryable)
+	require.Equal(test, healthy.String(), attempts[1].Proxy)
+	require.NoError(test, attempts[1].Err)
+}
+
+func TestRoundRobinProxySelector(test *testing.T) {
+	test.Parallel()
+
+	first, second := &url.URL{Host: "first"}, &url.URL{Host: "second"}
+	selector := new(RoundRobinProxySelector)
+	proxy, err := selector.SelectProxy(0, nil)
+	require.NoError(test, err)
+	require.Nil(test, proxy)
+
+	selector.Proxies = []*url.URL{first, second}
+	for _, expected := range []*url.URL{first, second, first} {
+		proxy, err = selector.SelectProxy(0, nil)
+		require.NoError(test, err)
+		require.Same(test, expected, proxy)
+	}
+}
+
+func TestFailureAwareProxySelector(test *testing.T) {
+	test.Parallel()
+
+	first, second := &url.URL{Host: "first"}, &url.URL{Host: "second"}
+	clock := &MockClock{now: time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC)}
+	selector := &FailureAwareProxySelector{Proxies: []*url.URL{first, second}, Clock: clock}
+	selector.ReportProxy(first, errors.New("failed"))
+	for index := 0; index < 2; index++ {
+		proxy, err := selector.SelectProxy(0, nil)
+		require.NoError(test, err)
+		require.Same(test, second, proxy)
+	}
+
+	clock.now = clock.now.Add(time.Second)
+	selector.ReportProxy(second, errors.New("failed"))
+	proxy, err := selector.SelectProxy(0, nil)
+	require.NoError(test, err)
+	require.Same(test, first, proxy)
+
+	clock.now = clock.now.Add(time.Minute)
+	selector.ReportProxy(second, nil)
+	proxy, err = selector.SelectProxy(0, nil)
+	require.NoError(test, err)
+	require.Same(test, first, proxy)
+	proxy, err = selector.SelectProxy(0, nil)
+	require.NoError(test, err)
+	require.Same(test, second, proxy)
+	selector.ReportProxy(nil, errors.New("ignored"))
+}
+
+func TestAttemptProxy(test *testing.T) {
+	test.Parallel()
+
+	request, err := http.NewRequest(http.MethodGet, "http://example.com", nil)
+	require.NoError(test, err)
+	proxy, err := attemptProxy(nil)(request)
+	require.NoError(test, err)
+	require.Nil(test, proxy)
+
+	fallback := &url.URL{Host: "fallback"}
+	proxy, err = attemptProxy(http.ProxyURL(fallback))(request)
+	require.NoError(test, err)
+	require.Same(test, fallback, proxy)
+
+	client := new(Client)
+	client.ProxySelector = new(RoundRobinProxySelector)
+	request, _, err = client.selectProxy(request.Context(), request)
+	require.NoError(test, err)
+	proxy, err = attemptProxy(http.ProxyURL(fallback))(request)
+	require.NoError(test, err)
+	require.Nil(test, proxy)
+}
@@ -0,0 +1,102 @@
+package retryable
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// raceResult contains the outcome of one of the requests of a race.
+type raceResult struct {
+	// index specifies the position of the request.
+	index int
+
+	// response specifies the response of the request, if any.
+	response *http.Response
+
+	// err specifies the error of the request, if any.
+	err error
+}
+
+// Race sends different requests for the same resource concurrently, such as
+// requests to different regions or API versions, and returns the response of
+// the first request that succeeds. Each request is retried as with [Client.Do]
+// and the remaining requests are canceled once one succeeds. If all requests
+// fail, the errors are joined in the order of the requests. The requests keep
+// the values of their own context, and are also canceled with the specified
+// context.
+func (client *Client) Race(ctx context.Context, requests ...*http.Request) (response *http.Response, err error) {
+	// Check for requests
+	if len(requests) == 0 {
+		return nil, fmt.Errorf("%w: no requests to race", ErrNonRetryable)
+	}
+
+	// Send requests concurrently
+	results := make(chan raceResult, len(requests))
+	cancels := make([]context.CancelFunc, len(requests))
+	for index, request := range requests {
+		if request == nil {
+			cancels[index] = func() {}
+			go client.raceRequest(index, nil, results)
+			continue
+		}
+		var child context.Context
+		child, cancels[index] = raceContext(ctx, request.Context())
+		go client.raceRequest(index, request.WithContext(child), results)
+	}
+
+	// Wait for the first success
+	errs := make([]error, len(requests))
+	for remaining := len(requests); remaining > 0; remaining-- {
+		result := <-results
+		if result.err != nil {
+			cancels[result.index]()
+			errs[result.index] = result.err
+			continue
+		}
+
+		// Cancel remaining requests, keeping the winner alive until closed
+		for index, cancel := range cancels {
+			if index != result.index {
+				cancel()
+			}
+		}
+		go discardRace(remaining-1, results)
+		response = result.response
+		response.Body = cancelOnClose(response.Body, cancels[result.index])
+		return response, nil
+	}
+	return nil, errors.Join(errs...)
+}
+
+// raceRequest sends the request and delivers the result to the channel.
+func (client *Client) raceRequest(index int, request *http.Request, results chan<- raceResult) {
+	response, err := client.Do(request)
+	results <- raceResult{index: index, response: response, err: err}
+}
+
+// raceContext returns a copy of the context of the request that is also
+// canceled when the context of the race is done.
+func raceContext(ctx context.Context, parent context.Context) (child context.Context, cancel context.CancelFunc) {
+	child, cancel = context.WithCancel(parent)
+	go func() {
+		select {
+		case <-ctx.Done():
+			cancel()
+		case <-child.Done():
+		}
+	}()
+	return child, cancel
+}
+
+// discardRace closes the response bodies of the remaining requests of a race
+// that succeeded before they were canceled.
+func discardRace(remaining int, results <-chan raceResult) {
+	for ; remaining > 0; remaining-- {
+		result := <-results
+		if result.err == nil && result.response != nil {
+			_ = result.response.Body.Close()
+		}
+	}
+}
@@ -0,0 +1,79 @@
+package retryable
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestClient_Race(test *testing.T) {
+	test.Parallel()
+
+	canceled := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		switch request.URL.Path {
+		case "/slow":
+			select {
+			case <-request.Context().Done():
+				close(canceled)
+			case <-time.After(10 * time.Second):
+			}
+		case "/fast":
+			_, _ = writer.Write([]byte("fast"))
+		default:
+			writer.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	client := new(Client)
+	slow, err := http.NewRequest(http.MethodGet, server.URL+"/slow", nil)
+	require.NoError(test, err)
+	missing, err := http.NewRequest(http.MethodGet, server.URL+"/missing", nil)
+	require.NoError(test, err)
+	fast, err := http.NewRequest(http.MethodGet, server.URL+"/fast", nil)
+	require.NoError(test, err)
+	response, err := client.Race(context.Background(), slow, missing, fast)
+	require.NoError(test, err)
+	body, err := io.ReadAll(response.Body)
+	require.NoError(test, err)
+	require.Equal(test, "fast", string(body))
+	require.NoError(test, response.Body.Close())
+	select {
+	case <-canceled:
+	case <-time.After(5 * time.Second):
+		test.Fatal("slow request was not canceled")
+	}
+
+	_, err = client.Race(context.Background(), missing, nil)
+	require.ErrorIs(test, err, ErrNonRetryable)
+	require.ErrorContains(test, err, "404")
+
+	_, err = client.Race(context.Background())
+	require.ErrorIs(test, err, ErrNonRetryable)
+}
+
+func TestClient_RaceContext(test *testing.T) {
+	test.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		<-request.Context().Done()
+	}))
+	defer server.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	client := new(Client)
+	request, err := http.NewRequest(http.MethodGet, server.URL, nil)
+	require.NoError(test, err)
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		cancel()
+	}()
+	_, err = client.Race(ctx, request)
+	require.ErrorIs(test, err, context.Canceled)
+}
@@ -0,0 +1,57 @@
+package retryable
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// RetryDelayParser parses a server-specified retry delay from the response,
+// relative to the specified time, returning zero if no delay is specified.
+type RetryDelayParser func(response *http.Response, now time.Time) (delay time.Duration)
+
+// ParseRateLimitReset returns a [RetryDelayParser] for rate limit headers that
+// specify the number of remaining requests, and the time when the rate limit
+// resets in seconds since the Unix epoch, such as X-RateLimit-Remaining and
+// X-RateLimit-Reset. A delay is only returned if no requests remain.
+func ParseRateLimitReset(remaining string, reset string) RetryDelayParser {
+	return func(response *http.Response, now time.Time) (delay time.Duration) {
+		// Check for exhausted rate limit
+		if response.Header.Get(remaining) != "0" {
+			return 0
+		}
+
+		// Parse reset time
+		seconds, err := strconv.ParseInt(response.Header.Get(reset), 10, 64)
+		if err != nil {
+			return 0
+		}
+		return time.Unix(seconds, 0).Sub(now)
+	}
+}
+
+// ParseRateLimitSeconds returns a [RetryDelayParser] for a rate limit header
+// that specifies the number of seconds until the rate limit resets, such as
+// RateLimit-Reset.
+func ParseRateLimitSeconds(reset string) RetryDelayParser {
+	return func(response *http.Response, _ time.Time) (delay time.Duration) {
+		seconds, err := strconv.ParseFloat(response.Header.Get(reset), 64)
+		if err != nil || seconds <= 0 {
+			return 0
+		}
+		return time.Duration(seconds * float64(time.Second))
+	}
+}
+
+// parseCustomRetryDelay parses the retry delay with the configured parsers,
+// returning the first non-zero delay.
+func (client *Client) parseCustomRetryDelay(response *http.Response) (delay time.Duration) {
+	now := client.receivedAt(response)
+	for _, parser := range client.RetryDelayParsers {
+		delay = parser(response, now)
+		if delay > 0 {
+			return delay
+		}
+	}
+	return 0
+}
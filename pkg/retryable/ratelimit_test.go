@@ -0,0 +1,57 @@
+package retryable
+
+import (
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseRateLimitReset(test *testing.T) {
+	test.Parallel()
+
+	now := time.Unix(1_700_000_000, 0)
+	parser := ParseRateLimitReset("X-RateLimit-Remaining", "X-RateLimit-Reset")
+	response := &http.Response{Header: make(http.Header)}
+	require.Zero(test, parser(response, now))
+
+	response.Header.Set("X-RateLimit-Reset", strconv.FormatInt(now.Add(time.Minute).Unix(), 10))
+	response.Header.Set("X-RateLimit-Remaining", "1")
+	require.Zero(test, parser(response, now))
+
+	response.Header.Set("X-RateLimit-Remaining", "0")
+	require.Equal(test, time.Minute, parser(response, now))
+
+	response.Header.Set("X-RateLimit-Reset", "xyz")
+	require.Zero(test, parser(response, now))
+}
+
+func TestParseRateLimitSeconds(test *testing.T) {
+	test.Parallel()
+
+	parser := ParseRateLimitSeconds("RateLimit-Reset")
+	response := &http.Response{Header: make(http.Header)}
+	require.Zero(test, parser(response, time.Now()))
+
+	response.Header.Set("RateLimit-Reset", "1.5")
+	require.Equal(test, 1500*time.Millisecond, parser(response, time.Now()))
+}
+
+func TestClient_ParseCustomRetryDelay(test *testing.T) {
+	test.Parallel()
+
+	client := new(Client)
+	response := &http.Response{Header: http.Header{"Retry-After": {"xyz"}, "Ratelimit-Reset": {"2"}}}
+	require.Zero(test, client.parseRetryDelay(response))
+
+	client.RetryDelayParsers = []RetryDelayParser{ParseRateLimitSeconds("X-Missing"), ParseRateLimitSeconds("RateLimit-Reset")}
+	require.Equal(test, 2*time.Second, client.parseRetryDelay(response))
+
+	response.Header.Set("Retry-After", "1")
+	require.Equal(test, time.Second, client.parseRetryDelay(response))
+
+	response.Header.Del("Retry-After")
+	require.Equal(test, 2*time.Second, client.parseRetryDelay(response))
+}
@@ -0,0 +1,56 @@
+package retryable
+
+import (
+	"net/http"
+	"time"
+)
+
+// markReceived records the current time as the time the response was
+// received, in the attempt recorder of the request of the response.
+func (client *Client) markReceived(response *http.Response) {
+	if response == nil || response.Request == nil {
+		return
+	}
+	recorder := attemptRecorderFrom(response.Request.Context())
+	if recorder == nil {
+		return
+	}
+	recorder.mutex.Lock()
+	defer recorder.mutex.Unlock()
+	recorder.received = client.clock().Now()
+}
+
+// receivedAt returns the time the response was received, or the current time
+// if unknown.
+func (client *Client) receivedAt(response *http.Response) (received time.Time) {
+	if response != nil && response.Request != nil {
+		if recorder := attemptRecorderFrom(response.Request.Context()); recorder != nil {
+			recorder.mutex.Lock()
+			received = recorder.received
+			recorder.mutex.Unlock()
+		}
+	}
+	if received.IsZero() {
+		return client.clock().Now()
+	}
+	return received
+}
+
+// remainingRetryDelay returns the part of the server-specified retry delay
+// that remains, measured from the time the response was received rather than
+// the time it is processed. If the response does not specify a retry delay,
+// ok is false.
+func (client *Client) remainingRetryDelay(response *http.Response) (delay time.Duration, ok bool) {
+	// Check for server-specified delay
+	delay = client.parseRetryDelay(response)
+	if delay <= 0 {
+		return 0, false
+	}
+
+	// Subtract time elapsed since the response was received
+	delay -= client.clock().Now().Sub(client.receivedAt(response))
+	if delay < 0 {
+		delay = 0
+	}
+	return delay, true
+}
@@ -0,0 +1,51 @@
+package retryable
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestClient_RemainingRetryDelay(test *testing.T) {
+	test.Parallel()
+
+	clock := &MockClock{now: time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC)}
+	client := new(Client)
+	client.Clock = clock
+	_, ok := client.remainingRetryDelay(nil)
+	require.False(test, ok)
+
+	ctx := client.startAttempt(context.Background(), 0, "")
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, "https://www.github.com/", nil)
+	require.NoError(test, err)
+	response := &http.Response{Header: http.Header{"Retry-After": {"10"}}, Request: request}
+	client.markReceived(response)
+	require.Equal(test, clock.Now(), client.receivedAt(response))
+	require.Same(test, request, response.Request)
+
+	err = clock.Sleep(context.Background(), 4*time.Second)
+	require.NoError(test, err)
+	delay, ok := client.remainingRetryDelay(response)
+	require.True(test, ok)
+	require.Equal(test, 6*time.Second, delay)
+
+	response.Header.Set("Retry-After", clock.Now().Add(2*time.Second).Format(time.RFC1123))
+	delay, ok = client.remainingRetryDelay(response)
+	require.True(test, ok)
+	require.Equal(test, 2*time.Second, delay)
+
+	response.Header.Set("Retry-After", "3")
+	delay, ok = client.remainingRetryDelay(response)
+	require.True(test, ok)
+	require.Zero(test, delay)
+
+	response.Request = request.WithContext(context.Background())
+	client.markReceived(response)
+	require.Equal(test, clock.Now(), client.receivedAt(response))
+	delay, ok = client.remainingRetryDelay(response)
+	require.True(test, ok)
+	require.Equal(test, 3*time.Second, delay)
+}
@@ -0,0 +1,225 @@
+package retryable
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync/atomic"
+)
+
+// ErrTooManyRedirects defines an error for requests that exceeded the maximum
+// number of redirects, or that were redirected in a loop.
+var ErrTooManyRedirects = errors.New("too many redirects")
+
+// ErrUnsafeRedirect defines an error for redirects that were rejected by the
+// redirect policy, such as a downgrade from HTTPS to HTTP.
+var ErrUnsafeRedirect = errors.New("unsafe redirect")
+
+// ErrMalformedRedirect defines an error for redirects with a missing or
+// invalid Location header.
+var ErrMalformedRedirect = errors.New("malformed redirect")
+
+// RedirectKind identifies a redirect policy decision.
+type RedirectKind string
+
+// Redirect policy decisions reported to [Client.OnRedirect].
+const (
+	RedirectDowngradeAllowed RedirectKind = "downgrade_allowed"
+	RedirectDowngradeDenied  RedirectKind = "downgrade_denied"
+	RedirectPortDenied       RedirectKind = "port_denied"
+	RedirectAuthStripped     RedirectKind = "auth_stripped"
+)
+
+// RedirectEvent describes a redirect policy decision.
+type RedirectEvent struct {
+	// Kind specifies the policy decision.
+	Kind RedirectKind
+
+	// Request specifies the redirect request.
+	Request *http.Request
+
+	// Via specifies the requests made so far, oldest first.
+	Via []*http.Request
+}
+
+// credentialHeaders contains the headers that are removed from cross-origin
+// redirects.
+var credentialHeaders = []string{"Authorization", "Proxy-Authorization", "Cookie"}
+
+// defaultRedirectCount is the maximum number of redirects per attempt used by
+// [net/http.Client] when no redirect policy is specified.
+const defaultRedirectCount = 10
+
+// redirectCounterKey is the context key for the redirect counter.
+type redirectCounterKey struct{}
+
+// withRedirectCounter returns a copy of the context with a new redirect
+// counter, which is shared across all attempts of a request.
+func withRedirectCounter(ctx context.Context) context.Context {
+	return context.WithValue(ctx, redirectCounterKey{}, new(atomic.Int64))
+}
+
+// checkRedirect applies the redirect policy of the client, limiting the total
+// number of redirects across all attempts, rejecting redirect loops, unsafe
+// downgrades, and port changes, and removing cross-origin credentials, before
+// deferring to the redirect policy of the base HTTP client.
+func (client *Client) checkRedirect(request *http.Request, via []*http.Request) (err error) {
+	// Check for maximum redirects across all attempts
+	counter, ok := request.Context().Value(redirectCounterKey{}).(*atomic.Int64)
+	if ok {
+		count := counter.Add(1)
+		if client.RedirectCount > 0 && count > int64(client.RedirectCount) {
+			return fmt.Errorf("%w: stopped after %d redirects", ErrTooManyRedirects, client.RedirectCount)
+		}
+	}
+
+	// Check for redirect loop
+	if client.RejectRedirectLoops {
+		for _, previous := range via {
+			if previous.URL.String() == request.URL.String() {
+				return fmt.Errorf("%w: redirect loop (%s)", ErrTooManyRedirects, request.URL.Redacted())
+			}
+		}
+	}
+
+	// Check for unsafe redirect
+	if len(via) > 0 {
+		err = client.checkRedirectTarget(request, via)
+		if err != nil {
+			return err
+		}
+	}
+
+	// Apply redirect policy of the base HTTP client
+	if client.CheckRedirect != nil {
+		return client.CheckRedirect(request, via)
+	}
+	if len(via) >= defaultRedirectCount {
+		return fmt.Errorf("%w: stopped after %d redirects", ErrTooManyRedirects, defaultRedirectCount)
+	}
+	return nil
+}
+
+// checkRedirectTarget rejects downgrades from HTTPS to HTTP and port changes
+// according to the redirect policy, and removes credentials from cross-origin
+// redirects.
+func (client *Client) checkRedirectTarget(request *http.Request, via []*http.Request) (err error) {
+	previous := via[len(via)-1]
+
+	// Check for downgrade from HTTPS to HTTP
+	if strings.EqualFold(previous.URL.Scheme, "https") && strings.EqualFold(request.URL.Scheme, "http") {
+		if !client.allowDowngrade(request.URL.Hostname()) {
+			client.notifyRedirect(RedirectDowngradeDenied, request, via)
+			return fmt.Errorf("%w: downgrade from https to http (%s)", ErrUnsafeRedirect, request.URL.Redacted())
+		}
+		client.notifyRedirect(RedirectDowngradeAllowed, request, via)
+	}
+
+	// Check for redirect to a different port
+	if client.RejectPortRedirects {
+		before, after := effectivePort(previous.URL), effectivePort(request.URL)
+		if before != after && !(isDefaultPort(previous.URL) && isDefaultPort(request.URL)) {
+			client.notifyRedirect(RedirectPortDenied, request, via)
+			return fmt.Errorf("%w: redirect from port %s to port %s (%s)", ErrUnsafeRedirect, before, after, request.URL.Redacted())
+		}
+	}
+
+	// Remove credentials from cross-origin redirects
+	if client.StripCrossOriginAuth && origin(via[0].URL) != origin(request.URL) {
+		stripped := false
+		for _, header := range credentialHeaders {
+			if request.Header.Get(header) != "" {
+				request.Header.Del(header)
+				stripped = true
+			}
+		}
+		if stripped {
+			client.notifyRedirect(RedirectAuthStripped, request, via)
+		}
+	}
+	return nil
+}
+
+// isRedirectStatus reports whether the status code is a redirect that
+// requires a Location header.
+func isRedirectStatus(status int) bool {
+	switch status {
+	case http.StatusMovedPermanently, http.StatusFound, http.StatusSeeOther,
+		http.StatusTemporaryRedirect, http.StatusPermanentRedirect:
+		return true
+	}
+	return false
+}
+
+// isInvalidLocation reports whether the error was returned by the base HTTP
+// client for a redirect with an invalid Location header. The error is not
+// exported by the standard library, so the message of the error is inspected
+// instead.
+func isInvalidLocation(err error) bool {
+	return err != nil && strings.Contains(err.Error(), "failed to parse Location header")
+}
+
+// malformedRedirect returns an error for a redirect with a missing or invalid
+// Location header, which is retryable if the client retries malformed
+// redirects.
+func (client *Client) malformedRedirect(err error) error {
+	if client.RetryMalformedRedirects {
+		return fmt.Errorf("%w: %w: %w", ErrRetryable, ErrMalformedRedirect, err)
+	}
+	return fmt.Errorf("%w: %w: %w", ErrNonRetryable, ErrMalformedRedirect, err)
+}
+
+// allowDowngrade returns true if the host is allowed to redirect from HTTPS to
+// HTTP.
+func (client *Client) allowDowngrade(host string) bool {
+	for _, allowed := range client.AllowDowngradeHosts {
+		if strings.EqualFold(allowed, host) {
+			return true
+		}
+	}
+	return false
+}
+
+// notifyRedirect calls the redirect hook, if specified.
+func (client *Client) notifyRedirect(kind RedirectKind, request *http.Request, via []*http.Request) {
+	if client.OnRedirect != nil {
+		client.OnRedirect(RedirectEvent{Kind: kind, Request: request, Via: via})
+	}
+}
+
+// origin returns the scheme, host, and port of the URL in canonical form.
+func origin(address *url.URL) string {
+	scheme := strings.ToLower(address.Scheme)
+	return scheme + "://" + net.JoinHostPort(strings.ToLower(address.Hostname()), effectivePort(address))
+}
+
+// effectivePort returns the port of the URL, or the default port of the
+// scheme if no port is specified.
+func effectivePort(address *url.URL) string {
+	port := address.Port()
+	if port != "" {
+		return port
+	}
+	switch strings.ToLower(address.Scheme) {
+	case "http":
+		return "80"
+	case "https":
+		return "443"
+	}
+	return ""
+}
+
+// isDefaultPort returns true if the URL uses the default port of its scheme.
+func isDefaultPort(address *url.URL) bool {
+	switch strings.ToLower(address.Scheme) {
+	case "http":
+		return effectivePort(address) == "80"
+	case "https":
+		return effectivePort(address) == "443"
+	}
+	return false
+}
@@ -0,0 +1,194 @@
+package retryable
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestClient_CheckRedirect(test *testing.T) {
+	test.Parallel()
+
+	client := new(Client)
+	request, err := http.NewRequest(http.MethodGet, "https://www.github.com/", nil)
+	require.NoError(test, err)
+	err = client.checkRedirect(request, nil)
+	require.NoError(test, err)
+
+	via := make([]*http.Request, defaultRedirectCount)
+	for index := range via {
+		via[index] = request
+	}
+	err = client.checkRedirect(request, via)
+	require.ErrorIs(test, err, ErrTooManyRedirects)
+
+	client.RejectRedirectLoops = true
+	err = client.checkRedirect(request, via[:1])
+	require.ErrorIs(test, err, ErrTooManyRedirects)
+
+	client.RejectRedirectLoops = false
+	client.CheckRedirect = func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse }
+	err = client.checkRedirect(request, via)
+	require.ErrorIs(test, err, http.ErrUseLastResponse)
+
+	client.RedirectCount = 1
+	request = request.WithContext(withRedirectCounter(context.Background()))
+	err = client.checkRedirect(request, nil)
+	require.ErrorIs(test, err, http.ErrUseLastResponse)
+	err = client.checkRedirect(request, nil)
+	require.ErrorIs(test, err, ErrTooManyRedirects)
+}
+
+func TestClient_RedirectCount(test *testing.T) {
+	test.Parallel()
+
+	var attempts atomic.Int32
+	mux := http.NewServeMux()
+	mux.HandleFunc("/a", func(writer http.ResponseWriter, request *http.Request) {
+		attempts.Add(1)
+		http.Redirect(writer, request, "/b", http.StatusFound)
+	})
+	mux.HandleFunc("/b", func(writer http.ResponseWriter, _ *http.Request) {
+		writer.WriteHeader(http.StatusServiceUnavailable)
+	})
+	mux.HandleFunc("/loop", func(writer http.ResponseWriter, request *http.Request) {
+		http.Redirect(writer, request, "/loop", http.StatusFound)
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	client := new(Client)
+	client.RetryCount = 5
+	client.RetryStatus = []int{http.StatusServiceUnavailable}
+	client.RedirectCount = 2
+	response, err := client.Get(server.URL + "/a")
+	require.ErrorIs(test, err, ErrNonRetryable)
+	require.ErrorIs(test, err, ErrTooManyRedirects)
+	require.NotNil(test, response)
+	require.Equal(test, int32(3), attempts.Load())
+
+	client.RedirectCount = 0
+	response, err = client.Get(server.URL + "/loop")
+	require.ErrorIs(test, err, ErrNonRetryable)
+	require.ErrorIs(test, err, ErrTooManyRedirects)
+	require.NotNil(test, response)
+}
+
+func TestClient_CheckRedirectTarget(test *testing.T) {
+	test.Parallel()
+
+	newRequest := func(url string) *http.Request {
+		request, err := http.NewRequest(http.MethodGet, url, nil)
+		require.NoError(test, err)
+		return request
+	}
+
+	var events []RedirectKind
+	client := new(Client)
+	client.OnRedirect = func(event RedirectEvent) {
+		events = append(events, event.Kind)
+	}
+	secure := newRequest("https://www.github.com/")
+	err := client.checkRedirect(newRequest("http://www.github.com/"), []*http.Request{secure})
+	require.ErrorIs(test, err, ErrUnsafeRedirect)
+
+	client.AllowDowngradeHosts = []string{"WWW.GITHUB.COM"}
+	err = client.checkRedirect(newRequest("http://www.github.com/"), []*http.Request{secure})
+	require.NoError(test, err)
+
+	err = client.checkRedirect(newRequest("https://www.github.com:8443/"), []*http.Request{secure})
+	require.NoError(test, err)
+
+	client.RejectPortRedirects = true
+	err = client.checkRedirect(newRequest("https://www.github.com:8443/"), []*http.Request{secure})
+	require.ErrorIs(test, err, ErrUnsafeRedirect)
+
+	err = client.checkRedirect(newRequest("https://www.github.com/login"), []*http.Request{newRequest("http://www.github.com/")})
+	require.NoError(test, err)
+
+	request := newRequest("https://api.github.com/")
+	request.Header.Set("Authorization", "Bearer token")
+	err = client.checkRedirect(request, []*http.Request{secure})
+	require.NoError(test, err)
+	require.Equal(test, "Bearer token", request.Header.Get("Authorization"))
+
+	client.StripCrossOriginAuth = true
+	err = client.checkRedirect(request, []*http.Request{secure})
+	require.NoError(test, err)
+	require.Empty(test, request.Header.Get("Authorization"))
+
+	require.Equal(test, []RedirectKind{
+		RedirectDowngradeDenied,
+		RedirectDowngradeAllowed,
+		RedirectPortDenied,
+		RedirectAuthStripped,
+	}, events)
+}
+
+func TestClient_RedirectPolicy(test *testing.T) {
+	test.Parallel()
+
+	var authorization atomic.Value
+	target := httptest.NewServer(http.HandlerFunc(func(_ http.ResponseWriter, request *http.Request) {
+		authorization.Store(request.Header.Get("Authorization"))
+	}))
+	defer target.Close()
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		http.Redirect(writer, request, target.URL, http.StatusFound)
+	}))
+	defer server.Close()
+
+	client := new(Client)
+	client.RejectPortRedirects = true
+	response, err := client.Get(server.URL)
+	require.ErrorIs(test, err, ErrNonRetryable)
+	require.ErrorIs(test, err, ErrUnsafeRedirect)
+	require.NotNil(test, response)
+
+	client.RejectPortRedirects = false
+	client.StripCrossOriginAuth = true
+	request, err := http.NewRequest(http.MethodGet, server.URL, nil)
+	require.NoError(test, err)
+	request.Header.Set("Authorization", "Bearer token")
+	_, err = client.Do(request)
+	require.NoError(test, err)
+	require.Equal(test, "", authorization.Load())
+}
+
+func TestClient_RetryMalformedRedirects(test *testing.T) {
+	test.Parallel()
+
+	var attempts atomic.Int64
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		attempts.Add(1)
+		if request.URL.Path == "/invalid" {
+			writer.Header().Set("Location", "http://[invalid")
+		}
+		writer.WriteHeader(http.StatusFound)
+	}))
+	defer server.Close()
+
+	client := new(Client)
+	client.RetryCount = 2
+	response, err := client.Get(server.URL + "/missing")
+	require.NoError(test, err)
+	require.Equal(test, http.StatusFound, response.StatusCode)
+	_, err = client.Get(server.URL + "/invalid")
+	require.ErrorIs(test, err, ErrNonRetryable)
+	require.ErrorIs(test, err, ErrMalformedRedirect)
+	require.Equal(test, int64(2), attempts.Load())
+
+	client.RetryMalformedRedirects = true
+	for _, path := range []string{"/missing", "/invalid"} {
+		attempts.Store(0)
+		_, err = client.Get(server.URL + path)
+		require.ErrorIs(test, err, ErrRetryable)
+		require.ErrorIs(test, err, ErrMalformedRedirect)
+		require.Equal(test, int64(3), attempts.Load())
+	}
+	require.Equal(test, ErrorKindRedirect, classifyError(nil, err))
+}
@@ -0,0 +1,151 @@
+package retryable
+
+import (
+	"math"
+	"net/http"
+	"time"
+
+	"github.com/cholland1989/go-delay/pkg/delay"
+)
+
+// Schedule returns the retry delay applied after each of the specified number
+// of attempts, without random jitter, fixed request delays, or retry headers.
+// The number of attempts is limited by the retry count.
+func (client *Client) Schedule(attempts int) (schedule []time.Duration) {
+	// Take a snapshot of the configuration
+	client = client.snapshot()
+
+	// Limit attempts to retry count
+	if attempts > client.RetryCount {
+		attempts = client.RetryCount
+	}
+	if attempts <= 0 {
+		return nil
+	}
+
+	// Calculate exponential backoff for each attempt
+	schedule = make([]time.Duration, attempts)
+	for attempt := range schedule {
+		schedule[attempt] = client.retryDelay(attempt)
+	}
+	return schedule
+}
+
+// retryDelay calculates the exponential backoff for the specified attempt,
+// without random jitter.
+func (client *Client) retryDelay(attempt int) time.Duration {
+	// Ensure the retry multiplier is valid when unset
+	multiplier := math.Max(client.RetryMultiplier, 1.0)
+	return client.limitRetryDelay(delay.ExponentialBackoff(client.baseDelay(), multiplier, attempt))
+}
+
+// limitRetryDelay limits the retry delay to the maximum retry delay.
+func (client *Client) limitRetryDelay(duration time.Duration) time.Duration {
+	if client.MaxRetryDelay > 0 && duration > client.MaxRetryDelay {
+		return client.MaxRetryDelay
+	}
+	return duration
+}
+
+// PlannedAttempt describes an attempt that a client would send for a request
+// that keeps failing with retryable errors, as planned by
+// [Client.PlanRetries].
+type PlannedAttempt struct {
+	// Number specifies the attempt number, starting from zero.
+	Number int
+
+	// Delay specifies the retry delay before the attempt without random
+	// jitter, or zero for the first attempt.
+	Delay time.Duration
+
+	// MinDelay and MaxDelay specify the range of the retry delay before the
+	// attempt with random jitter.
+	MinDelay, MaxDelay time.Duration
+
+	// MinElapsed and MaxElapsed specify the range of the time spent sleeping
+	// before the attempt is sent, including request delays and retry delays
+	// but excluding the time spent sending previous attempts.
+	MinElapsed, MaxElapsed time.Duration
+}
+
+// RetryPlan describes the attempts and deadlines that a client would use for
+// a request that keeps failing with retryable errors, without sending it.
+type RetryPlan struct {
+	// Attempts specifies the planned attempts, starting with the first.
+	Attempts []PlannedAttempt
+
+	// RetryTimeout specifies the deadline of all attempts relative to the
+	// start of the request, or zero if there is no deadline.
+	RetryTimeout time.Duration
+
+	// RequestTimeout specifies the deadline of each attempt relative to its
+	// start, or zero if there is no deadline.
+	RequestTimeout time.Duration
+}
+
+// PlanRetries returns the attempts and deadlines that the client would use
+// for the request if every attempt failed with a retryable error, without
+// sending the request, such as to review the retry configuration. Attempts
+// that cannot start before the retry timeout or that exceed the maximum total
+// delay are omitted. Server-specified retry delays, free retries, and the
+// time spent sending attempts are not taken into account. A request that
+// would be rejected before sending has no attempts.
+func (client *Client) PlanRetries(request *http.Request) (plan RetryPlan) {
+	// Take a snapshot of the configuration
+	client = client.snapshot()
+	plan.RetryTimeout = client.RetryTimeout
+	plan.RequestTimeout = client.RequestTimeout
+
+	// Check for request that would be rejected
+	if client.validateRequest(request) != nil {
+		return plan
+	}
+
+	// Plan each attempt
+	var minElapsed, maxElapsed, totalDelay time.Duration
+	for attempt := 0; attempt <= client.RetryCount; attempt++ {
+		planned := PlannedAttempt{Number: attempt}
+
+		// Add retry delay
+		if attempt > 0 {
+			planned.Delay = client.retryDelay(attempt - 1)
+			planned.MinDelay, planned.MaxDelay = client.jitterRange(planned.Delay)
+			totalDelay += planned.Delay
+			if client.MaxTotalDelay > 0 && totalDelay > client.MaxTotalDelay {
+				break
+			}
+		}
+
+		// Add request delay
+		minRequest, maxRequest := time.Duration(0), time.Duration(0)
+		if attempt > 0 || !client.SkipInitialDelay {
+			minRequest = time.Duration(float64(client.RequestDelay) * (1.0 - client.RequestJitter))
+			maxRequest = time.Duration(float64(client.RequestDelay) * (1.0 + client.RequestJitter))
+		}
+		minElapsed += planned.MinDelay + minRequest
+		maxElapsed += planned.MaxDelay + maxRequest
+		planned.MinElapsed, planned.MaxElapsed = minElapsed, maxElapsed
+
+		// Check that the attempt can start before the retry timeout
+		if client.RetryTimeout > 0 && minElapsed >= client.RetryTimeout {
+			break
+		}
+		plan.Attempts = append(plan.Attempts, planned)
+	}
+	return plan
+}
+
+// jitterRange returns the range of the retry delay with random jitter
+// applied according to the jitter mode.
+func (client *Client) jitterRange(duration time.Duration) (minimum time.Duration, maximum time.Duration) {
+	switch client.JitterMode {
+	case FullJitter:
+		return 0, duration
+	case EqualJitter:
+		return duration - duration/2, duration
+	default:
+		minimum = time.Duration(float64(duration) * (1.0 - client.RetryJitter))
+		maximum = time.Duration(float64(duration) * (1.0 + client.RetryJitter))
+		return client.limitRetryDelay(minimum), client.limitRetryDelay(maximum)
+	}
+}
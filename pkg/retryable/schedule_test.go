@@ -0,0 +1,86 @@
+package retryable
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestClient_Schedule(test *testing.T) {
+	test.Parallel()
+
+	client := new(Client)
+	require.Empty(test, client.Schedule(3))
+
+	client.RetryCount = 3
+	client.RetryDelay = time.Second
+	require.Empty(test, client.Schedule(0))
+	require.Equal(test, []time.Duration{time.Second, time.Second, time.Second}, client.Schedule(3))
+
+	client.RetryMultiplier = 2.0
+	client.RetryJitter = 0.5
+	schedule := []time.Duration{2 * time.Second, 4 * time.Second, 8 * time.Second}
+	require.Equal(test, schedule, client.Schedule(3))
+	require.Equal(test, schedule, client.Schedule(10))
+}
+
+func TestClient_LimitRetryDelay(test *testing.T) {
+	test.Parallel()
+
+	client := new(Client)
+	require.Equal(test, time.Hour, client.limitRetryDelay(time.Hour))
+
+	client.MaxRetryDelay = time.Minute
+	require.Equal(test, time.Minute, client.limitRetryDelay(time.Hour))
+	require.Equal(test, time.Second, client.limitRetryDelay(time.Second))
+
+	client.RetryCount = 3
+	client.RetryDelay = 20 * time.Second
+	client.RetryMultiplier = 2.0
+	require.Equal(test, []time.Duration{40 * time.Second, time.Minute, time.Minute}, client.Schedule(3))
+}
+
+func TestClient_PlanRetries(test *testing.T) {
+	test.Parallel()
+
+	client := new(Client)
+	client.RetryCount = 3
+	client.RetryDelay = time.Second
+	client.RetryMultiplier = 2.0
+	client.RetryJitter = 0.5
+	client.RequestDelay = 10 * time.Millisecond
+	client.SkipInitialDelay = true
+	client.RequestTimeout = time.Minute
+	request, err := http.NewRequest(http.MethodGet, "http://example.invalid/", nil)
+	require.NoError(test, err)
+
+	plan := client.PlanRetries(request)
+	require.Equal(test, time.Minute, plan.RequestTimeout)
+	require.Zero(test, plan.RetryTimeout)
+	require.Equal(test, []PlannedAttempt{
+		{Number: 0},
+		{Number: 1, Delay: 2 * time.Second, MinDelay: time.Second, MaxDelay: 3 * time.Second, MinElapsed: time.Second + 10*time.Millisecond, MaxElapsed: 3*time.Second + 10*time.Millisecond},
+		{Number: 2, Delay: 4 * time.Second, MinDelay: 2 * time.Second, MaxDelay: 6 * time.Second, MinElapsed: 3*time.Second + 20*time.Millisecond, MaxElapsed: 9*time.Second + 20*time.Millisecond},
+		{Number: 3, Delay: 8 * time.Second, MinDelay: 4 * time.Second, MaxDelay: 12 * time.Second, MinElapsed: 7*time.Second + 30*time.Millisecond, MaxElapsed: 21*time.Second + 30*time.Millisecond},
+	}, plan.Attempts)
+
+	client.RetryTimeout = 5 * time.Second
+	require.Len(test, client.PlanRetries(request).Attempts, 3)
+	client.RetryTimeout = 0
+	client.MaxTotalDelay = 6 * time.Second
+	require.Len(test, client.PlanRetries(request).Attempts, 3)
+	client.MaxTotalDelay = 0
+
+	client.JitterMode = FullJitter
+	plan = client.PlanRetries(request)
+	require.Zero(test, plan.Attempts[1].MinDelay)
+	require.Equal(test, 2*time.Second, plan.Attempts[1].MaxDelay)
+	client.JitterMode = EqualJitter
+	plan = client.PlanRetries(request)
+	require.Equal(test, time.Second, plan.Attempts[1].MinDelay)
+
+	client.DeniedMethods = []string{http.MethodGet}
+	require.Empty(test, client.PlanRetries(request).Attempts)
+}
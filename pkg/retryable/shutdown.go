@@ -0,0 +1,123 @@
+package retryable
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ErrClientClosed defines an error for requests sent after the client was
+// shut down.
+var ErrClientClosed = errors.New("client is closed")
+
+// Shutdown gracefully shuts down the client. It switches the client into
+// drain mode, so that no new retries are scheduled and pending retry delays
+// are interrupted, and causes subsequent requests to fail with
+// [ErrClientClosed]. Other pending delays, such as request delays, host
+// pacing, and poll intervals, are interrupted with [ErrClientClosed]. It then
+// waits for in-flight requests to finish, including reading streamed response
+// bodies. If the context expires first, the in-flight requests are canceled
+// and the context error is returned. Shutdown cannot be reversed.
+func (client *Client) Shutdown(ctx context.Context) (err error) {
+	// Reject new requests
+	state := client.state()
+	state.mutex.Lock()
+	state.closed = true
+	if state.idle == nil {
+		state.idle = make(chan struct{})
+		if len(state.requests) == 0 {
+			close(state.idle)
+		}
+	}
+	idle := state.idle
+	state.mutex.Unlock()
+
+	// Interrupt pending delays and stop scheduling retries
+	state.closeOnce.Do(func() {
+		close(state.closedChannel())
+	})
+	client.Drain()
+
+	// Wait for in-flight requests, canceling them when the context expires
+	select {
+	case <-idle:
+		return nil
+	case <-ctx.Done():
+		state.mutex.Lock()
+		for _, cancel := range state.requests {
+			cancel()
+		}
+		state.mutex.Unlock()
+		return ctx.Err()
+	}
+}
+
+// Closed returns true if the client was shut down.
+func (client *Client) Closed() bool {
+	state := client.state()
+	state.mutex.Lock()
+	defer state.mutex.Unlock()
+	return state.closed
+}
+
+// closedChannel returns a channel that is closed when the client is shut
+// down, initializing it if required.
+func (state *clientState) closedChannel() chan struct{} {
+	state.mutex.Lock()
+	defer state.mutex.Unlock()
+	if state.closing == nil {
+		state.closing = make(chan struct{})
+	}
+	return state.closing
+}
+
+// sleepUnlessClosed pauses the current goroutine for the specified duration,
+// or until the context is canceled or the client is shut down.
+func (client *Client) sleepUnlessClosed(ctx context.Context, duration time.Duration) (err error) {
+	interrupted, err := client.interruptibleSleep(ctx, duration, client.state().closedChannel())
+	if interrupted {
+		return ErrClientClosed
+	}
+	return err
+}
+
+// trackRequest returns a copy of the context that is canceled if the client
+// is shut down before the request finishes, and a function that stops
+// tracking the request and cancels the context. It returns an error if the
+// client was already shut down.
+func (client *Client) trackRequest(ctx context.Context) (tracked context.Context, release context.CancelFunc, err error) {
+	// Check for shutdown
+	state := client.state()
+	state.mutex.Lock()
+	defer state.mutex.Unlock()
+	if state.closed {
+		return nil, nil, fmt.Errorf("%w: %w", ErrNonRetryable, ErrClientClosed)
+	}
+
+	// Register request
+	tracked, cancel := context.WithCancel(ctx)
+	if state.requests == nil {
+		state.requests = make(map[uint64]context.CancelFunc)
+	}
+	id := state.nextRequest
+	state.nextRequest++
+	state.requests[id] = cancel
+	state.stats.requests.Add(1)
+
+	// Unregister request, notifying shutdown when the last request finishes
+	var once sync.Once
+	release = func() {
+		once.Do(func() {
+			cancel()
+			state.mutex.Lock()
+			defer state.mutex.Unlock()
+			delete(state.requests, id)
+			if state.idle != nil && len(state.requests) == 0 {
+				close(state.idle)
+			}
+		})
+	}
+	return tracked, release, nil
+}
@@ -0,0 +1,102 @@
+package retryable
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestClient_Shutdown(test *testing.T) {
+	test.Parallel()
+
+	started := make(chan struct{})
+	finish := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		close(started)
+		<-finish
+	}))
+	defer server.Close()
+
+	client := new(Client)
+	require.False(test, client.Closed())
+
+	// Wait for in-flight requests
+	done := make(chan error, 1)
+	go func() {
+		response, err := client.Get(server.URL)
+		if err == nil {
+			err = response.Body.Close()
+		}
+		done <- err
+	}()
+	<-started
+	shutdown := make(chan error, 1)
+	go func() {
+		shutdown <- client.Shutdown(context.Background())
+	}()
+	select {
+	case <-shutdown:
+		require.Fail(test, "shutdown returned before the in-flight request finished")
+	case <-time.After(10 * time.Millisecond):
+	}
+	close(finish)
+	require.NoError(test, <-done)
+	require.NoError(test, <-shutdown)
+	require.True(test, client.Closed())
+	require.True(test, client.Draining())
+
+	// Reject subsequent requests
+	_, err := client.Get(server.URL)
+	require.ErrorIs(test, err, ErrNonRetryable)
+	require.ErrorIs(test, err, ErrClientClosed)
+	require.NoError(test, client.Shutdown(context.Background()))
+}
+
+func TestClient_ShutdownDeadline(test *testing.T) {
+	test.Parallel()
+
+	started := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		close(started)
+		<-request.Context().Done()
+	}))
+	defer server.Close()
+
+	client := new(Client)
+	done := make(chan error, 1)
+	go func() {
+		_, err := client.Get(server.URL)
+		done <- err
+	}()
+	<-started
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	require.ErrorIs(test, client.Shutdown(ctx), context.DeadlineExceeded)
+	require.ErrorIs(test, <-done, context.Canceled)
+}
+
+func TestClient_ShutdownDelay(test *testing.T) {
+	test.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {}))
+	defer server.Close()
+
+	client := new(Client)
+	client.RequestDelay = time.Hour
+	done := make(chan error, 1)
+	go func() {
+		_, err := client.Get(server.URL)
+		done <- err
+	}()
+	time.Sleep(10 * time.Millisecond)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	require.NoError(test, client.Shutdown(ctx))
+	err := <-done
+	require.ErrorIs(test, err, ErrNonRetryable)
+	require.ErrorIs(test, err, ErrClientClosed)
+}
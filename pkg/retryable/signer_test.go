@@ -0,0 +1,80 @@
+package retryable
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestHMACSigner(test *testing.T) {
+	test.Parallel()
+
+	clock := &MockClock{now: time.Unix(1700000000, 0)}
+	signer := &HMACSigner{Secret: []byte("secret"), Clock: clock}
+	request, err := http.NewRequest(http.MethodPost, "https://api.example.com/v1/items?page=2", strings.NewReader("xyz"))
+	require.NoError(test, err)
+	require.NoError(test, signer.Sign(request))
+
+	body := sha256.Sum256([]byte("xyz"))
+	mac := hmac.New(sha256.New, []byte("secret"))
+	_, _ = fmt.Fprintf(mac, "1700000000\nPOST\n/v1/items?page=2\n%x", body)
+	require.Equal(test, "1700000000", request.Header.Get("X-Timestamp"))
+	require.Equal(test, hex.EncodeToString(mac.Sum(nil)), request.Header.Get("X-Signature"))
+
+	signer.Header = "Signature"
+	signer.TimestampHeader = "Signature-Time"
+	require.NoError(test, signer.Sign(request))
+	require.Equal(test, request.Header.Get("X-Signature"), request.Header.Get("Signature"))
+
+	request.GetBody = nil
+	require.Error(test, signer.Sign(request))
+}
+
+func TestClient_Signer(test *testing.T) {
+	test.Parallel()
+
+	clock := &MockClock{now: time.Unix(1700000000, 0)}
+	var timestamps []string
+	client := new(Client)
+	client.Clock = clock
+	client.RetryCount = 2
+	client.RetryDelay = time.Minute
+	client.Signer = &HMACSigner{Secret: []byte("secret"), Clock: clock}
+	client.Transport = MockTransport(func(request *http.Request) (*http.Response, error) {
+		timestamps = append(timestamps, request.Header.Get("X-Timestamp"))
+		return nil, errors.New("connection refused")
+	})
+	_, err := client.Post("http://localhost/", "text/plain", strings.NewReader("xyz"))
+	require.ErrorIs(test, err, ErrRetryable)
+	require.Equal(test, []string{"1700000000", "1700000060", "1700000120"}, timestamps)
+
+	client.Signer = SignerFunc(func(request *http.Request) error {
+		return errors.New("no key")
+	})
+	_, err = client.Get("http://localhost/")
+	require.ErrorIs(test, err, ErrNonRetryable)
+	require.ErrorContains(test, err, "unable to sign request: no key")
+
+	client.Signer = SignerFunc(func(request *http.Request) error {
+		return fmt.Errorf("%w: key rotating", ErrRetryable)
+	})
+	_, err = client.Get("http://localhost/")
+	require.ErrorIs(test, err, ErrRetryable)
+
+	timestamps = nil
+	client.Signer = nil
+	request, err := http.NewRequestWithContext(WithSigner(context.Background(), &HMACSigner{Secret: []byte("secret"), Clock: clock}), http.MethodGet, "http://localhost/", nil)
+	require.NoError(test, err)
+	_, err = client.Do(request)
+	require.ErrorIs(test, err, ErrRetryable)
+	require.Equal(test, []string{"1700000240", "1700000300", "1700000360"}, timestamps)
+}
@@ -0,0 +1,165 @@
+package retryable
+
+import (
+	"net/http"
+	"sort"
+	"time"
+)
+
+// maxSizeKeys is the maximum number of hosts and operations for which
+// response sizes are recorded by a client.
+const maxSizeKeys = 256
+
+// SizeBuckets contains the upper bounds in bytes of the buckets of a
+// [SizeHistogram]. Larger responses are counted in a final overflow bucket.
+var SizeBuckets = []int64{
+	1 << 10, 4 << 10, 16 << 10, 64 << 10, 256 << 10,
+	1 << 20, 4 << 20, 16 << 20, 64 << 20, 256 << 20,
+	1 << 30,
+}
+
+// SizeHistogram describes the distribution of response sizes.
+type SizeHistogram struct {
+	// Counts contains the number of responses per bucket of [SizeBuckets],
+	// followed by the number of larger responses.
+	Counts []int64
+
+	// Count specifies the total number of responses.
+	Count int64
+
+	// Total specifies the total size of all responses in bytes.
+	Total int64
+
+	// Max specifies the size of the largest response in bytes.
+	Max int64
+}
+
+// LargestResponse describes one of the largest responses received.
+type LargestResponse struct {
+	// URL specifies the URL of the request, without query parameters.
+	URL string
+
+	// Size specifies the size of the response in bytes.
+	Size int64
+
+	// Time specifies when the response was received.
+	Time time.Time
+}
+
+// ResponseSizeStats contains the response sizes recorded by a client.
+type ResponseSizeStats struct {
+	// Hosts contains the response size distribution per host.
+	Hosts map[string]SizeHistogram
+
+	// Operations contains the response size distribution per operation.
+	Operations map[string]SizeHistogram
+
+	// Largest contains the largest responses, largest first.
+	Largest []LargestResponse
+}
+
+// sizeState contains the response sizes recorded by a client.
+type sizeState struct {
+	// hosts contains the histogram per host.
+	hosts map[string]*SizeHistogram
+
+	// operations contains the histogram per operation.
+	operations map[string]*SizeHistogram
+
+	// largest contains the largest responses, largest first.
+	largest []LargestResponse
+}
+
+// ResponseSizes returns a copy of the response sizes recorded by the client,
+// so that operators can choose the response size limit from real data.
+// Response sizes are only recorded if [Client.TrackResponseSizes] is
+// positive.
+func (client *Client) ResponseSizes() (stats ResponseSizeStats) {
+	state := client.state()
+	state.mutex.Lock()
+	defer state.mutex.Unlock()
+	stats.Hosts = copyHistograms(state.sizes.hosts)
+	stats.Operations = copyHistograms(state.sizes.operations)
+	stats.Largest = append([]LargestResponse(nil), state.sizes.largest...)
+	return stats
+}
+
+// recordResponseSize records the size of the response.
+func (client *Client) recordResponseSize(response *http.Response, size int64) {
+	// Check for size tracking
+	if client.TrackResponseSizes <= 0 || response.Request == nil || size < 0 {
+		return
+	}
+	request := response.Request
+	operation, _ := request.Context().Value(operationKey{}).(string)
+	address := *request.URL
+	address.User, address.RawQuery, address.ForceQuery, address.Fragment = nil, "", false, ""
+	largest := LargestResponse{URL: address.String(), Size: size, Time: client.clock().Now()}
+
+	// Record histograms
+	state := client.state()
+	state.mutex.Lock()
+	defer state.mutex.Unlock()
+	sizes := &state.sizes
+	sizes.hosts = recordHistogram(sizes.hosts, normalizeHost(request.URL.Scheme, request.URL.Host), size)
+	if operation != "" {
+		sizes.operations = recordHistogram(sizes.operations, operation, size)
+	}
+
+	// Record largest responses
+	index := sort.Search(len(sizes.largest), func(index int) bool {
+		return sizes.largest[index].Size < size
+	})
+	if index >= client.TrackResponseSizes {
+		return
+	}
+	sizes.largest = append(sizes.largest, LargestResponse{})
+	copy(sizes.largest[index+1:], sizes.largest[index:])
+	sizes.largest[index] = largest
+	if len(sizes.largest) > client.TrackResponseSizes {
+		sizes.largest = sizes.largest[:client.TrackResponseSizes]
+	}
+}
+
+// recordHistogram records the size in the histogram for the key, evicting an
+// arbitrary key if required.
+func recordHistogram(histograms map[string]*SizeHistogram, key string, size int64) map[string]*SizeHistogram {
+	// Find or create histogram
+	if histograms == nil {
+		histograms = make(map[string]*SizeHistogram)
+	}
+	histogram, ok := histograms[key]
+	if !ok {
+		if len(histograms) >= maxSizeKeys {
+			for existing := range histograms {
+				delete(histograms, existing)
+				break
+			}
+		}
+		histogram = &SizeHistogram{Counts: make([]int64, len(SizeBuckets)+1)}
+		histograms[key] = histogram
+	}
+
+	// Record size
+	bucket := sort.Search(len(SizeBuckets), func(index int) bool {
+		return size <= SizeBuckets[index]
+	})
+	histogram.Counts[bucket]++
+	histogram.Count++
+	histogram.Total += size
+	if size > histogram.Max {
+		histogram.Max = size
+	}
+	return histograms
+}
+
+// copyHistograms returns a deep copy of the histograms.
+func copyHistograms(histograms map[string]*SizeHistogram) (copied map[string]SizeHistogram) {
+	copied = make(map[string]SizeHistogram, len(histograms))
+	for key, histogram := range histograms {
+		value := *histogram
+		value.Counts = append([]int64(nil), histogram.Counts...)
+		copied[key] = value
+	}
+	return copied
+}
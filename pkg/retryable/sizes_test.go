@@ -0,0 +1,60 @@
+package retryable
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestClient_ResponseSizes(test *testing.T) {
+	test.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		size, _ := strconv.Atoi(request.URL.Query().Get("size"))
+		_, _ = writer.Write([]byte(strings.Repeat("x", size)))
+	}))
+	defer server.Close()
+	address, err := url.Parse(server.URL)
+	require.NoError(test, err)
+
+	client := new(Client)
+	_, err = client.Get(server.URL + "/?size=10")
+	require.NoError(test, err)
+	require.Empty(test, client.ResponseSizes().Hosts)
+
+	client.TrackResponseSizes = 2
+	client.RegisterOperation("download", func(*http.Response, []byte) error { return nil })
+	for _, size := range []int{10, 5000, 100, 2048} {
+		_, err = client.Get(server.URL + "/small?size=" + strconv.Itoa(size))
+		require.NoError(test, err)
+	}
+	request, err := http.NewRequestWithContext(WithOperation(context.Background(), "download"), http.MethodGet, server.URL+"/large?size=70000", nil)
+	require.NoError(test, err)
+	_, err = client.Do(request)
+	require.NoError(test, err)
+
+	stats := client.ResponseSizes()
+	host := stats.Hosts[address.Host]
+	require.Equal(test, int64(5), host.Count)
+	require.Equal(test, int64(77158), host.Total)
+	require.Equal(test, int64(70000), host.Max)
+	require.Equal(test, []int64{2, 1, 1, 0, 1, 0, 0, 0, 0, 0, 0, 0}, host.Counts)
+	require.Equal(test, int64(1), stats.Operations["download"].Count)
+
+	require.Len(test, stats.Largest, 2)
+	require.Equal(test, server.URL+"/large", stats.Largest[0].URL)
+	require.Equal(test, int64(70000), stats.Largest[0].Size)
+	require.Equal(test, server.URL+"/small", stats.Largest[1].URL)
+	require.Equal(test, int64(5000), stats.Largest[1].Size)
+
+	stats.Largest[0].Size = 0
+	stats.Hosts[address.Host].Counts[0] = 0
+	require.Equal(test, int64(70000), client.ResponseSizes().Largest[0].Size)
+	require.Equal(test, int64(2), client.ResponseSizes().Hosts[address.Host].Counts[0])
+}
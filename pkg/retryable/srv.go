@@ -0,0 +1,265 @@
+package retryable
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// SRVResolver defines the lookup of DNS SRV records, as implemented by
+// [net.Resolver].
+type SRVResolver interface {
+	// LookupSRV returns the SRV records of the service.
+	LookupSRV(ctx context.Context, service string, proto string, name string) (cname string, addrs []*net.SRV, err error)
+}
+
+// SRVEndpointSelector is an [EndpointSelector] that resolves endpoints from
+// DNS SRV records. Each attempt selects a record from the lowest priority
+// that has not failed, choosing between records of equal priority at random
+// in proportion to their weight, as described in RFC 2782. Records that fail
+// are skipped until the records are resolved again. If every record has
+// failed, all records are used.
+type SRVEndpointSelector struct {
+	// Service specifies the symbolic name of the service, such as "http".
+	Service string
+
+	// Proto specifies the protocol of the service. If the protocol is empty,
+	// "tcp" is used.
+	Proto string
+
+	// Name specifies the domain name of the service.
+	Name string
+
+	// Scheme specifies the URL scheme of the endpoints. If the scheme is
+	// empty, "https" is used.
+	Scheme string
+
+	// Resolver specifies the SRV resolver. If the resolver is nil,
+	// [net.DefaultResolver] is used.
+	Resolver SRVResolver
+
+	// TTL specifies how long resolved records are used before they are
+	// resolved again, since the standard resolver does not expose the TTL of
+	// records. If the TTL is zero, records are resolved again after thirty
+	// seconds.
+	TTL time.Duration
+
+	// Clock specifies the time source. If the clock is nil, the system time
+	// is used.
+	Clock Clock
+
+	// mutex guards access to the resolved records.
+	mutex sync.Mutex
+
+	// records contains the resolved records.
+	records []*net.SRV
+
+	// resolved specifies when the records were resolved.
+	resolved time.Time
+
+	// failed contains the endpoints of records that failed since the records
+	// were resolved.
+	failed map[string]bool
+
+	// random returns a random number in [0, n), or nil for math/rand.
+	random func(n int) int
+}
+
+// SelectEndpoint resolves the SRV records if they have expired, and returns
+// the endpoint of a record selected by priority and weight.
+func (selector *SRVEndpointSelector) SelectEndpoint(_ int, request *http.Request) (endpoint *url.URL, err error) {
+	// Resolve records if expired
+	ctx := context.Background()
+	if request != nil {
+		ctx = request.Context()
+	}
+	selector.mutex.Lock()
+	defer selector.mutex.Unlock()
+	err = selector.resolve(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// Select from records that have not failed, or all records if all failed
+	candidates := make([]*net.SRV, 0, len(selector.records))
+	for _, record := range selector.records {
+		if !selector.failed[srvHost(record)] {
+			candidates = append(candidates, record)
+		}
+	}
+	if len(candidates) == 0 {
+		candidates = selector.records
+	}
+	record := selector.choose(candidates)
+	return &url.URL{Scheme: selector.scheme(), Host: srvHost(record)}, nil
+}
+
+// ReportEndpoint marks the record of the endpoint as failed until the records
+// are resolved again, or clears its failure on success.
+func (selector *SRVEndpointSelector) ReportEndpoint(endpoint *url.URL, err error) {
+	// Check for endpoint
+	if endpoint == nil {
+		return
+	}
+	selector.mutex.Lock()
+	defer selector.mutex.Unlock()
+
+	// Record outcome
+	if err == nil {
+		delete(selector.failed, endpoint.Host)
+		return
+	}
+	if selector.failed == nil {
+		selector.failed = make(map[string]bool)
+	}
+	selector.failed[endpoint.Host] = true
+}
+
+// Healthy reports whether the record of the endpoint has not failed since
+// the records were resolved.
+func (selector *SRVEndpointSelector) Healthy(endpoint *url.URL) bool {
+	selector.mutex.Lock()
+	defer selector.mutex.Unlock()
+	return !selector.failed[endpoint.Host]
+}
+
+// Records returns a copy of the resolved SRV records.
+func (selector *SRVEndpointSelector) Records() (records []net.SRV) {
+	selector.mutex.Lock()
+	defer selector.mutex.Unlock()
+	for _, record := range selector.records {
+		records = append(records, *record)
+	}
+	return records
+}
+
+// record returns the resolved SRV record of the endpoint, or nil if the
+// endpoint does not match a record.
+func (selector *SRVEndpointSelector) record(endpoint *url.URL) *net.SRV {
+	selector.mutex.Lock()
+	defer selector.mutex.Unlock()
+	for _, record := range selector.records {
+		if srvHost(record) == endpoint.Host {
+			copied := *record
+			return &copied
+		}
+	}
+	return nil
+}
+
+// resolve looks up the SRV records if they have expired, while holding the
+// lock. If the lookup fails, previously resolved records continue to be used.
+func (selector *SRVEndpointSelector) resolve(ctx context.Context) (err error) {
+	// Check for expired records
+	now := selector.now()
+	if len(selector.records) > 0 && now.Sub(selector.resolved) < selector.ttl() {
+		return nil
+	}
+
+	// Look up records
+	_, records, err := selector.resolver().LookupSRV(ctx, selector.Service, selector.proto(), selector.Name)
+	if err == nil && len(records) == 0 {
+		err = errors.New("no SRV records")
+	}
+	if err != nil {
+		if len(selector.records) > 0 {
+			return nil
+		}
+		return err
+	}
+	selector.records = records
+	selector.resolved = now
+	selector.failed = nil
+	return nil
+}
+
+// choose returns a record from the lowest priority of the candidates,
+// selected at random in proportion to its weight.
+func (selector *SRVEndpointSelector) choose(candidates []*net.SRV) *net.SRV {
+	// Collect records of the lowest priority
+	var group []*net.SRV
+	for _, record := range candidates {
+		if len(group) == 0 || record.Priority < group[0].Priority {
+			group = append(group[:0], record)
+		} else if record.Priority == group[0].Priority {
+			group = append(group, record)
+		}
+	}
+
+	// Select by weight, or uniformly if every weight is zero
+	total := 0
+	for _, record := range group {
+		total += int(record.Weight)
+	}
+	if total == 0 {
+		return group[selector.randomInt(len(group))]
+	}
+	pick := selector.randomInt(total)
+	for _, record := range group {
+		pick -= int(record.Weight)
+		if pick < 0 {
+			return record
+		}
+	}
+	return group[len(group)-1]
+}
+
+// randomInt returns a random number in [0, n).
+func (selector *SRVEndpointSelector) randomInt(n int) int {
+	if selector.random == nil {
+		return rand.Intn(n)
+	}
+	return selector.random(n)
+}
+
+// resolver returns the SRV resolver.
+func (selector *SRVEndpointSelector) resolver() SRVResolver {
+	if selector.Resolver == nil {
+		return net.DefaultResolver
+	}
+	return selector.Resolver
+}
+
+// proto returns the protocol of the service.
+func (selector *SRVEndpointSelector) proto() string {
+	if selector.Proto == "" {
+		return "tcp"
+	}
+	return selector.Proto
+}
+
+// scheme returns the URL scheme of the endpoints.
+func (selector *SRVEndpointSelector) scheme() string {
+	if selector.Scheme == "" {
+		return "https"
+	}
+	return selector.Scheme
+}
+
+// ttl returns how long resolved records are used.
+func (selector *SRVEndpointSelector) ttl() time.Duration {
+	if selector.TTL <= 0 {
+		return 30 * time.Second
+	}
+	return selector.TTL
+}
+
+// now returns the current time of the clock.
+func (selector *SRVEndpointSelector) now() time.Time {
+	if selector.Clock == nil {
+		return time.Now()
+	}
+	return selector.Clock.Now()
+}
+
+// srvHost returns the host and port of the SRV record.
+func srvHost(record *net.SRV) string {
+	return net.JoinHostPort(strings.TrimSuffix(record.Target, "."), strconv.Itoa(int(record.Port)))
+}
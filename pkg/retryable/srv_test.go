@@ -0,0 +1,134 @@
+package retryable
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type mockResolver struct {
+	records [][]*net.SRV
+	lookups int
+}
+
+func (resolver *mockResolver) LookupSRV(_ context.Context, service string, proto string, name string) (cname string, addrs []*net.SRV, err error) {
+	if service != "api" || proto != "tcp" || name != "example.test" {
+		return "", nil, errors.New("unexpected lookup")
+	}
+	index := resolver.lookups
+	resolver.lookups++
+	if index >= len(resolver.records) {
+		return "", nil, errors.New("lookup failed")
+	}
+	return "", resolver.records[index], nil
+}
+
+func TestClient_SRVEndpointSelector(test *testing.T) {
+	test.Parallel()
+
+	primary := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		writer.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer primary.Close()
+	secondary := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		writer.WriteHeader(http.StatusOK)
+	}))
+	defer secondary.Close()
+
+	record := func(server *httptest.Server, priority uint16) *net.SRV {
+		address, err := url.Parse(server.URL)
+		require.NoError(test, err)
+		host, port, err := net.SplitHostPort(address.Host)
+		require.NoError(test, err)
+		number, err := strconv.Atoi(port)
+		require.NoError(test, err)
+		return &net.SRV{Target: host + ".", Port: uint16(number), Priority: priority, Weight: 10}
+	}
+	resolver := &mockResolver{records: [][]*net.SRV{{record(secondary, 20), record(primary, 10)}}}
+	selector := &SRVEndpointSelector{Service: "api", Name: "example.test", Scheme: "http", Resolver: resolver}
+
+	var attempts []Attempt
+	client := new(Client)
+	client.RetryCount = 1
+	client.RetryStatus = []int{http.StatusServiceUnavailable}
+	client.EndpointSelector = selector
+	client.OnAttempt = func(attempt Attempt) {
+		attempts = append(attempts, attempt)
+	}
+	response, err := client.Get("http://service.invalid/resource")
+	require.NoError(test, err)
+	require.NoError(test, response.Body.Close())
+	require.Len(test, attempts, 2)
+	require.Equal(test, uint16(10), attempts[0].SRV.Priority)
+	require.Equal(test, uint16(20), attempts[1].SRV.Priority)
+	require.Equal(test, secondary.URL, attempts[1].Endpoint)
+	require.Equal(test, 1, resolver.lookups)
+}
+
+func TestSRVEndpointSelector(test *testing.T) {
+	test.Parallel()
+
+	clock := &MockClock{now: time.Unix(0, 0)}
+	resolver := &mockResolver{records: [][]*net.SRV{
+		{
+			{Target: "a.example.test.", Port: 443, Priority: 1, Weight: 1},
+			{Target: "b.example.test.", Port: 443, Priority: 1, Weight: 3},
+			{Target: "c.example.test.", Port: 8443, Priority: 2, Weight: 0},
+		},
+		{
+			{Target: "d.example.test.", Port: 443, Priority: 1, Weight: 0},
+		},
+	}}
+	var pick int
+	selector := &SRVEndpointSelector{Service: "api", Name: "example.test", Resolver: resolver, TTL: time.Minute, Clock: clock}
+	selector.random = func(n int) int {
+		return pick % n
+	}
+
+	// Select by weight within the lowest priority
+	endpoint, err := selector.SelectEndpoint(0, nil)
+	require.NoError(test, err)
+	require.Equal(test, "https://a.example.test:443", endpoint.String())
+	pick = 1
+	endpoint, err = selector.SelectEndpoint(0, nil)
+	require.NoError(test, err)
+	require.Equal(test, "https://b.example.test:443", endpoint.String())
+
+	// Skip failed records, falling back to the next priority
+	selector.ReportEndpoint(endpoint, errors.New("failure"))
+	endpoint, err = selector.SelectEndpoint(1, nil)
+	require.NoError(test, err)
+	require.Equal(test, "https://a.example.test:443", endpoint.String())
+	selector.ReportEndpoint(endpoint, errors.New("failure"))
+	endpoint, err = selector.SelectEndpoint(2, nil)
+	require.NoError(test, err)
+	require.Equal(test, "https://c.example.test:8443", endpoint.String())
+	require.Equal(test, uint16(8443), selector.record(endpoint).Port)
+	require.Equal(test, 1, resolver.lookups)
+
+	// Resolve again after the TTL expires
+	clock.now = clock.now.Add(time.Minute)
+	endpoint, err = selector.SelectEndpoint(0, nil)
+	require.NoError(test, err)
+	require.Equal(test, "https://d.example.test:443", endpoint.String())
+	require.Len(test, selector.Records(), 1)
+
+	// Keep stale records if resolution fails
+	clock.now = clock.now.Add(time.Minute)
+	endpoint, err = selector.SelectEndpoint(0, nil)
+	require.NoError(test, err)
+	require.Equal(test, "https://d.example.test:443", endpoint.String())
+	require.Equal(test, 3, resolver.lookups)
+
+	// Fail without records
+	_, err = (&SRVEndpointSelector{Service: "api", Name: "example.test", Resolver: new(mockResolver)}).SelectEndpoint(0, nil)
+	require.Error(test, err)
+}
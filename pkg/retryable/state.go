@@ -0,0 +1,116 @@
+package retryable
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// stateMutex guards the lazy initialization of client state.
+var stateMutex sync.Mutex
+
+// clientState contains the mutable state shared by all requests sent by a
+// client.
+type clientState struct {
+	// mutex guards access to the client state.
+	mutex sync.Mutex
+
+	// validators contains the most recent validators per URL.
+	validators map[string]validators
+
+	// draining is closed when the client starts draining.
+	draining chan struct{}
+
+	// drainOnce guards closing the drain channel.
+	drainOnce sync.Once
+
+	// closed specifies whether the client was shut down.
+	closed bool
+
+	// closing is closed when the client is shut down.
+	closing chan struct{}
+
+	// closeOnce guards closing the closing channel.
+	closeOnce sync.Once
+
+	// requests contains the cancel functions of in-flight requests.
+	requests map[uint64]context.CancelFunc
+
+	// nextRequest contains the identifier of the next in-flight request.
+	nextRequest uint64
+
+	// idle is closed when the client was shut down and the last in-flight
+	// request finishes.
+	idle chan struct{}
+
+	// hosts contains the most recent failure per host.
+	hosts map[string]HostStatus
+
+	// pacing contains the time until which requests are delayed per host.
+	pacing map[string]time.Time
+
+	// expectRejected contains the hosts that rejected the Expect:
+	// 100-continue header.
+	expectRejected map[string]struct{}
+
+	// adaptive contains the adaptive base delay.
+	adaptive time.Duration
+
+	// operations contains the response checks per operation name.
+	operations map[string]ResponseCheck
+
+	// sizes contains the recorded response sizes.
+	sizes sizeState
+
+	// fallbackAddresses contains the static fallback addresses per hostname.
+	fallbackAddresses map[string][]string
+
+	// wrappedBase contains the transport that the wrapped transport copies.
+	wrappedBase http.RoundTripper
+
+	// wrappedTransport contains the transport that dials fallback addresses
+	// and connects through selected proxies.
+	wrappedTransport *http.Transport
+
+	// http1Base contains the transport that the HTTP/1.1 transport copies.
+	http1Base *http.Transport
+
+	// http1Transport contains the transport with HTTP/2 disabled.
+	http1Transport *http.Transport
+
+	// endpointTransports contains the copies of the transport per selected
+	// endpoint.
+	endpointTransports map[endpointTransportKey]*http.Transport
+
+	// encodings contains the registered content codings per name.
+	encodings map[string]Encoding
+
+	// deprecations contains the deprecated endpoints per endpoint.
+	deprecations map[string]Deprecation
+
+	// policy contains the policy from the most recent call to UpdatePolicy.
+	policy atomic.Pointer[Policy]
+
+	// stats contains the live counters of the client.
+	stats clientStats
+
+	// flights contains the requests in progress that are shared with
+	// identical concurrent requests per deduplication key.
+	flights map[string]*flight
+
+	// events contains the channel of retry lifecycle events, if requested,
+	// so that requests can check for it without locking.
+	events atomic.Pointer[chan Event]
+}
+
+// state returns the shared state of the client, initializing it if required.
+func (client *Client) state() *clientState {
+	stateMutex.Lock()
+	defer stateMutex.Unlock()
+	if client.shared == nil {
+		client.shared = new(clientState)
+	}
+	return client.shared
+}
@@ -0,0 +1,258 @@
+package retryable
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// attemptDurationBounds contains the upper bounds in seconds of the buckets
+// of the attempt duration histogram.
+var attemptDurationBounds = [...]float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60}
+
+// Stats contains a snapshot of the live counters and health of a client, so
+// that an admin endpoint can report the health of the client without an
+// external metrics system. Stats can be published with [expvar]:
+//
+//	expvar.Publish("client", expvar.Func(func() any { return client.Stats() }))
+type Stats struct {
+	// InFlight specifies the number of requests currently in progress.
+	InFlight int
+
+	// Requests specifies the number of requests accepted by the client since
+	// it was created.
+	Requests uint64
+
+	// Attempts specifies the number of attempts sent, including retries.
+	Attempts uint64
+
+	// Retries specifies the number of attempts that followed a failed attempt
+	// of the same request.
+	Retries uint64
+
+	// Failures specifies the number of attempts that failed.
+	Failures uint64
+
+	// Draining specifies whether the client is in drain mode.
+	Draining bool
+
+	// Closed specifies whether the client was shut down.
+	Closed bool
+
+	// BaseDelay specifies the current base delay for exponential backoff,
+	// which differs from the retry delay if adaptive backoff is specified.
+	BaseDelay time.Duration
+
+	// HostDelays specifies the remaining server-specified delay per host, for
+	// hosts with a pending delay if host pacing is specified.
+	HostDelays map[string]time.Duration
+
+	// HostFailures specifies the most recent failed attempt per host.
+	HostFailures map[string]HostStatus
+
+	// UnhealthyEndpoints specifies the redacted URLs of the endpoints that
+	// are currently skipped, if the endpoint selector is a
+	// [FailoverEndpointSelector].
+	UnhealthyEndpoints []string
+
+	// DroppedEvents specifies the number of events that were dropped because
+	// the channel returned by [Client.Events] was full.
+	DroppedEvents uint64
+
+	// AttemptDurations specifies the histogram of attempt durations in
+	// seconds.
+	AttemptDurations Histogram
+}
+
+// Histogram contains the distribution of observed values.
+type Histogram struct {
+	// Bounds specifies the upper bounds of the buckets in ascending order.
+	Bounds []float64
+
+	// Counts specifies the number of observations per bucket, which are
+	// greater than the bound of the previous bucket and less than or equal to
+	// the bound of the bucket.
+	Counts []uint64
+
+	// Count specifies the total number of observations, including those
+	// greater than the largest bound.
+	Count uint64
+
+	// Sum specifies the sum of the observations.
+	Sum float64
+}
+
+// clientStats contains the live counters of a client.
+type clientStats struct {
+	// requests contains the number of requests sent.
+	requests atomic.Uint64
+
+	// attempts contains the number of attempts sent.
+	attempts atomic.Uint64
+
+	// retries contains the number of attempts that were retries.
+	retries atomic.Uint64
+
+	// failures contains the number of attempts that failed.
+	failures atomic.Uint64
+
+	// durations contains the number of attempts per duration bucket, with an
+	// additional bucket for longer attempts.
+	durations [len(attemptDurationBounds) + 1]atomic.Uint64
+
+	// durationSum contains the sum of the attempt durations.
+	durationSum atomic.Int64
+
+	// droppedEvents contains the number of events dropped because the event
+	// channel was full.
+	droppedEvents atomic.Uint64
+}
+
+// observeDuration records the duration of an attempt.
+func (stats *clientStats) observeDuration(duration time.Duration) {
+	index := sort.SearchFloat64s(attemptDurationBounds[:], duration.Seconds())
+	stats.durations[index].Add(1)
+	stats.durationSum.Add(int64(duration))
+}
+
+// Stats returns a snapshot of the live counters and health of the client. It
+// is safe to call while the client is sending requests.
+func (client *Client) Stats() (stats Stats) {
+	// Read counters
+	state := client.state()
+	stats.Requests = state.stats.requests.Load()
+	stats.Attempts = state.stats.attempts.Load()
+	stats.Retries = state.stats.retries.Load()
+	stats.Failures = state.stats.failures.Load()
+	stats.DroppedEvents = state.stats.droppedEvents.Load()
+	stats.Draining = client.Draining()
+	stats.BaseDelay = client.baseDelay()
+	stats.AttemptDurations = Histogram{
+		Bounds: append([]float64(nil), attemptDurationBounds[:]...),
+		Counts: make([]uint64, len(attemptDurationBounds)),
+		Sum:    time.Duration(state.stats.durationSum.Load()).Seconds(),
+	}
+	for index := range state.stats.durations {
+		count := state.stats.durations[index].Load()
+		if index < len(attemptDurationBounds) {
+			stats.AttemptDurations.Counts[index] = count
+		}
+		stats.AttemptDurations.Count += count
+	}
+
+	// Read host state
+	now := client.clock().Now()
+	state.mutex.Lock()
+	stats.InFlight = len(state.requests)
+	stats.Closed = state.closed
+	stats.HostDelays = make(map[string]time.Duration)
+	for host, until := range state.pacing {
+		if delay := until.Sub(now); delay > 0 {
+			stats.HostDelays[host] = delay
+		}
+	}
+	stats.HostFailures = make(map[string]HostStatus, len(state.hosts))
+	for host, status := range state.hosts {
+		stats.HostFailures[host] = status
+	}
+	state.mutex.Unlock()
+
+	// Read endpoint health
+	if selector, ok := client.EndpointSelector.(*FailoverEndpointSelector); ok {
+		for _, endpoint := range selector.Endpoints {
+			if !selector.Healthy(endpoint) {
+				stats.UnhealthyEndpoints = append(stats.UnhealthyEndpoints, endpoint.Redacted())
+			}
+		}
+	}
+	return stats
+}
+
+// WriteOpenMetrics writes the stats in the OpenMetrics text format, so that
+// they can be scraped by Prometheus without depending on a metrics library.
+// Metric names are prefixed with "retryable_".
+func (stats Stats) WriteOpenMetrics(writer io.Writer) (err error) {
+	buffered := bufio.NewWriter(writer)
+
+	// Write counters and gauges
+	for _, metric := range []struct {
+		name  string
+		kind  string
+		help  string
+		value float64
+	}{
+		{"retryable_requests", "counter", "Requests accepted by the client.", float64(stats.Requests)},
+		{"retryable_attempts", "counter", "Attempts sent, including retries.", float64(stats.Attempts)},
+		{"retryable_retries", "counter", "Attempts that followed a failed attempt.", float64(stats.Retries)},
+		{"retryable_failures", "counter", "Attempts that failed.", float64(stats.Failures)},
+		{"retryable_dropped_events", "counter", "Events dropped because the event channel was full.", float64(stats.DroppedEvents)},
+		{"retryable_in_flight", "gauge", "Requests in progress.", float64(stats.InFlight)},
+		{"retryable_draining", "gauge", "Whether the client is in drain mode.", openMetricsBool(stats.Draining)},
+		{"retryable_closed", "gauge", "Whether the client was shut down.", openMetricsBool(stats.Closed)},
+		{"retryable_base_delay_seconds", "gauge", "Base delay for exponential backoff.", stats.BaseDelay.Seconds()},
+	} {
+		fmt.Fprintf(buffered, "# TYPE %s %s\n# HELP %s %s\n", metric.name, metric.kind, metric.name, metric.help)
+		suffix := ""
+		if metric.kind == "counter" {
+			suffix = "_total"
+		}
+		fmt.Fprintf(buffered, "%s%s %s\n", metric.name, suffix, openMetricsFloat(metric.value))
+	}
+
+	// Write per-host delays and unhealthy endpoints in a stable order
+	fmt.Fprintf(buffered, "# TYPE retryable_host_delay_seconds gauge\n# HELP retryable_host_delay_seconds Remaining server-specified delay per host.\n")
+	hosts := make([]string, 0, len(stats.HostDelays))
+	for host := range stats.HostDelays {
+		hosts = append(hosts, host)
+	}
+	sort.Strings(hosts)
+	for _, host := range hosts {
+		fmt.Fprintf(buffered, "retryable_host_delay_seconds{host=\"%s\"} %s\n",
+			openMetricsLabel(host), openMetricsFloat(stats.HostDelays[host].Seconds()))
+	}
+	fmt.Fprintf(buffered, "# TYPE retryable_endpoint_unhealthy gauge\n# HELP retryable_endpoint_unhealthy Endpoints that are currently skipped.\n")
+	for _, endpoint := range stats.UnhealthyEndpoints {
+		fmt.Fprintf(buffered, "retryable_endpoint_unhealthy{endpoint=\"%s\"} 1\n", openMetricsLabel(endpoint))
+	}
+
+	// Write attempt duration histogram with cumulative buckets
+	histogram := stats.AttemptDurations
+	fmt.Fprintf(buffered, "# TYPE retryable_attempt_duration_seconds histogram\n")
+	fmt.Fprintf(buffered, "# UNIT retryable_attempt_duration_seconds seconds\n")
+	fmt.Fprintf(buffered, "# HELP retryable_attempt_duration_seconds Duration of attempts.\n")
+	cumulative := uint64(0)
+	for index, bound := range histogram.Bounds {
+		if index < len(histogram.Counts) {
+			cumulative += histogram.Counts[index]
+		}
+		fmt.Fprintf(buffered, "retryable_attempt_duration_seconds_bucket{le=\"%s\"} %d\n", openMetricsFloat(bound), cumulative)
+	}
+	fmt.Fprintf(buffered, "retryable_attempt_duration_seconds_bucket{le=\"+Inf\"} %d\n", histogram.Count)
+	fmt.Fprintf(buffered, "retryable_attempt_duration_seconds_sum %s\n", openMetricsFloat(histogram.Sum))
+	fmt.Fprintf(buffered, "retryable_attempt_duration_seconds_count %d\n", histogram.Count)
+	fmt.Fprintf(buffered, "# EOF\n")
+	return buffered.Flush()
+}
+
+// openMetricsFloat formats the value as an OpenMetrics number.
+func openMetricsFloat(value float64) string {
+	return strconv.FormatFloat(value, 'g', -1, 64)
+}
+
+// openMetricsBool formats the value as an OpenMetrics number.
+func openMetricsBool(value bool) float64 {
+	if value {
+		return 1
+	}
+	return 0
+}
+
+// openMetricsLabel escapes the value of an OpenMetrics label.
+func openMetricsLabel(value string) string {
+	return strings.NewReplacer("\\", "\\\\", "\"", "\\\"", "\n", "\\n").Replace(value)
+}
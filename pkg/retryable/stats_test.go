@@ -0,0 +1,89 @@
+package retryable
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestClient_Stats(test *testing.T) {
+	test.Parallel()
+
+	var count atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		if count.Add(1) == 1 {
+			writer.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		writer.Header().Set("Retry-After", "3600")
+	}))
+	defer server.Close()
+
+	endpoint := &url.URL{Scheme: "https", Host: "primary"}
+	selector := &FailoverEndpointSelector{Endpoints: []*url.URL{endpoint}, FailureThreshold: 1}
+	selector.ReportEndpoint(endpoint, context.DeadlineExceeded)
+	client := new(Client)
+	client.RetryCount = 1
+	client.RetryStatus = []int{http.StatusServiceUnavailable}
+	client.HostPacing = true
+	stats := client.Stats()
+	require.Zero(test, stats.Requests)
+	require.Empty(test, stats.HostDelays)
+	require.Empty(test, stats.HostFailures)
+	require.Zero(test, stats.AttemptDurations.Count)
+
+	response, err := client.Get(server.URL)
+	require.NoError(test, err)
+	require.NoError(test, response.Body.Close())
+	client.EndpointSelector = selector
+	stats = client.Stats()
+	require.Zero(test, stats.InFlight)
+	require.Equal(test, uint64(1), stats.Requests)
+	require.Equal(test, uint64(2), stats.Attempts)
+	require.Equal(test, uint64(1), stats.Retries)
+	require.Equal(test, uint64(1), stats.Failures)
+	require.False(test, stats.Draining)
+	require.False(test, stats.Closed)
+	host := normalizeHost("", server.Listener.Addr().String())
+	require.Equal(test, http.StatusServiceUnavailable, stats.HostFailures[host].StatusCode)
+	require.Greater(test, stats.HostDelays[host], time.Minute)
+	require.Equal(test, []string{"https://primary"}, stats.UnhealthyEndpoints)
+	require.Equal(test, uint64(2), stats.AttemptDurations.Count)
+	require.Len(test, stats.AttemptDurations.Counts, len(stats.AttemptDurations.Bounds))
+}
+
+func TestStats_WriteOpenMetrics(test *testing.T) {
+	test.Parallel()
+
+	stats := Stats{
+		Requests:           3,
+		Attempts:           5,
+		Draining:           true,
+		BaseDelay:          1500 * time.Millisecond,
+		HostDelays:         map[string]time.Duration{"b.example": time.Second, "a.example": time.Minute},
+		UnhealthyEndpoints: []string{`https://"quoted"`},
+		AttemptDurations:   Histogram{Bounds: []float64{0.1, 1}, Counts: []uint64{2, 1}, Count: 5, Sum: 7.5},
+	}
+	var buffer strings.Builder
+	require.NoError(test, stats.WriteOpenMetrics(&buffer))
+	output := buffer.String()
+	require.Contains(test, output, "# TYPE retryable_requests counter\n# HELP retryable_requests Requests accepted by the client.\nretryable_requests_total 3\n")
+	require.Contains(test, output, "retryable_attempts_total 5\n")
+	require.Contains(test, output, "retryable_draining 1\n")
+	require.Contains(test, output, "retryable_base_delay_seconds 1.5\n")
+	require.Contains(test, output, "retryable_host_delay_seconds{host=\"a.example\"} 60\nretryable_host_delay_seconds{host=\"b.example\"} 1\n")
+	require.Contains(test, output, `retryable_endpoint_unhealthy{endpoint="https://\"quoted\""} 1`)
+	require.Contains(test, output, "retryable_attempt_duration_seconds_bucket{le=\"0.1\"} 2\n"+
+		"retryable_attempt_duration_seconds_bucket{le=\"1\"} 3\n"+
+		"retryable_attempt_duration_seconds_bucket{le=\"+Inf\"} 5\n"+
+		"retryable_attempt_duration_seconds_sum 7.5\n"+
+		"retryable_attempt_duration_seconds_count 5\n")
+	require.True(test, strings.HasSuffix(output, "# EOF\n"))
+}
@@ -0,0 +1,165 @@
+package retryable
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/cholland1989/go-retryable/pkg/unofficial"
+)
+
+// Class classifies the cause of an error status code.
+type Class string
+
+// Classes reported by [Classify].
+const (
+	ClassNone                Class = ""
+	ClassThrottled           Class = "throttled"
+	ClassNetworkIntermediary Class = "network_intermediary"
+	ClassServerError         Class = "server_error"
+	ClassClientError         Class = "client_error"
+)
+
+// Classify returns the class of the status code, such as [ClassThrottled] for
+// rate limiting, or [ClassNetworkIntermediary] for a gateway or CDN that could
+// not reach the origin server. Status codes below 400 are [ClassNone].
+func Classify(status int) Class {
+	// Check for rate limiting
+	switch status {
+	case http.StatusTooManyRequests,
+		unofficial.StatusEnhanceYourCalm,
+		unofficial.StatusRequestHeaderFieldsTooLarge,
+		unofficial.StatusBandwidthLimitExceeded,
+		unofficial.StatusSiteIsOverloaded:
+		return ClassThrottled
+	}
+
+	// Check for gateway and proxy errors
+	switch status {
+	case http.StatusBadGateway,
+		http.StatusGatewayTimeout,
+		unofficial.StatusNetworkReadTimeout,
+		unofficial.StatusNetworkConnectTimeout:
+		return ClassNetworkIntermediary
+	}
+	for _, cloudflare := range unofficial.CloudflareStatuses {
+		if cloudflare == status {
+			return ClassNetworkIntermediary
+		}
+	}
+
+	// Check for remaining error status codes
+	switch {
+	case status >= http.StatusInternalServerError:
+		return ClassServerError
+	case status >= http.StatusBadRequest:
+		return ClassClientError
+	}
+	return ClassNone
+}
+
+// IsRetryableStatus reports whether the status code is retryable by default,
+// which is whether it is one of [DefaultStatus] and not one of
+// [DefaultPermanentStatus].
+func IsRetryableStatus(status int) bool {
+	for _, permanent := range DefaultPermanentStatus {
+		if permanent == status {
+			return false
+		}
+	}
+	for _, retry := range DefaultStatus {
+		if retry == status {
+			return true
+		}
+	}
+	return false
+}
+
+// StatusRange defines an inclusive range of status codes, such as 500 to 599
+// for all server errors, so that retryable status codes can be specified
+// without enumerating them.
+type StatusRange struct {
+	// Min specifies the lowest status code of the range.
+	Min int
+
+	// Max specifies the highest status code of the range.
+	Max int
+}
+
+// ServerErrors defines the range of all server error status codes.
+var ServerErrors = StatusRange{Min: 500, Max: 599}
+
+// Contains reports whether the status code is within the range.
+func (statusRange StatusRange) Contains(status int) bool {
+	return status >= statusRange.Min && status <= statusRange.Max
+}
+
+// String returns the range as "500-599", or as a single status code if the
+// range contains only one.
+func (statusRange StatusRange) String() string {
+	if statusRange.Min == statusRange.Max {
+		return strconv.Itoa(statusRange.Min)
+	}
+	return fmt.Sprintf("%d-%d", statusRange.Min, statusRange.Max)
+}
+
+// MarshalText encodes the range as a string, such as "500-599".
+func (statusRange StatusRange) MarshalText() (text []byte, err error) {
+	return []byte(statusRange.String()), nil
+}
+
+// UnmarshalText decodes the range from a string, such as "500-599", a status
+// class, such as "5xx", or a single status code, such as "503".
+func (statusRange *StatusRange) UnmarshalText(text []byte) (err error) {
+	// Check for status class
+	value := strings.TrimSpace(string(text))
+	if len(value) == 3 && strings.EqualFold(value[1:], "xx") && value[0] >= '1' && value[0] <= '9' {
+		class := int(value[0]-'0') * 100
+		*statusRange = StatusRange{Min: class, Max: class + 99}
+		return nil
+	}
+
+	// Parse range bounds
+	lowerText, upperText, found := strings.Cut(value, "-")
+	if !found {
+		upperText = lowerText
+	}
+	lower, err := strconv.Atoi(strings.TrimSpace(lowerText))
+	if err != nil {
+		return fmt.Errorf("%w: invalid status range (%s)", ErrInvalidConfig, value)
+	}
+	upper, err := strconv.Atoi(strings.TrimSpace(upperText))
+	if err != nil {
+		return fmt.Errorf("%w: invalid status range (%s)", ErrInvalidConfig, value)
+	}
+	*statusRange = StatusRange{Min: lower, Max: upper}
+	return nil
+}
+
+// isRetryStatus reports whether the status code is one of the retryable
+// status codes, or within one of the retryable status ranges, and is not one
+// of the non-retryable status codes.
+func (client *Client) isRetryStatus(status int) bool {
+	// Check for non-retryable status code
+	for _, noRetry := range client.NoRetryStatus {
+		if noRetry == status {
+			return false
+		}
+	}
+
+	// Check for retryable status code
+	for _, retry := range client.RetryStatus {
+		if retry == status {
+			return true
+		}
+	}
+
+	// Check for retryable status range
+	for _, retry := range client.RetryRanges {
+		if retry.Contains(status) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,111 @@
+package retryable
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestStatusRange(test *testing.T) {
+	test.Parallel()
+
+	require.True(test, ServerErrors.Contains(http.StatusBadGateway))
+	require.False(test, ServerErrors.Contains(http.StatusTooManyRequests))
+	require.Equal(test, "500-599", ServerErrors.String())
+	require.Equal(test, "503", StatusRange{Min: 503, Max: 503}.String())
+
+	var statusRange StatusRange
+	for text, expected := range map[string]StatusRange{
+		"5xx":       ServerErrors,
+		"4XX":       {Min: 400, Max: 499},
+		"520 - 529": {Min: 520, Max: 529},
+		"503":       {Min: 503, Max: 503},
+	} {
+		require.NoError(test, statusRange.UnmarshalText([]byte(text)))
+		require.Equal(test, expected, statusRange)
+	}
+	require.ErrorIs(test, statusRange.UnmarshalText([]byte("5xy")), ErrInvalidConfig)
+	require.ErrorIs(test, statusRange.UnmarshalText([]byte("500-")), ErrInvalidConfig)
+}
+
+func TestClient_RetryRanges(test *testing.T) {
+	test.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		writer.WriteHeader(599)
+	}))
+	defer server.Close()
+
+	client := new(Client)
+	client.RetryCount = 2
+	_, err := client.Get(server.URL)
+	require.ErrorIs(test, err, ErrNonRetryable)
+
+	client.RetryRanges = []StatusRange{ServerErrors}
+	_, err = client.Get(server.URL)
+	require.ErrorIs(test, err, ErrRetryable)
+
+	client, err = LoadJSON(strings.NewReader(`{"retry_ranges": ["5xx", "420-429"]}`))
+	require.NoError(test, err)
+	require.Equal(test, []StatusRange{ServerErrors, {Min: 420, Max: 429}}, client.RetryRanges)
+	client, err = Parse("?retry_ranges=5xx,429")
+	require.NoError(test, err)
+	require.Equal(test, []StatusRange{ServerErrors, {Min: 429, Max: 429}}, client.RetryRanges)
+	_, err = LoadYAML(strings.NewReader("retry_ranges: [599-500]\n"))
+	require.ErrorIs(test, err, ErrInvalidConfig)
+	require.ErrorContains(test, err, "retry_ranges")
+}
+
+func TestClient_NoRetryStatus(test *testing.T) {
+	test.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		writer.WriteHeader(520)
+	}))
+	defer server.Close()
+
+	client := new(Client)
+	client.RetryStatus = []int{520}
+	client.RetryRanges = []StatusRange{ServerErrors}
+	client.NoRetryStatus = []int{520}
+	_, err := client.Get(server.URL)
+	require.ErrorIs(test, err, ErrNonRetryable)
+
+	client.UpdatePolicy(Policy{RetryRanges: []StatusRange{ServerErrors}})
+	_, err = client.Get(server.URL)
+	require.ErrorIs(test, err, ErrRetryable)
+
+	client, err = Parse("?retry_ranges=5xx&no_retry_status=501,520")
+	require.NoError(test, err)
+	require.Equal(test, []int{501, 520}, client.NoRetryStatus)
+	_, err = Parse("?no_retry_status=42")
+	require.ErrorIs(test, err, ErrInvalidConfig)
+	require.ErrorContains(test, err, "no_retry_status")
+}
+
+func TestClassify(test *testing.T) {
+	test.Parallel()
+
+	require.Equal(test, ClassNone, Classify(http.StatusOK))
+	require.Equal(test, ClassNone, Classify(http.StatusFound))
+	require.Equal(test, ClassClientError, Classify(http.StatusNotFound))
+	require.Equal(test, ClassThrottled, Classify(http.StatusTooManyRequests))
+	require.Equal(test, ClassThrottled, Classify(420))
+	require.Equal(test, ClassNetworkIntermediary, Classify(http.StatusBadGateway))
+	require.Equal(test, ClassNetworkIntermediary, Classify(522))
+	require.Equal(test, ClassServerError, Classify(http.StatusServiceUnavailable))
+	require.Equal(test, ClassServerError, Classify(http.StatusNotImplemented))
+}
+
+func TestIsRetryableStatus(test *testing.T) {
+	test.Parallel()
+
+	require.True(test, IsRetryableStatus(http.StatusServiceUnavailable))
+	require.True(test, IsRetryableStatus(http.StatusTooManyRequests))
+	require.False(test, IsRetryableStatus(http.StatusNotFound))
+	require.False(test, IsRetryableStatus(http.StatusNotImplemented))
+	require.False(test, IsRetryableStatus(http.StatusOK))
+}
@@ -0,0 +1,210 @@
+package retryable
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DecisionKind defines the kind of decision the client made after an
+// attempt.
+type DecisionKind string
+
+const (
+	// DecisionAccept indicates that the response was accepted.
+	DecisionAccept DecisionKind = "accept"
+
+	// DecisionRefresh indicates that rejected credentials were refreshed and
+	// the attempt is repeated.
+	DecisionRefresh DecisionKind = "refresh"
+
+	// DecisionRetry indicates that the request is retried after a delay.
+	DecisionRetry DecisionKind = "retry"
+
+	// DecisionStop indicates that the request is not retried.
+	DecisionStop DecisionKind = "stop"
+)
+
+// Delay sources of a retry decision.
+const (
+	// DelaySourceRetryAfter indicates that the delay was specified by the
+	// Retry-After header of the response.
+	DelaySourceRetryAfter = "Retry-After"
+
+	// DelaySourceParser indicates that the delay was specified by one of the
+	// retry delay parsers of the client.
+	DelaySourceParser = "RetryDelayParsers"
+
+	// DelaySourceBackoff indicates that the delay was computed by exponential
+	// backoff with random jitter.
+	DelaySourceBackoff = "backoff"
+)
+
+// Decision describes a decision the client made after an attempt of a
+// request.
+type Decision struct {
+	// Time specifies when the decision was made.
+	Time time.Time
+
+	// Attempt specifies the attempt number, starting from zero.
+	Attempt int
+
+	// Kind specifies the kind of decision.
+	Kind DecisionKind
+
+	// Reason specifies why the decision was made, such as "status 503" or
+	// "retry count exhausted".
+	Reason string
+
+	// Err specifies the error of the attempt, if any.
+	Err error
+
+	// Free specifies whether the retry does not consume the retry count.
+	Free bool
+
+	// Delay specifies the delay before the next attempt, if retried.
+	Delay time.Duration
+
+	// DelaySource specifies what determined the delay, such as
+	// [DelaySourceRetryAfter], if retried.
+	DelaySource string
+
+	// RetriesRemaining specifies the number of retries remaining after the
+	// decision.
+	RetriesRemaining int
+
+	// DelayBudget specifies the remaining total delay budget after the
+	// decision, or -1 if the total delay is unlimited.
+	DelayBudget time.Duration
+
+	// Deadline specifies when the retry timeout of the request expires, or
+	// zero if the request has no deadline.
+	Deadline time.Time
+}
+
+// String returns a single line summary of the decision.
+func (decision Decision) String() string {
+	var builder strings.Builder
+	fmt.Fprintf(&builder, "attempt %d: %s", decision.Attempt, decision.Kind)
+	if decision.Kind == DecisionRetry {
+		fmt.Fprintf(&builder, " after %s (%s)", decision.Delay, decision.DelaySource)
+		if decision.Free {
+			builder.WriteString(" free")
+		}
+	}
+	if decision.Reason != "" {
+		fmt.Fprintf(&builder, ": %s", decision.Reason)
+	}
+	fmt.Fprintf(&builder, "; %d retries remaining", decision.RetriesRemaining)
+	if decision.DelayBudget >= 0 {
+		fmt.Fprintf(&builder, ", %s delay budget remaining", decision.DelayBudget)
+	}
+	if decision.Err != nil {
+		fmt.Fprintf(&builder, ": %v", decision.Err)
+	}
+	return builder.String()
+}
+
+// Trace records the retry decisions of the requests sent with a context from
+// [WithTrace], for debugging why a request was or was not retried. It is safe
+// for concurrent use.
+type Trace struct {
+	// mutex guards access to the decisions.
+	mutex sync.Mutex
+
+	// decisions contains the recorded decisions.
+	decisions []Decision
+}
+
+// Decisions returns a copy of the recorded decisions in chronological order.
+func (trace *Trace) Decisions() (decisions []Decision) {
+	trace.mutex.Lock()
+	defer trace.mutex.Unlock()
+	return append([]Decision(nil), trace.decisions...)
+}
+
+// String returns the recorded decisions, one per line.
+func (trace *Trace) String() string {
+	var builder strings.Builder
+	for _, decision := range trace.Decisions() {
+		builder.WriteString(decision.String())
+		builder.WriteByte('\n')
+	}
+	return builder.String()
+}
+
+// record appends the decision to the trace.
+func (trace *Trace) record(decision Decision) {
+	trace.mutex.Lock()
+	defer trace.mutex.Unlock()
+	trace.decisions = append(trace.decisions, decision)
+}
+
+// traceKey is the context key for the trace.
+type traceKey struct{}
+
+// WithTrace returns a copy of the context that records the retry decisions of
+// requests sent with it in the trace. Tracing is opt-in, so requests without
+// a trace do not pay for recording decisions.
+func WithTrace(ctx context.Context, trace *Trace) context.Context {
+	return context.WithValue(ctx, traceKey{}, trace)
+}
+
+// traceFrom returns the trace from the context, or nil if the context does
+// not contain a trace.
+func traceFrom(ctx context.Context) *Trace {
+	if ctx == nil {
+		return nil
+	}
+	trace, _ := ctx.Value(traceKey{}).(*Trace)
+	return trace
+}
+
+// traceDecision records the decision in the trace of the context, if any,
+// with the source of the retry delay of the response, and the remaining
+// retries and delay budget after the specified total delay.
+func (client *Client) traceDecision(ctx context.Context, decision Decision, response *http.Response, totalDelay time.Duration) {
+	// Check for trace
+	trace := traceFrom(ctx)
+	if trace == nil {
+		return
+	}
+
+	// Record delay source and remaining budgets
+	if decision.Kind == DecisionRetry {
+		decision.DelaySource = client.retryDelaySource(response)
+	}
+	decision.Time = client.clock().Now()
+	decision.Deadline, _ = ctx.Deadline()
+	decision.RetriesRemaining = client.RetryCount - decision.Attempt
+	if decision.Kind == DecisionRetry && !decision.Free {
+		decision.RetriesRemaining--
+	}
+	if decision.RetriesRemaining < 0 {
+		decision.RetriesRemaining = 0
+	}
+	decision.DelayBudget = -1
+	if client.MaxTotalDelay > 0 {
+		decision.DelayBudget = client.MaxTotalDelay - totalDelay
+	}
+	trace.record(decision)
+}
+
+// retryDelaySource returns what determines the retry delay of the response.
+func (client *Client) retryDelaySource(response *http.Response) string {
+	if _, ok := client.remainingRetryDelay(response); !ok {
+		return DelaySourceBackoff
+	}
+	header := response.Header.Get("Retry-After")
+	if _, err := strconv.ParseInt(header, 10, 64); err == nil {
+		return DelaySourceRetryAfter
+	}
+	if _, err := time.Parse(time.RFC1123, header); err == nil {
+		return DelaySourceRetryAfter
+	}
+	return DelaySourceParser
+}
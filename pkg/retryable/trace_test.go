@@ -0,0 +1,72 @@
+package retryable
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithTrace(test *testing.T) {
+	test.Parallel()
+
+	attempts := 0
+	client := new(Client)
+	client.Clock = &MockClock{now: time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC)}
+	client.RetryCount = 3
+	client.RetryDelay = time.Second
+	client.RetryStatus = []int{http.StatusServiceUnavailable, http.StatusTooManyRequests}
+	client.MaxTotalDelay = time.Minute
+	client.Transport = MockTransport(func(request *http.Request) (*http.Response, error) {
+		attempts++
+		response := &http.Response{StatusCode: http.StatusOK, Body: http.NoBody, Header: make(http.Header), Request: request}
+		switch attempts {
+		case 1:
+			response.StatusCode = http.StatusServiceUnavailable
+		case 2:
+			response.StatusCode = http.StatusTooManyRequests
+			response.Header.Set("Retry-After", "5")
+		}
+		return response, nil
+	})
+
+	trace := new(Trace)
+	request, err := http.NewRequestWithContext(WithTrace(context.Background(), trace), http.MethodGet, "http://example.invalid/", nil)
+	require.NoError(test, err)
+	response, err := client.Do(request)
+	require.NoError(test, err)
+	require.NoError(test, response.Body.Close())
+
+	decisions := trace.Decisions()
+	require.Len(test, decisions, 3)
+	require.Equal(test, DecisionRetry, decisions[0].Kind)
+	require.Equal(test, "status 503", decisions[0].Reason)
+	require.Equal(test, DelaySourceBackoff, decisions[0].DelaySource)
+	require.Equal(test, time.Second, decisions[0].Delay)
+	require.Equal(test, 2, decisions[0].RetriesRemaining)
+	require.Equal(test, 59*time.Second, decisions[0].DelayBudget)
+	require.Equal(test, DecisionRetry, decisions[1].Kind)
+	require.Equal(test, DelaySourceRetryAfter, decisions[1].DelaySource)
+	require.Equal(test, 5*time.Second, decisions[1].Delay)
+	require.Equal(test, 54*time.Second, decisions[1].DelayBudget)
+	require.Equal(test, DecisionAccept, decisions[2].Kind)
+	require.Contains(test, trace.String(), "attempt 1: retry after 5s (Retry-After): status 429")
+
+	// Stop when the retry count is exhausted
+	stopped := new(Trace)
+	client.RetryCount = 0
+	client.Transport = MockTransport(func(request *http.Request) (*http.Response, error) {
+		return &http.Response{StatusCode: http.StatusServiceUnavailable, Body: http.NoBody, Header: make(http.Header), Request: request}, nil
+	})
+	request, err = http.NewRequestWithContext(WithTrace(context.Background(), stopped), http.MethodGet, "http://example.invalid/", nil)
+	require.NoError(test, err)
+	_, err = client.Do(request)
+	require.ErrorIs(test, err, ErrRetryable)
+	decisions = stopped.Decisions()
+	require.Len(test, decisions, 1)
+	require.Equal(test, DecisionStop, decisions[0].Kind)
+	require.Equal(test, "retry count exhausted", decisions[0].Reason)
+	require.ErrorIs(test, decisions[0].Err, ErrRetryable)
+}
@@ -0,0 +1,35 @@
+package retryable
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// BodyTransform defines a transformation of a response body read into
+// memory, such as decryption or stripping a vendor envelope, which returns
+// the transformed body. An error that is not wrapped with [ErrRetryable] or
+// [ErrNonRetryable] is treated as retryable.
+type BodyTransform func(response *http.Response, body []byte) (transformed []byte, err error)
+
+// transformBody applies the body transforms of the client in order, and
+// enforces the response size on the transformed body.
+func (client *Client) transformBody(response *http.Response, body []byte) (transformed []byte, err error) {
+	transformed = body
+	for _, transform := range client.BodyTransforms {
+		// Apply transform
+		transformed, err = transform(response, transformed)
+		if err != nil && !errors.Is(err, ErrNonRetryable) && !errors.Is(err, ErrRetryable) {
+			err = fmt.Errorf("%w: unable to transform response body: %w", ErrRetryable, err)
+		}
+		if err != nil {
+			return body, err
+		}
+
+		// Check for valid response size
+		if client.ResponseSize > 0 && int64(len(transformed)) > client.ResponseSize {
+			return body, fmt.Errorf("%w: %w after transform (%d)", ErrNonRetryable, ErrResponseSize, len(transformed))
+		}
+	}
+	return transformed, nil
+}
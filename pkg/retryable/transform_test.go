@@ -0,0 +1,63 @@
+package retryable
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestClient_BodyTransforms(test *testing.T) {
+	test.Parallel()
+
+	var attempts atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, _ *http.Request) {
+		if attempts.Add(1) == 1 {
+			_, _ = writer.Write([]byte("truncated"))
+			return
+		}
+		_, _ = writer.Write([]byte("<envelope>payload</envelope>"))
+	}))
+	defer server.Close()
+
+	strip := func(_ *http.Response, body []byte) ([]byte, error) {
+		if !bytes.HasPrefix(body, []byte("<envelope>")) || !bytes.HasSuffix(body, []byte("</envelope>")) {
+			return nil, errors.New("missing envelope")
+		}
+		return bytes.TrimSuffix(bytes.TrimPrefix(body, []byte("<envelope>")), []byte("</envelope>")), nil
+	}
+	upper := func(_ *http.Response, body []byte) ([]byte, error) {
+		return bytes.ToUpper(body), nil
+	}
+	client := new(Client)
+	client.RetryCount = 1
+	client.StreamResponse = true
+	client.BodyTransforms = []BodyTransform{strip, upper}
+	client.CheckResponse = func(_ *http.Response, body []byte) error {
+		require.Equal(test, "PAYLOAD", string(body))
+		return nil
+	}
+	response, err := client.Get(server.URL)
+	require.NoError(test, err)
+	body, err := io.ReadAll(response.Body)
+	require.NoError(test, err)
+	require.NoError(test, response.Body.Close())
+	require.Equal(test, "PAYLOAD", string(body))
+	require.Equal(test, int64(len(body)), response.ContentLength)
+	require.Equal(test, int32(2), attempts.Load())
+
+	// Enforce response size on transformed body
+	client.ResponseSize = 32
+	client.CheckResponse = nil
+	client.BodyTransforms = []BodyTransform{func(_ *http.Response, body []byte) ([]byte, error) {
+		return bytes.Repeat(body, 2), nil
+	}}
+	_, err = client.Get(server.URL)
+	require.ErrorIs(test, err, ErrNonRetryable)
+	require.ErrorIs(test, err, ErrResponseSize)
+}
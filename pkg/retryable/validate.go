@@ -0,0 +1,140 @@
+package retryable
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+)
+
+// ErrUnsafeRequest defines an error for requests that contain conflicting or
+// malformed framing, which could be used for request smuggling.
+var ErrUnsafeRequest = errors.New("unsafe request")
+
+// ErrMethodNotAllowed defines an error for requests with a method that the
+// client is not allowed to send.
+var ErrMethodNotAllowed = errors.New("method not allowed")
+
+// validateRequest rejects requests with a conflicting host header, invalid
+// header names, control characters in header values, or conflicting message
+// framing headers. If the request or request URL is nil, the request is not
+// validated.
+func (client *Client) validateRequest(request *http.Request) (err error) {
+	// Check for valid request
+	if request == nil || request.URL == nil {
+		return nil
+	}
+
+	// Check for valid method
+	if request.Method != "" && !isToken(request.Method) {
+		return fmt.Errorf("%w: %w: invalid method (%q)", ErrNonRetryable, ErrUnsafeRequest, request.Method)
+	}
+
+	// Check for allowed method
+	err = client.checkMethod(request.Method)
+	if err != nil {
+		return err
+	}
+
+	// Check for valid URL host
+	if strings.ContainsAny(request.URL.Host, "\r\n\x00 ") {
+		return fmt.Errorf("%w: %w: invalid host (%q)", ErrNonRetryable, ErrUnsafeRequest, request.URL.Host)
+	}
+
+	// Check for conflicting host header
+	if !client.AllowHostOverride {
+		hosts := append([]string{request.Host}, request.Header.Values("Host")...)
+		for _, host := range hosts {
+			if host != "" && !equalHost(request.URL.Scheme, host, request.URL.Host) {
+				return fmt.Errorf("%w: %w: conflicting host header (%q)", ErrNonRetryable, ErrUnsafeRequest, host)
+			}
+		}
+	}
+
+	// Check for valid header names and values
+	for _, header := range []http.Header{request.Header, request.Trailer} {
+		for name, values := range header {
+			if !isToken(name) {
+				return fmt.Errorf("%w: %w: invalid header name (%q)", ErrNonRetryable, ErrUnsafeRequest, name)
+			}
+			for _, value := range values {
+				if strings.ContainsAny(value, "\r\n\x00") {
+					return fmt.Errorf("%w: %w: invalid header value (%s)", ErrNonRetryable, ErrUnsafeRequest, name)
+				}
+			}
+		}
+	}
+
+	// Check for conflicting framing headers
+	lengths := request.Header.Values("Content-Length")
+	if len(lengths) > 0 && len(request.Header.Values("Transfer-Encoding")) > 0 {
+		return fmt.Errorf("%w: %w: conflicting content length and transfer encoding", ErrNonRetryable, ErrUnsafeRequest)
+	}
+	for _, length := range lengths {
+		if strings.TrimSpace(length) != strings.TrimSpace(lengths[0]) {
+			return fmt.Errorf("%w: %w: conflicting content length", ErrNonRetryable, ErrUnsafeRequest)
+		}
+	}
+	return nil
+}
+
+// checkMethod rejects methods that are not in the allowed methods, if any, or
+// that are in the denied methods.
+func (client *Client) checkMethod(method string) (err error) {
+	// Normalize method
+	if method == "" {
+		method = http.MethodGet
+	}
+
+	// Check for denied method
+	for _, denied := range client.DeniedMethods {
+		if strings.EqualFold(denied, method) {
+			return fmt.Errorf("%w: %w (%s)", ErrNonRetryable, ErrMethodNotAllowed, method)
+		}
+	}
+
+	// Check for allowed method
+	if len(client.AllowedMethods) == 0 {
+		return nil
+	}
+	for _, allowed := range client.AllowedMethods {
+		if strings.EqualFold(allowed, method) {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: %w (%s)", ErrNonRetryable, ErrMethodNotAllowed, method)
+}
+
+// equalHost compares two hosts, ignoring case and the default port for the
+// specified scheme.
+func equalHost(scheme string, first string, second string) (equal bool) {
+	return normalizeHost(scheme, first) == normalizeHost(scheme, second)
+}
+
+// normalizeHost converts the host to lowercase and removes the default port
+// for the specified scheme.
+func normalizeHost(scheme string, host string) (normalized string) {
+	host = strings.ToLower(host)
+	hostname, port, err := net.SplitHostPort(host)
+	if err != nil {
+		return strings.TrimSuffix(strings.TrimPrefix(host, "["), "]")
+	}
+	if (scheme == "http" && port == "80") || (scheme == "https" && port == "443") {
+		return hostname
+	}
+	return net.JoinHostPort(hostname, port)
+}
+
+// isToken reports whether the value is a valid token as defined by RFC 9110.
+func isToken(value string) (valid bool) {
+	if value == "" {
+		return false
+	}
+	for _, char := range value {
+		if char >= 0x7F || char <= ' ' || strings.ContainsRune("\"(),/:;<=>?@[\\]{}", char) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,108 @@
+package retryable
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestClient_ValidateRequest(test *testing.T) {
+	test.Parallel()
+
+	client := new(Client)
+	err := client.validateRequest(nil)
+	require.NoError(test, err)
+
+	err = client.validateRequest(new(http.Request))
+	require.NoError(test, err)
+
+	request, err := http.NewRequest(http.MethodGet, "https://www.github.com/", nil)
+	require.NoError(test, err)
+	err = client.validateRequest(request)
+	require.NoError(test, err)
+
+	request.Host = "WWW.GITHUB.COM:443"
+	err = client.validateRequest(request)
+	require.NoError(test, err)
+
+	request.Host = "www.example.com"
+	err = client.validateRequest(request)
+	require.ErrorIs(test, err, ErrNonRetryable)
+	require.ErrorIs(test, err, ErrUnsafeRequest)
+
+	client.AllowHostOverride = true
+	err = client.validateRequest(request)
+	require.NoError(test, err)
+
+	client.AllowHostOverride = false
+	request.Host = ""
+	request.Header.Set("Host", "www.example.com")
+	err = client.validateRequest(request)
+	require.ErrorIs(test, err, ErrUnsafeRequest)
+
+	request.Header.Del("Host")
+	request.Header.Set("X-Forwarded-For", "127.0.0.1\r\nContent-Length: 0")
+	err = client.validateRequest(request)
+	require.ErrorIs(test, err, ErrUnsafeRequest)
+
+	request.Header = http.Header{"X Forwarded For": {"127.0.0.1"}}
+	err = client.validateRequest(request)
+	require.ErrorIs(test, err, ErrUnsafeRequest)
+
+	request.Header = http.Header{"Content-Length": {"1", "2"}}
+	err = client.validateRequest(request)
+	require.ErrorIs(test, err, ErrUnsafeRequest)
+
+	request.Header = http.Header{"Content-Length": {"1"}, "Transfer-Encoding": {"chunked"}}
+	err = client.validateRequest(request)
+	require.ErrorIs(test, err, ErrUnsafeRequest)
+
+	request.Header = http.Header{"Content-Length": {"1"}}
+	err = client.validateRequest(request)
+	require.NoError(test, err)
+
+	request.Method = "GET /admin"
+	err = client.validateRequest(request)
+	require.ErrorIs(test, err, ErrUnsafeRequest)
+
+	request.Method = http.MethodGet
+	request.URL.Host = "www.github.com\r\n"
+	err = client.validateRequest(request)
+	require.ErrorIs(test, err, ErrUnsafeRequest)
+}
+
+func TestClient_CheckMethod(test *testing.T) {
+	test.Parallel()
+
+	client := new(Client)
+	require.NoError(test, client.checkMethod(http.MethodDelete))
+
+	client.AllowedMethods = []string{http.MethodGet, http.MethodHead}
+	require.NoError(test, client.checkMethod(""))
+	require.NoError(test, client.checkMethod("head"))
+	err := client.checkMethod(http.MethodPost)
+	require.ErrorIs(test, err, ErrMethodNotAllowed)
+	require.ErrorIs(test, err, ErrNonRetryable)
+	require.ErrorContains(test, err, "POST")
+
+	client.AllowedMethods = nil
+	client.DeniedMethods = []string{http.MethodDelete}
+	require.NoError(test, client.checkMethod(http.MethodPost))
+	require.ErrorIs(test, client.checkMethod(http.MethodDelete), ErrMethodNotAllowed)
+
+	request, err := http.NewRequest(http.MethodDelete, "http://127.0.0.1:1/", nil)
+	require.NoError(test, err)
+	_, err = client.Do(request)
+	require.ErrorIs(test, err, ErrMethodNotAllowed)
+}
+
+func TestNormalizeHost(test *testing.T) {
+	test.Parallel()
+
+	require.Equal(test, "www.github.com", normalizeHost("https", "WWW.GITHUB.COM:443"))
+	require.Equal(test, "www.github.com:80", normalizeHost("https", "www.github.com:80"))
+	require.Equal(test, "www.github.com", normalizeHost("http", "www.github.com:80"))
+	require.Equal(test, "::1", normalizeHost("http", "[::1]:80"))
+	require.Equal(test, "::1", normalizeHost("http", "[::1]"))
+}
@@ -0,0 +1,125 @@
+package retrytest
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+)
+
+// Attempt defines a request received by a [Server] or [Transport].
+type Attempt struct {
+	// Time specifies when the request was received.
+	Time time.Time
+
+	// Method specifies the request method.
+	Method string
+
+	// URL specifies the request URI.
+	URL string
+
+	// Header specifies the request headers.
+	Header http.Header
+
+	// Body specifies the request body.
+	Body []byte
+}
+
+// recorder selects the next step of a script, and records each attempt.
+type recorder struct {
+	mutex    sync.Mutex
+	steps    []Step
+	attempts []Attempt
+}
+
+// Attempts returns the recorded attempts.
+func (recorder *recorder) Attempts() []Attempt {
+	recorder.mutex.Lock()
+	defer recorder.mutex.Unlock()
+	return append([]Attempt(nil), recorder.attempts...)
+}
+
+// Intervals returns the duration between the start of each recorded attempt
+// and the previous attempt.
+func (recorder *recorder) Intervals() (intervals []time.Duration) {
+	attempts := recorder.Attempts()
+	for index := 1; index < len(attempts); index++ {
+		intervals = append(intervals, attempts[index].Time.Sub(attempts[index-1].Time))
+	}
+	return intervals
+}
+
+// AssertAttempts reports a test failure if the number of recorded attempts
+// does not match the expected count.
+func (recorder *recorder) AssertAttempts(test testing.TB, count int) bool {
+	test.Helper()
+	attempts := recorder.Attempts()
+	if len(attempts) != count {
+		test.Errorf("expected %d attempts, received %d", count, len(attempts))
+		return false
+	}
+	return true
+}
+
+// AssertBodies reports a test failure if the request bodies of the recorded
+// attempts do not match the expected bodies.
+func (recorder *recorder) AssertBodies(test testing.TB, bodies ...string) bool {
+	test.Helper()
+	attempts := recorder.Attempts()
+	if len(attempts) != len(bodies) {
+		test.Errorf("expected %d bodies, received %d", len(bodies), len(attempts))
+		return false
+	}
+	for index, attempt := range attempts {
+		if !bytes.Equal(attempt.Body, []byte(bodies[index])) {
+			test.Errorf("expected body %q for attempt %d, received %q", bodies[index], index, attempt.Body)
+			return false
+		}
+	}
+	return true
+}
+
+// record records the request as an attempt, and returns the next step. Once
+// the script is exhausted, a successful step is returned.
+func (recorder *recorder) record(request *http.Request) (step Step) {
+	// Read request body
+	var body []byte
+	if request.Body != nil {
+		body, _ = io.ReadAll(request.Body)
+	}
+
+	// Record attempt and select step
+	recorder.mutex.Lock()
+	defer recorder.mutex.Unlock()
+	recorder.attempts = append(recorder.attempts, Attempt{
+		Time:   time.Now(),
+		Method: request.Method,
+		URL:    request.URL.RequestURI(),
+		Header: request.Header.Clone(),
+		Body:   body,
+	})
+	if len(recorder.steps) == 0 {
+		return Succeed(nil)
+	}
+	step = recorder.steps[0]
+	recorder.steps = recorder.steps[1:]
+	return step
+}
+
+// delay waits for the specified duration, returning false if the request
+// context is canceled.
+func delay(request *http.Request, duration time.Duration) bool {
+	if duration <= 0 {
+		return true
+	}
+	timer := time.NewTimer(duration)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return true
+	case <-request.Context().Done():
+		return false
+	}
+}
@@ -0,0 +1,140 @@
+// Package retrytest provides utilities for testing retryable HTTP clients,
+// such as a scriptable fault-injection test server and transport.
+package retrytest
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Step defines the response to a single attempt.
+type Step struct {
+	// StatusCode specifies the status code of the response. If the status code
+	// is zero, [net/http.StatusOK] is used.
+	StatusCode int
+
+	// Header specifies additional headers of the response.
+	Header http.Header
+
+	// Body specifies the body of the response.
+	Body []byte
+
+	// Delay specifies how long to wait before responding.
+	Delay time.Duration
+
+	// Drop specifies whether the connection is closed after writing half of
+	// the response body, resulting in an unexpected EOF for the client.
+	Drop bool
+
+	// Err specifies an error that fails the attempt without a response. A
+	// [Transport] returns the error, and a [Server] closes the connection.
+	Err error
+}
+
+// Fail returns a step that responds with the specified status code.
+func Fail(status int) Step {
+	return Step{StatusCode: status, Header: nil, Body: nil, Delay: 0, Drop: false, Err: nil}
+}
+
+// RetryAfter returns a step that responds with the specified status code, and
+// a Retry-After header with the specified delay in seconds.
+func RetryAfter(status int, duration time.Duration) Step {
+	step := Fail(status)
+	step.Header = http.Header{"Retry-After": {strconv.FormatInt(int64(duration/time.Second), 10)}}
+	return step
+}
+
+// Drop returns a step that closes the connection in the middle of the
+// response body.
+func Drop(body []byte) Step {
+	return Step{StatusCode: http.StatusOK, Header: nil, Body: body, Delay: 0, Drop: true, Err: nil}
+}
+
+// Succeed returns a step that responds with a status code of
+// [net/http.StatusOK] and the specified body.
+func Succeed(body []byte) Step {
+	return Step{StatusCode: http.StatusOK, Header: nil, Body: body, Delay: 0, Drop: false, Err: nil}
+}
+
+// Error returns a step that fails with the specified error.
+func Error(err error) Step {
+	return Step{StatusCode: 0, Header: nil, Body: nil, Delay: 0, Drop: false, Err: err}
+}
+
+// Failures returns a script of the specified number of failed steps with the
+// specified status code, followed by a successful step.
+func Failures(count int, status int) (steps []Step) {
+	for index := 0; index < count; index++ {
+		steps = append(steps, Fail(status))
+	}
+	return append(steps, Succeed(nil))
+}
+
+// Server is a test HTTP server that responds to each request with the next
+// step of a script, and records each attempt. Once the script is exhausted,
+// the server responds with a status code of [net/http.StatusOK].
+type Server struct {
+	// Server specifies the underlying test server.
+	*httptest.Server
+
+	recorder
+}
+
+// NewServer starts and returns a new test server that responds with the
+// specified steps. The caller should call Close when finished.
+func NewServer(steps ...Step) *Server {
+	server := &Server{Server: nil, recorder: recorder{mutex: sync.Mutex{}, steps: steps, attempts: nil}}
+	server.Server = httptest.NewServer(http.HandlerFunc(server.serveHTTP))
+	return server
+}
+
+// serveHTTP records the attempt and responds with the next step.
+func (server *Server) serveHTTP(writer http.ResponseWriter, request *http.Request) {
+	// Record attempt and delay response
+	step := server.record(request)
+	if !delay(request, step.Delay) {
+		return
+	}
+
+	// Close connection without response
+	if step.Err != nil {
+		closeConnection(writer)
+		return
+	}
+
+	// Write response headers
+	for name, values := range step.Header {
+		writer.Header()[name] = values
+	}
+	if step.StatusCode == 0 {
+		step.StatusCode = http.StatusOK
+	}
+	writer.Header().Set("Content-Length", strconv.Itoa(len(step.Body)))
+	writer.WriteHeader(step.StatusCode)
+
+	// Write response body
+	if !step.Drop {
+		_, _ = writer.Write(step.Body)
+		return
+	}
+	_, _ = io.Copy(writer, bytes.NewReader(step.Body[:len(step.Body)/2]))
+	if flusher, ok := writer.(http.Flusher); ok {
+		flusher.Flush()
+	}
+	closeConnection(writer)
+}
+
+// closeConnection closes the underlying connection of the response writer.
+func closeConnection(writer http.ResponseWriter) {
+	if hijacker, ok := writer.(http.Hijacker); ok {
+		connection, _, err := hijacker.Hijack()
+		if err == nil {
+			_ = connection.Close()
+		}
+	}
+}
@@ -0,0 +1,94 @@
+package retrytest
+
+import (
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cholland1989/go-retryable/pkg/retryable"
+	"github.com/stretchr/testify/require"
+)
+
+func TestServer(test *testing.T) {
+	test.Parallel()
+
+	server := NewServer(Failures(2, http.StatusServiceUnavailable)...)
+	defer server.Close()
+
+	client := new(retryable.Client)
+	client.RetryCount = 2
+	client.RetryDelay = time.Millisecond
+	client.RetryStatus = []int{http.StatusServiceUnavailable}
+	response, err := client.Post(server.URL+"/path?x=y", "text/plain", strings.NewReader("xyz"))
+	require.NoError(test, err)
+	require.Equal(test, http.StatusOK, response.StatusCode)
+
+	attempts := server.Attempts()
+	require.Len(test, attempts, 3)
+	require.Equal(test, http.MethodPost, attempts[0].Method)
+	require.Equal(test, "/path?x=y", attempts[0].URL)
+	require.Equal(test, "text/plain", attempts[0].Header.Get("Content-Type"))
+	require.Equal(test, []byte("xyz"), attempts[2].Body)
+
+	intervals := server.Intervals()
+	require.Len(test, intervals, 2)
+	require.GreaterOrEqual(test, intervals[0], time.Millisecond)
+}
+
+func TestServer_RetryAfter(test *testing.T) {
+	test.Parallel()
+
+	server := NewServer(RetryAfter(http.StatusTooManyRequests, time.Second))
+	defer server.Close()
+
+	response, err := http.Get(server.URL)
+	require.NoError(test, err)
+	defer response.Body.Close()
+	require.Equal(test, http.StatusTooManyRequests, response.StatusCode)
+	require.Equal(test, "1", response.Header.Get("Retry-After"))
+
+	response, err = http.Get(server.URL)
+	require.NoError(test, err)
+	defer response.Body.Close()
+	require.Equal(test, http.StatusOK, response.StatusCode)
+}
+
+func TestServer_Drop(test *testing.T) {
+	test.Parallel()
+
+	server := NewServer(Drop([]byte("xyz")), Succeed([]byte("xyz")))
+	defer server.Close()
+
+	response, err := http.Get(server.URL)
+	require.NoError(test, err)
+	defer response.Body.Close()
+	_, err = io.ReadAll(response.Body)
+	require.ErrorIs(test, err, io.ErrUnexpectedEOF)
+
+	client := new(retryable.Client)
+	client.RetryCount = 1
+	server = NewServer(Drop([]byte("xyz")), Succeed([]byte("xyz")))
+	defer server.Close()
+	response, err = client.Get(server.URL)
+	require.NoError(test, err)
+	buffer, err := io.ReadAll(response.Body)
+	require.NoError(test, err)
+	require.Equal(test, "xyz", string(buffer))
+}
+
+func TestServer_Delay(test *testing.T) {
+	test.Parallel()
+
+	step := Succeed(nil)
+	step.Delay = 10 * time.Millisecond
+	server := NewServer(step)
+	defer server.Close()
+
+	timestamp := time.Now()
+	response, err := http.Get(server.URL)
+	require.NoError(test, err)
+	defer response.Body.Close()
+	require.GreaterOrEqual(test, time.Since(timestamp), 10*time.Millisecond)
+}
@@ -0,0 +1,78 @@
+package retrytest
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+)
+
+// Transport is an [net/http.RoundTripper] that responds to each request with
+// the next step of a script without network access, and records each
+// attempt. Once the script is exhausted, the transport responds with a status
+// code of [net/http.StatusOK].
+type Transport struct {
+	recorder
+}
+
+// NewTransport returns a new transport that responds with the specified
+// steps.
+func NewTransport(steps ...Step) *Transport {
+	return &Transport{recorder: recorder{mutex: sync.Mutex{}, steps: steps, attempts: nil}}
+}
+
+// RoundTrip records the request, and returns the response or error defined by
+// the next step.
+func (transport *Transport) RoundTrip(request *http.Request) (response *http.Response, err error) {
+	// Record attempt and delay response
+	step := transport.record(request)
+	if request.Body != nil {
+		_ = request.Body.Close()
+	}
+	if !delay(request, step.Delay) {
+		return nil, fmt.Errorf("retrytest: %w", request.Context().Err())
+	}
+
+	// Fail without response
+	if step.Err != nil {
+		return nil, step.Err
+	}
+
+	// Construct response
+	status := step.StatusCode
+	if status == 0 {
+		status = http.StatusOK
+	}
+	header := step.Header.Clone()
+	if header == nil {
+		header = make(http.Header)
+	}
+	header.Set("Content-Length", strconv.Itoa(len(step.Body)))
+	body := io.Reader(bytes.NewReader(step.Body))
+	if step.Drop {
+		body = io.MultiReader(bytes.NewReader(step.Body[:len(step.Body)/2]), errorReader{io.ErrUnexpectedEOF})
+	}
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", status, http.StatusText(status)),
+		StatusCode:    status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(body),
+		ContentLength: int64(len(step.Body)),
+		Request:       request,
+	}, nil
+}
+
+// errorReader is an [io.Reader] that always returns the specified error.
+type errorReader struct {
+	err error
+}
+
+// Read returns the error of the reader.
+func (reader errorReader) Read(_ []byte) (int, error) {
+	return 0, reader.err
+}
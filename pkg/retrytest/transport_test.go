@@ -0,0 +1,87 @@
+package retrytest
+
+import (
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cholland1989/go-retryable/pkg/retryable"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTransport(test *testing.T) {
+	test.Parallel()
+
+	for name, params := range map[string]struct {
+		steps    []Step
+		attempts int
+		status   int
+		err      error
+	}{
+		"Success":        {nil, 1, http.StatusOK, nil},
+		"Failures":       {Failures(2, http.StatusBadGateway), 3, http.StatusOK, nil},
+		"Exhausted":      {Failures(3, http.StatusBadGateway), 3, http.StatusBadGateway, retryable.ErrRetryable},
+		"Non-Retryable":  {[]Step{Fail(http.StatusNotFound)}, 1, http.StatusNotFound, retryable.ErrNonRetryable},
+		"Transport":      {[]Step{Error(io.ErrClosedPipe)}, 2, http.StatusOK, nil},
+		"Dropped Body":   {[]Step{Drop([]byte("xyz"))}, 2, http.StatusOK, nil},
+		"Transport Fail": {[]Step{Error(io.ErrClosedPipe), Error(io.ErrClosedPipe), Error(io.ErrClosedPipe)}, 3, 0, io.ErrClosedPipe},
+	} {
+		params := params
+		test.Run(name, func(test *testing.T) {
+			test.Parallel()
+
+			transport := NewTransport(params.steps...)
+			client := new(retryable.Client)
+			client.Transport = transport
+			client.RetryCount = 2
+			client.RetryStatus = []int{http.StatusBadGateway}
+			response, err := client.Post("http://example.com/", "text/plain", strings.NewReader("xyz"))
+			if params.err == nil {
+				require.NoError(test, err)
+			} else {
+				require.ErrorIs(test, err, params.err)
+			}
+			if params.status != 0 {
+				require.Equal(test, params.status, response.StatusCode)
+			}
+			require.True(test, transport.AssertAttempts(test, params.attempts))
+			bodies := make([]string, params.attempts)
+			for index := range bodies {
+				bodies[index] = "xyz"
+			}
+			require.True(test, transport.AssertBodies(test, bodies...))
+		})
+	}
+}
+
+func TestTransport_Assertions(test *testing.T) {
+	test.Parallel()
+
+	transport := NewTransport()
+	mock := new(testing.T)
+	require.False(test, transport.AssertAttempts(mock, 1))
+	require.False(test, transport.AssertBodies(mock, "xyz"))
+
+	request, err := http.NewRequest(http.MethodPost, "http://example.com/", strings.NewReader("abc"))
+	require.NoError(test, err)
+	response, err := transport.RoundTrip(request)
+	require.NoError(test, err)
+	require.NoError(test, response.Body.Close())
+	require.False(test, transport.AssertBodies(mock, "xyz"))
+	require.True(test, transport.AssertBodies(test, "abc"))
+}
+
+func TestTransport_Delay(test *testing.T) {
+	test.Parallel()
+
+	step := Succeed(nil)
+	step.Delay = time.Second
+	transport := NewTransport(step)
+	client := new(retryable.Client)
+	client.Transport = transport
+	client.RequestTimeout = time.Millisecond
+	_, err := client.Get("http://example.com/")
+	require.ErrorIs(test, err, retryable.ErrNonRetryable)
+}
@@ -0,0 +1,223 @@
+// Package sigv4 signs HTTP requests with AWS Signature Version 4, so that
+// each attempt of a retryable request can be re-signed with a fresh date
+// before the signature expires.
+package sigv4
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/cholland1989/go-retryable/pkg/retryable"
+)
+
+// ErrSign defines an error for requests that could not be signed.
+var ErrSign = errors.New("unable to sign request")
+
+// algorithm is the signing algorithm of Signature Version 4.
+const algorithm = "AWS4-HMAC-SHA256"
+
+// Signer signs HTTP requests with AWS Signature Version 4. It implements
+// [retryable.Signer], so that each attempt is re-signed.
+type Signer struct {
+	// AccessKeyID specifies the access key ID of the credentials.
+	AccessKeyID string
+
+	// SecretAccessKey specifies the secret access key of the credentials.
+	SecretAccessKey string
+
+	// SessionToken specifies the session token of temporary credentials, if
+	// any.
+	SessionToken string
+
+	// Region specifies the region of the service, such as "us-east-1".
+	Region string
+
+	// Service specifies the signing name of the service, such as "s3".
+	Service string
+
+	// Clock specifies the time source for the signing date. If the clock is
+	// nil, the system time is used.
+	Clock retryable.Clock
+}
+
+// PrepareAttempt signs the request, and can be used as the
+// [retryable.Client.PrepareAttempt] hook so that each attempt is re-signed.
+func (signer *Signer) PrepareAttempt(_ int, request *http.Request) (err error) {
+	return signer.Sign(request)
+}
+
+// Sign adds the X-Amz-Date, X-Amz-Security-Token, and Authorization headers
+// to the request, replacing any previous signature. The host, the content
+// type, and all X-Amz-* headers are signed. The request body is read with
+// GetBody, unless the X-Amz-Content-Sha256 header is already present.
+func (signer *Signer) Sign(request *http.Request) (err error) {
+	// Determine signing date
+	now := time.Now()
+	if signer.Clock != nil {
+		now = signer.Clock.Now()
+	}
+	now = now.UTC()
+	date := now.Format("20060102")
+
+	// Add date and session token headers
+	request.Header.Del("Authorization")
+	request.Header.Set("X-Amz-Date", now.Format("20060102T150405Z"))
+	if signer.SessionToken != "" {
+		request.Header.Set("X-Amz-Security-Token", signer.SessionToken)
+	}
+
+	// Hash request body
+	payload := request.Header.Get("X-Amz-Content-Sha256")
+	if payload == "" {
+		payload, err = hashBody(request)
+		if err != nil {
+			return fmt.Errorf("%w: %w", ErrSign, err)
+		}
+		if signer.Service == "s3" {
+			request.Header.Set("X-Amz-Content-Sha256", payload)
+		}
+	}
+
+	// Construct canonical request
+	headers, signed := canonicalHeaders(request)
+	canonical := strings.Join([]string{
+		request.Method,
+		canonicalPath(request, signer.Service != "s3"),
+		canonicalQuery(request),
+		headers,
+		signed,
+		payload,
+	}, "\n")
+
+	// Construct string to sign
+	scope := strings.Join([]string{date, signer.Region, signer.Service, "aws4_request"}, "/")
+	digest := sha256.Sum256([]byte(canonical))
+	text := strings.Join([]string{algorithm, now.Format("20060102T150405Z"), scope, hex.EncodeToString(digest[:])}, "\n")
+
+	// Calculate signature
+	key := hmacSHA256([]byte("AWS4"+signer.SecretAccessKey), date)
+	key = hmacSHA256(key, signer.Region)
+	key = hmacSHA256(key, signer.Service)
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, text))
+
+	// Add authorization header
+	request.Header.Set("Authorization", fmt.Sprintf("%s Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		algorithm, signer.AccessKeyID, scope, signed, signature))
+	return nil
+}
+
+// hashBody returns the hex-encoded SHA-256 hash of the request body.
+func hashBody(request *http.Request) (hash string, err error) {
+	digest := sha256.New()
+	if request.GetBody != nil {
+		body, err := request.GetBody()
+		if err != nil {
+			return "", err
+		}
+		defer func(body io.Closer) {
+			_ = body.Close()
+		}(body)
+		_, err = io.Copy(digest, body)
+		if err != nil {
+			return "", err
+		}
+	} else if request.Body != nil && request.Body != http.NoBody {
+		return "", errors.New("request body cannot be reset")
+	}
+	return hex.EncodeToString(digest.Sum(nil)), nil
+}
+
+// canonicalPath returns the URI-encoded path of the request. Services other
+// than S3 encode each path segment twice.
+func canonicalPath(request *http.Request, twice bool) (path string) {
+	path = request.URL.Path
+	if path == "" {
+		return "/"
+	}
+	segments := strings.Split(path, "/")
+	for index, segment := range segments {
+		segment = encode(segment)
+		if twice {
+			segment = encode(segment)
+		}
+		segments[index] = segment
+	}
+	return strings.Join(segments, "/")
+}
+
+// canonicalQuery returns the URI-encoded query parameters of the request,
+// sorted by name and value.
+func canonicalQuery(request *http.Request) (query string) {
+	var params []string
+	for name, values := range request.URL.Query() {
+		for _, value := range values {
+			params = append(params, encode(name)+"="+encode(value))
+		}
+	}
+	sort.Strings(params)
+	return strings.Join(params, "&")
+}
+
+// canonicalHeaders returns the canonical headers and the signed header names
+// of the request.
+func canonicalHeaders(request *http.Request) (headers string, signed string) {
+	// Select headers to sign
+	host := request.Host
+	if host == "" {
+		host = request.URL.Host
+	}
+	values := map[string]string{"host": host}
+	for name, value := range request.Header {
+		name = strings.ToLower(name)
+		if name == "content-type" || strings.HasPrefix(name, "x-amz-") {
+			trimmed := make([]string, len(value))
+			for index := range value {
+				trimmed[index] = strings.Join(strings.Fields(value[index]), " ")
+			}
+			values[name] = strings.Join(trimmed, ",")
+		}
+	}
+
+	// Sort headers by name
+	names := make([]string, 0, len(values))
+	for name := range values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var builder strings.Builder
+	for _, name := range names {
+		builder.WriteString(name + ":" + values[name] + "\n")
+	}
+	return builder.String(), strings.Join(names, ";")
+}
+
+// encode URI-encodes every byte of the text except unreserved characters.
+func encode(text string) (encoded string) {
+	var builder strings.Builder
+	for index := 0; index < len(text); index++ {
+		char := text[index]
+		if ('A' <= char && char <= 'Z') || ('a' <= char && char <= 'z') || ('0' <= char && char <= '9') ||
+			char == '-' || char == '_' || char == '.' || char == '~' {
+			builder.WriteByte(char)
+		} else {
+			fmt.Fprintf(&builder, "%%%02X", char)
+		}
+	}
+	return builder.String()
+}
+
+// hmacSHA256 returns the HMAC-SHA256 of the text with the key.
+func hmacSHA256(key []byte, text string) (sum []byte) {
+	mac := hmac.New(sha256.New, key)
+	_, _ = mac.Write([]byte(text))
+	return mac.Sum(nil)
+}
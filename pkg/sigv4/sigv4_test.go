@@ -0,0 +1,115 @@
+package sigv4
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/cholland1989/go-retryable/pkg/retryable"
+	"github.com/stretchr/testify/require"
+)
+
+type fixedClock struct {
+	mutex sync.Mutex
+	now   time.Time
+}
+
+func (clock *fixedClock) Now() time.Time {
+	clock.mutex.Lock()
+	defer clock.mutex.Unlock()
+	return clock.now
+}
+
+func (clock *fixedClock) Sleep(_ context.Context, duration time.Duration) error {
+	clock.mutex.Lock()
+	defer clock.mutex.Unlock()
+	clock.now = clock.now.Add(duration)
+	return nil
+}
+
+func newSigner(service string) *Signer {
+	return &Signer{
+		AccessKeyID:     "AKIDEXAMPLE",
+		SecretAccessKey: "wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY",
+		Region:          "us-east-1",
+		Service:         service,
+		Clock:           &fixedClock{now: time.Date(2015, time.August, 30, 12, 36, 0, 0, time.UTC)},
+	}
+}
+
+func TestSigner_Sign(test *testing.T) {
+	test.Parallel()
+
+	request, err := http.NewRequest(http.MethodGet, "https://example.amazonaws.com/", nil)
+	require.NoError(test, err)
+	err = newSigner("service").Sign(request)
+	require.NoError(test, err)
+	require.Equal(test, "20150830T123600Z", request.Header.Get("X-Amz-Date"))
+	require.Equal(test, "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20150830/us-east-1/service/aws4_request, "+
+		"SignedHeaders=host;x-amz-date, "+
+		"Signature=5fa00fa31553b73ebf1942676e86291e8372ff2a2260956d9b8aae1d763fbf31", request.Header.Get("Authorization"))
+
+	request, err = http.NewRequest(http.MethodGet, "https://iam.amazonaws.com/?Version=2010-05-08&Action=ListUsers", nil)
+	require.NoError(test, err)
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded; charset=utf-8")
+	err = newSigner("iam").Sign(request)
+	require.NoError(test, err)
+	require.Equal(test, "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20150830/us-east-1/iam/aws4_request, "+
+		"SignedHeaders=content-type;host;x-amz-date, "+
+		"Signature=5d672d79c15b13162d9279b0855cfba6789a8edb4c82c400e06b5924a6f2b5d7", request.Header.Get("Authorization"))
+}
+
+func TestSigner_SignBody(test *testing.T) {
+	test.Parallel()
+
+	signer := newSigner("s3")
+	signer.SessionToken = "token"
+	request, err := http.NewRequest(http.MethodPut, "https://bucket.s3.amazonaws.com/a b", strings.NewReader("body"))
+	require.NoError(test, err)
+	err = signer.Sign(request)
+	require.NoError(test, err)
+	require.Equal(test, "230d8358dc8e8890b4c58deeb62912ee2f20357ae92a5cc861b98e68fe31acb5", request.Header.Get("X-Amz-Content-Sha256"))
+	require.Equal(test, "token", request.Header.Get("X-Amz-Security-Token"))
+	require.Contains(test, request.Header.Get("Authorization"), "SignedHeaders=host;x-amz-content-sha256;x-amz-date;x-amz-security-token,")
+
+	request.GetBody = nil
+	request.Header.Del("X-Amz-Content-Sha256")
+	err = signer.Sign(request)
+	require.ErrorIs(test, err, ErrSign)
+
+	require.Equal(test, "/a%2520b", canonicalPath(request, true))
+	require.Equal(test, "/a%20b", canonicalPath(request, false))
+}
+
+func TestSigner_PrepareAttempt(test *testing.T) {
+	test.Parallel()
+
+	var dates []string
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		dates = append(dates, request.Header.Get("X-Amz-Date"))
+		writer.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	for _, hook := range []bool{true, false} {
+		dates = nil
+		signer := newSigner("service")
+		client := new(retryable.Client)
+		client.Clock = signer.Clock
+		client.RetryCount = 2
+		client.RetryDelay = 10 * time.Minute
+		client.RetryStatus = []int{http.StatusServiceUnavailable}
+		if hook {
+			client.PrepareAttempt = signer.PrepareAttempt
+		} else {
+			client.Signer = signer
+		}
+		_, err := client.Get(server.URL)
+		require.ErrorIs(test, err, retryable.ErrRetryable)
+		require.Equal(test, []string{"20150830T123600Z", "20150830T124600Z", "20150830T125600Z"}, dates)
+	}
+}
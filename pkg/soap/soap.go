@@ -0,0 +1,121 @@
+// Package soap provides a response check for SOAP endpoints, which retries
+// SOAP faults that signal a transient failure even when they are returned
+// with a successful status code.
+package soap
+
+import (
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/cholland1989/go-retryable/pkg/retryable"
+)
+
+// DefaultRetryCodes contains the default fault codes that are retryable,
+// which are soap:Server for SOAP 1.1 and soap:Receiver for SOAP 1.2.
+var DefaultRetryCodes = []string{"Server", "Receiver"}
+
+// Fault defines a SOAP 1.1 or SOAP 1.2 fault.
+type Fault struct {
+	// Code specifies the fault code without the namespace prefix, such as
+	// Server or Client.
+	Code string
+
+	// Message specifies the human-readable description of the fault.
+	Message string
+
+	// Actor specifies the node that caused the fault, if any.
+	Actor string
+}
+
+// Error returns the code and the description of the fault.
+func (fault *Fault) Error() string {
+	return fmt.Sprintf("soap: %s: %s", fault.Code, fault.Message)
+}
+
+// envelope defines a SOAP envelope, matching elements of any namespace so
+// that both SOAP 1.1 and SOAP 1.2 are supported.
+type envelope struct {
+	// Body specifies the body of the envelope.
+	Body struct {
+		// Fault specifies the fault of the body, if any.
+		Fault *fault `xml:"Fault"`
+	} `xml:"Body"`
+}
+
+// fault defines the elements of a SOAP 1.1 or SOAP 1.2 fault.
+type fault struct {
+	// FaultCode specifies the SOAP 1.1 fault code.
+	FaultCode string `xml:"faultcode"`
+
+	// FaultString specifies the SOAP 1.1 fault description.
+	FaultString string `xml:"faultstring"`
+
+	// FaultActor specifies the SOAP 1.1 fault actor.
+	FaultActor string `xml:"faultactor"`
+
+	// Code specifies the SOAP 1.2 fault code.
+	Code string `xml:"Code>Value"`
+
+	// Reason specifies the SOAP 1.2 fault descriptions.
+	Reason []string `xml:"Reason>Text"`
+
+	// Role specifies the SOAP 1.2 fault role.
+	Role string `xml:"Role"`
+}
+
+// ParseFault returns the fault of the SOAP envelope, or nil if the envelope
+// does not contain a fault. It returns an error if the body is not a SOAP
+// envelope.
+func ParseFault(body []byte) (result *Fault, err error) {
+	// Decode envelope
+	var message envelope
+	err = xml.Unmarshal(body, &message)
+	if err != nil {
+		return nil, fmt.Errorf("soap: unable to decode envelope: %w", err)
+	}
+	if message.Body.Fault == nil {
+		return nil, nil
+	}
+
+	// Normalize SOAP 1.1 and SOAP 1.2 faults
+	raw := message.Body.Fault
+	result = &Fault{Code: raw.FaultCode, Message: raw.FaultString, Actor: raw.FaultActor}
+	if raw.Code != "" {
+		result.Code = raw.Code
+		result.Message = strings.Join(raw.Reason, "; ")
+		result.Actor = raw.Role
+	}
+	result.Code = strings.TrimSpace(result.Code)
+	if index := strings.LastIndex(result.Code, ":"); index >= 0 {
+		result.Code = result.Code[index+1:]
+	}
+	result.Message = strings.TrimSpace(result.Message)
+	return result, nil
+}
+
+// FaultCheck returns a response check that retries SOAP faults with the
+// specified codes, or [DefaultRetryCodes] if no codes are specified. Dotted
+// subcodes, such as Server.Timeout, match their parent code. Other faults are
+// not retried, and responses that are not SOAP envelopes are accepted.
+func FaultCheck(codes ...string) retryable.ResponseCheck {
+	if len(codes) == 0 {
+		codes = DefaultRetryCodes
+	}
+	return func(_ *http.Response, body []byte) (err error) {
+		// Check for fault, leaving invalid responses to the caller
+		fault, err := ParseFault(body)
+		if err != nil || fault == nil {
+			return nil
+		}
+
+		// Check for retryable fault code
+		for _, code := range codes {
+			if fault.Code == code || strings.HasPrefix(fault.Code, code+".") {
+				return fmt.Errorf("%w: %w", retryable.ErrRetryable, fault)
+			}
+		}
+		return fmt.Errorf("%w: %w", retryable.ErrNonRetryable, fault)
+	}
+}
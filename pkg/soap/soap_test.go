@@ -0,0 +1,117 @@
+package soap
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/cholland1989/go-retryable/pkg/retryable"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	serverFault = `<?xml version="1.0"?>
+<soap:Envelope xmlns:soap="http://schemas.xmlsoap.org/soap/envelope/">
+  <soap:Body>
+    <soap:Fault>
+      <faultcode>soap:Server</faultcode>
+      <faultstring>Backend unavailable</faultstring>
+    </soap:Fault>
+  </soap:Body>
+</soap:Envelope>`
+
+	senderFault = `<env:Envelope xmlns:env="http://www.w3.org/2003/05/soap-envelope">
+  <env:Body>
+    <env:Fault>
+      <env:Code><env:Value>env:Sender</env:Value></env:Code>
+      <env:Reason><env:Text xml:lang="en">Invalid account</env:Text></env:Reason>
+      <env:Role>http://example.com/gateway</env:Role>
+    </env:Fault>
+  </env:Body>
+</env:Envelope>`
+
+	success = `<soap:Envelope xmlns:soap="http://schemas.xmlsoap.org/soap/envelope/">
+  <soap:Body><GetPriceResponse><Price>1.90</Price></GetPriceResponse></soap:Body>
+</soap:Envelope>`
+)
+
+func ExampleFaultCheck() {
+	client := &retryable.Client{RetryCount: 3}
+	client.CheckResponse = FaultCheck()
+	response, err := client.Post("https://example.com/soap", "text/xml; charset=utf-8", nil)
+	if err != nil {
+		var fault *Fault
+		if errors.As(err, &fault) {
+			fmt.Println(fault.Code, fault.Message)
+		}
+		return
+	}
+	defer response.Body.Close()
+}
+
+func TestFaultCheck(test *testing.T) {
+	test.Parallel()
+
+	var attempts atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		switch {
+		case request.URL.Path == "/sender":
+			_, _ = writer.Write([]byte(senderFault))
+		case attempts.Add(1) < 3:
+			_, _ = writer.Write([]byte(serverFault))
+		default:
+			_, _ = writer.Write([]byte(success))
+		}
+	}))
+	defer server.Close()
+
+	client := &retryable.Client{RetryCount: 5}
+	client.CheckResponse = FaultCheck()
+	response, err := client.Post(server.URL, "text/xml", nil)
+	require.NoError(test, err)
+	require.NoError(test, response.Body.Close())
+	require.Equal(test, int32(3), attempts.Load())
+
+	_, err = client.Post(server.URL+"/sender", "text/xml", nil)
+	require.ErrorIs(test, err, retryable.ErrNonRetryable)
+	var fault *Fault
+	require.ErrorAs(test, err, &fault)
+	require.Equal(test, "Sender", fault.Code)
+
+	check := FaultCheck("Server")
+	require.ErrorIs(test, check(nil, []byte(serverFault)), retryable.ErrRetryable)
+	require.ErrorIs(test, check(nil, []byte(senderFault)), retryable.ErrNonRetryable)
+	require.NoError(test, check(nil, []byte(success)))
+	require.NoError(test, check(nil, []byte("not xml")))
+}
+
+func TestParseFault(test *testing.T) {
+	test.Parallel()
+
+	fault, err := ParseFault([]byte(serverFault))
+	require.NoError(test, err)
+	require.Equal(test, &Fault{Code: "Server", Message: "Backend unavailable"}, fault)
+	require.Equal(test, "soap: Server: Backend unavailable", fault.Error())
+
+	fault, err = ParseFault([]byte(senderFault))
+	require.NoError(test, err)
+	require.Equal(test, &Fault{Code: "Sender", Message: "Invalid account", Actor: "http://example.com/gateway"}, fault)
+
+	fault, err = ParseFault([]byte(success))
+	require.NoError(test, err)
+	require.Nil(test, fault)
+
+	_, err = ParseFault([]byte("not xml"))
+	require.Error(test, err)
+}
+
+func TestFault_Subcode(test *testing.T) {
+	test.Parallel()
+
+	body := []byte(`<Envelope><Body><Fault><faultcode>Server.Timeout</faultcode></Fault></Body></Envelope>`)
+	require.ErrorIs(test, FaultCheck()(nil, body), retryable.ErrRetryable)
+	require.ErrorIs(test, FaultCheck("Client")(nil, body), retryable.ErrNonRetryable)
+}
@@ -0,0 +1,307 @@
+// Package sse provides a Server-Sent Events subscriber built on a retryable
+// HTTP client, which reconnects with exponential backoff, honors the retry
+// field of the event stream, and resumes from the last event ID.
+package sse
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cholland1989/go-delay/pkg/delay"
+	"github.com/cholland1989/go-retryable/pkg/retryable"
+)
+
+// ErrStreamingRequired defines an error for clients that read response bodies
+// into memory, which never completes for an event stream.
+var ErrStreamingRequired = errors.New("sse: client must stream responses")
+
+// Event defines an event of an event stream.
+type Event struct {
+	// ID specifies the last event ID of the stream when the event was
+	// dispatched.
+	ID string
+
+	// Type specifies the event type, or "message" if the stream did not
+	// specify one.
+	Type string
+
+	// Data specifies the data of the event, with multiple data lines joined
+	// by newlines.
+	Data string
+}
+
+// Subscriber subscribes to event streams with a retryable HTTP client.
+type Subscriber struct {
+	// Client specifies the retryable HTTP client used for each connection,
+	// which retries failed connection attempts according to its policy. The
+	// client must stream responses, and should not specify a request timeout,
+	// which would end long-lived streams. If the client is nil, a streaming
+	// client with three retries per connection is used.
+	Client *retryable.Client
+
+	// Header specifies additional request headers.
+	Header http.Header
+
+	// LastEventID specifies the initial last event ID sent in the
+	// Last-Event-ID header, to resume a previous subscription.
+	LastEventID string
+
+	// ReconnectDelay specifies the initial delay before reconnecting after
+	// the stream ends, which increases with the retry multiplier of the client
+	// until a connection delivers an event. If the stream specifies a retry
+	// field, that delay is used instead. If the delay is zero, the retry delay
+	// of the client is used.
+	ReconnectDelay time.Duration
+}
+
+// Subscription is an active subscription to an event stream.
+type Subscription struct {
+	// events contains the events of the stream.
+	events chan Event
+
+	// mutex guards access to the error.
+	mutex sync.Mutex
+
+	// err contains the error that ended the subscription.
+	err error
+}
+
+// Events returns the channel of events, which is closed when the context is
+// canceled or the subscription fails with a non-retryable error.
+func (subscription *Subscription) Events() <-chan Event {
+	return subscription.events
+}
+
+// Err returns the error that ended the subscription, once the channel of
+// events is closed. It returns nil if the server ended the subscription with
+// the 204 No Content status code.
+func (subscription *Subscription) Err() (err error) {
+	subscription.mutex.Lock()
+	defer subscription.mutex.Unlock()
+	return subscription.err
+}
+
+// Subscribe connects to the event stream at the URL, and streams its events
+// until the context is canceled or a connection fails with a non-retryable
+// error. Whenever the stream ends or fails with a retryable error, the
+// subscriber reconnects after the reconnection delay, sending the last event
+// ID so that the server can resume the stream.
+func (subscriber *Subscriber) Subscribe(ctx context.Context, url string) (subscription *Subscription) {
+	subscription = &Subscription{events: make(chan Event)}
+	go func() {
+		err := subscriber.run(ctx, url, subscription.events)
+		subscription.mutex.Lock()
+		subscription.err = err
+		subscription.mutex.Unlock()
+		close(subscription.events)
+	}()
+	return subscription
+}
+
+// stream contains the state of a subscription across connections.
+type stream struct {
+	// lastEventID specifies the last event ID of the stream.
+	lastEventID string
+
+	// retry specifies the reconnection delay of the retry field, if any.
+	retry time.Duration
+
+	// delivered specifies whether the current connection delivered an event.
+	delivered bool
+}
+
+// run connects to the event stream until the context is canceled or a
+// connection fails with a non-retryable error.
+func (subscriber *Subscriber) run(ctx context.Context, url string, events chan<- Event) (err error) {
+	// Check for streaming client
+	client := subscriber.client()
+	if !client.StreamResponse {
+		return ErrStreamingRequired
+	}
+
+	// Connect until canceled or failed
+	state := &stream{lastEventID: subscriber.LastEventID}
+	for attempt := 0; ; attempt++ {
+		state.delivered = false
+		err = subscriber.connect(ctx, client, url, state, events)
+		if errors.Is(err, errNoContent) {
+			return nil
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if errors.Is(err, retryable.ErrNonRetryable) {
+			return err
+		}
+
+		// Wait before reconnecting
+		if state.delivered {
+			attempt = 0
+		}
+		err = sleep(ctx, client, subscriber.reconnectDelay(client, state, attempt))
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// errNoContent indicates that the server ended the subscription.
+var errNoContent = errors.New("sse: no content")
+
+// connect sends a request for the event stream, and dispatches its events
+// until the stream ends.
+func (subscriber *Subscriber) connect(ctx context.Context, client *retryable.Client, url string, state *stream, events chan<- Event) (err error) {
+	// Construct request
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return fmt.Errorf("%w: unable to construct request: %w", retryable.ErrNonRetryable, err)
+	}
+	for name, values := range subscriber.Header {
+		request.Header[name] = append([]string(nil), values...)
+	}
+	request.Header.Set("Accept", "text/event-stream")
+	request.Header.Set("Cache-Control", "no-cache")
+	if state.lastEventID != "" {
+		request.Header.Set("Last-Event-ID", state.lastEventID)
+	}
+
+	// Send request with retries
+	response, err := client.Do(request)
+	if err != nil {
+		_ = retryable.Discard(response)
+		return err
+	}
+	defer func() {
+		_ = retryable.Discard(response)
+	}()
+
+	// Check for event stream
+	if response.StatusCode == http.StatusNoContent {
+		return errNoContent
+	}
+	mediaType, _, _ := mime.ParseMediaType(response.Header.Get("Content-Type"))
+	if mediaType != "text/event-stream" {
+		return fmt.Errorf("%w: invalid content type (%s)", retryable.ErrNonRetryable, mediaType)
+	}
+	return state.read(ctx, response.Body, events)
+}
+
+// read parses the event stream and dispatches its events, returning a
+// retryable error when the stream ends.
+func (state *stream) read(ctx context.Context, body io.Reader, events chan<- Event) (err error) {
+	reader := bufio.NewReader(body)
+	var event Event
+	var data []string
+	for {
+		// Read line without line ending
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return fmt.Errorf("%w: event stream ended: %w", retryable.ErrRetryable, err)
+		}
+		line = strings.TrimSuffix(strings.TrimSuffix(line, "\n"), "\r")
+
+		// Dispatch event on blank line
+		if line == "" {
+			if len(data) > 0 {
+				event.ID = state.lastEventID
+				if event.Type == "" {
+					event.Type = "message"
+				}
+				event.Data = strings.Join(data, "\n")
+				select {
+				case events <- event:
+					state.delivered = true
+				case <-ctx.Done():
+					return ctx.Err()
+				}
+			}
+			event, data = Event{}, nil
+			continue
+		}
+
+		// Parse field, ignoring comments
+		if strings.HasPrefix(line, ":") {
+			continue
+		}
+		name, value, _ := strings.Cut(line, ":")
+		value = strings.TrimPrefix(value, " ")
+		switch name {
+		case "event":
+			event.Type = value
+		case "data":
+			data = append(data, value)
+		case "id":
+			if !strings.Contains(value, "\x00") {
+				state.lastEventID = value
+			}
+		case "retry":
+			if milliseconds, err := strconv.ParseUint(value, 10, 63); err == nil {
+				state.retry = time.Duration(milliseconds) * time.Millisecond
+			}
+		}
+	}
+}
+
+// client returns the retryable HTTP client.
+func (subscriber *Subscriber) client() *retryable.Client {
+	if subscriber.Client != nil {
+		return subscriber.Client
+	}
+	client := new(retryable.Client)
+	client.RetryStatus = retryable.DefaultStatus
+	client.RetryCount = 3
+	client.RetryDelay = time.Second
+	client.RetryMultiplier = 2.0
+	client.RetryJitter = 0.5
+	client.MaxRetryDelay = time.Minute
+	client.StreamResponse = true
+	return client
+}
+
+// reconnectDelay returns the delay before reconnecting, which is the delay of
+// the retry field if specified, or an exponential backoff otherwise.
+func (subscriber *Subscriber) reconnectDelay(client *retryable.Client, state *stream, attempt int) (duration time.Duration) {
+	// Check for retry field
+	if state.retry > 0 {
+		return state.retry
+	}
+
+	// Apply exponential backoff with random jitter
+	policy := client.Policy()
+	duration = subscriber.ReconnectDelay
+	if duration <= 0 {
+		duration = policy.RetryDelay
+	}
+	multiplier := math.Max(policy.RetryMultiplier, 1.0)
+	duration = delay.RandomJitter(delay.ExponentialBackoff(duration, multiplier, attempt), policy.RetryJitter)
+	if policy.MaxRetryDelay > 0 && duration > policy.MaxRetryDelay {
+		duration = policy.MaxRetryDelay
+	}
+	return duration
+}
+
+// sleep pauses for the duration with the clock of the client, or until the
+// context is canceled.
+func sleep(ctx context.Context, client *retryable.Client, duration time.Duration) (err error) {
+	if client.Clock != nil {
+		return client.Clock.Sleep(ctx, duration)
+	}
+	timer := time.NewTimer(duration)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
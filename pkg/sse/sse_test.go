@@ -0,0 +1,114 @@
+package sse
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/cholland1989/go-retryable/pkg/retryable"
+	"github.com/stretchr/testify/require"
+)
+
+type mockClock struct {
+	mutex  sync.Mutex
+	sleeps []time.Duration
+}
+
+func (clock *mockClock) Now() time.Time {
+	return time.Now()
+}
+
+func (clock *mockClock) Sleep(ctx context.Context, duration time.Duration) error {
+	clock.mutex.Lock()
+	defer clock.mutex.Unlock()
+	clock.sleeps = append(clock.sleeps, duration)
+	return ctx.Err()
+}
+
+func TestSubscriber_Subscribe(test *testing.T) {
+	test.Parallel()
+
+	var mutex sync.Mutex
+	var lastEventIDs []string
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		mutex.Lock()
+		connection := len(lastEventIDs)
+		lastEventIDs = append(lastEventIDs, request.Header.Get("Last-Event-ID"))
+		mutex.Unlock()
+		switch connection {
+		case 0:
+			writer.Header().Set("Content-Type", "text/event-stream")
+			_, _ = fmt.Fprint(writer, ": comment\nretry: 250\nid: 1\ndata: first\ndata: line\n\nevent: update\ndata: second\r\n\r\n")
+		case 1:
+			writer.WriteHeader(http.StatusServiceUnavailable)
+		case 2:
+			writer.Header().Set("Content-Type", "text/event-stream; charset=utf-8")
+			_, _ = fmt.Fprint(writer, "id: 2\ndata: third\n\nid\n\n")
+		default:
+			writer.WriteHeader(http.StatusNoContent)
+		}
+	}))
+	defer server.Close()
+
+	clock := new(mockClock)
+	subscriber := &Subscriber{
+		Client: &retryable.Client{
+			RetryStatus:    []int{http.StatusServiceUnavailable},
+			StreamResponse: true,
+			Clock:          clock,
+		},
+		LastEventID: "0",
+	}
+	subscription := subscriber.Subscribe(context.Background(), server.URL)
+	var events []Event
+	for event := range subscription.Events() {
+		events = append(events, event)
+	}
+	require.NoError(test, subscription.Err())
+	require.Equal(test, []Event{
+		{ID: "1", Type: "message", Data: "first\nline"},
+		{ID: "1", Type: "update", Data: "second"},
+		{ID: "2", Type: "message", Data: "third"},
+	}, events)
+	require.Equal(test, []string{"0", "1", "1", ""}, lastEventIDs)
+	var delays []time.Duration
+	for _, duration := range clock.sleeps {
+		if duration > 0 {
+			delays = append(delays, duration)
+		}
+	}
+	require.Equal(test, []time.Duration{250 * time.Millisecond, 250 * time.Millisecond, 250 * time.Millisecond}, delays)
+}
+
+func TestSubscriber_Errors(test *testing.T) {
+	test.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		writer.Header().Set("Content-Type", "application/json")
+	}))
+	defer server.Close()
+
+	subscription := (&Subscriber{Client: new(retryable.Client)}).Subscribe(context.Background(), server.URL)
+	for range subscription.Events() {
+		test.Fatal("unexpected event")
+	}
+	require.ErrorIs(test, subscription.Err(), ErrStreamingRequired)
+
+	subscription = (&Subscriber{Client: &retryable.Client{StreamResponse: true}}).Subscribe(context.Background(), server.URL)
+	for range subscription.Events() {
+		test.Fatal("unexpected event")
+	}
+	require.ErrorIs(test, subscription.Err(), retryable.ErrNonRetryable)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	subscription = new(Subscriber).Subscribe(ctx, server.URL)
+	for range subscription.Events() {
+		test.Fatal("unexpected event")
+	}
+	require.ErrorIs(test, subscription.Err(), context.Canceled)
+}
@@ -0,0 +1,102 @@
+package unofficial
+
+import (
+	"strings"
+)
+
+// Status defines a well-known HTTP status code that is not part of the
+// official specification, and the vendor that uses it.
+type Status struct {
+	// Code specifies the status code.
+	Code int
+
+	// Name specifies the human-readable name of the status code.
+	Name string
+
+	// Origin specifies the vendor or software that uses the status code.
+	Origin string
+
+	// Description specifies the condition signaled by the status code.
+	Description string
+}
+
+// statuses contains the well-known status codes in ascending order.
+var statuses = []Status{
+	{StatusThisIsFine, "This Is Fine", "Apache", "catch-all error condition allowing the passage of message bodies when ProxyErrorOverride is enabled"},
+	{StatusPageExpired, "Page Expired", "Laravel", "CSRF token is missing or expired"},
+	{StatusMethodFailure, "Method Failure", "Spring", "method has failed"},
+	{StatusEnhanceYourCalm, "Enhance Your Calm", "Twitter", "client is being rate limited"},
+	{StatusRequestHeaderFieldsTooLarge, "Request Header Fields Too Large", "Shopify", "too many URLs are requested within a certain time frame"},
+	{StatusLoginTimeout, "Login Time-out", "IIS", "client's session has expired and must log in again"},
+	{StatusNoResponse, "No Response", "NGINX", "server returns no information and closes the connection"},
+	{StatusRetryWith, "Retry With", "IIS", "user has not provided the required information"},
+	{StatusBlockedByWindowsParentalControls, "Blocked by Windows Parental Controls", "Windows Parental Controls", "access to the requested webpage is blocked"},
+	{StatusRedirect, "Redirect", "Exchange ActiveSync", "a more efficient server is available or the server cannot access the mailbox"},
+	{StatusClientClosedConnection, "Client Closed Connection", "AWS Elastic Load Balancing", "client closed the connection before the idle timeout elapsed"},
+	{StatusXForwardedForTooLarge, "X-Forwarded-For Too Large", "AWS Elastic Load Balancing", "X-Forwarded-For header contains more than 30 IP addresses"},
+	{StatusIncompatibleProtocolVersions, "Incompatible Protocol Versions", "AWS Elastic Load Balancing", "client and origin server use incompatible protocol versions"},
+	{StatusRequestHeaderTooLarge, "Request Header Too Large", "NGINX", "request or header is too large"},
+	{StatusSSLCertificateError, "SSL Certificate Error", "NGINX", "client certificate is invalid"},
+	{StatusSSLCertificateRequired, "SSL Certificate Required", "NGINX", "client certificate is required but not provided"},
+	{StatusHTTPRequestSentToHTTPSPort, "HTTP Request Sent to HTTPS Port", "NGINX", "HTTP request was sent to a port listening for HTTPS requests"},
+	{StatusInvalidToken, "Invalid Token", "ArcGIS", "token is expired or otherwise invalid"},
+	{StatusTokenRequired, "Token Required", "ArcGIS", "token is required but was not submitted"},
+	{StatusClientClosedRequest, "Client Closed Request", "NGINX", "client closed the request before the server could respond"},
+	{StatusBandwidthLimitExceeded, "Bandwidth Limit Exceeded", "Apache", "server has exceeded the bandwidth specified by the administrator"},
+	{StatusWebServerReturnedAnUnknownError, "Web Server Returned an Unknown Error", "Cloudflare", "origin server returned an empty, unknown, or unexpected response"},
+	{StatusWebServerIsDown, "Web Server Is Down", "Cloudflare", "origin server refused the connection"},
+	{StatusConnectionTimedOut, "Connection Timed Out", "Cloudflare", "connection to the origin server timed out"},
+	{StatusOriginIsUnreachable, "Origin Is Unreachable", "Cloudflare", "origin server could not be reached"},
+	{StatusTimeoutOccurred, "A Timeout Occurred", "Cloudflare", "origin server did not respond in time"},
+	{StatusSSLHandshakeFailed, "SSL Handshake Failed", "Cloudflare", "SSL/TLS handshake with the origin server failed"},
+	{StatusInvalidSSLCertificate, "Invalid SSL Certificate", "Cloudflare", "SSL certificate of the origin server could not be validated"},
+	{StatusRailgunError, "Railgun Error", "Cloudflare", "connection to the Railgun server of the origin was interrupted"},
+	{StatusSiteIsOverloaded, "Site Is Overloaded", "Qualys", "site cannot process the request"},
+	{StatusSiteIsFrozen, "Site Is Frozen", "Pantheon", "site has been frozen due to inactivity"},
+	{StatusCloudflareError, "Cloudflare Error", "Cloudflare", "Cloudflare returned a 1xxx error"},
+	{StatusUnauthorized, "Unauthorized", "AWS Elastic Load Balancing", "identity provider returned an error when authenticating the user"},
+	{StatusNetworkReadTimeout, "Network Read Timeout Error", "HTTP proxies", "network read timed out behind the proxy"},
+	{StatusNetworkConnectTimeout, "Network Connect Timeout Error", "HTTP proxies", "network connect timed out behind the proxy"},
+}
+
+// CloudflareStatuses contains the status codes used by Cloudflare, which mostly
+// signal a failure to reach the origin server behind Cloudflare.
+var CloudflareStatuses = vendorStatuses("Cloudflare")
+
+// AWSELBStatuses contains the status codes used by AWS Elastic Load Balancing.
+var AWSELBStatuses = vendorStatuses("AWS Elastic Load Balancing")
+
+// NginxStatuses contains the status codes used by NGINX, most of which signal
+// client errors that are not worth retrying.
+var NginxStatuses = vendorStatuses("NGINX")
+
+// vendorStatuses returns the status codes used by the vendor in ascending
+// order.
+func vendorStatuses(origin string) (codes []int) {
+	for _, status := range statuses {
+		if status.Origin == origin {
+			codes = append(codes, status.Code)
+		}
+	}
+	return codes
+}
+
+// Statuses returns the well-known status codes in ascending order. Status
+// codes used by several vendors for different conditions, such as 420 and
+// 499, are listed once per vendor.
+func Statuses() []Status {
+	return append([]Status(nil), statuses...)
+}
+
+// StatusText returns the name of the status code, or the empty string if the
+// status code is unknown. If several vendors use the status code for
+// different conditions, the names are joined with slashes.
+func StatusText(code int) string {
+	var names []string
+	for _, status := range statuses {
+		if status.Code == code {
+			names = append(names, status.Name)
+		}
+	}
+	return strings.Join(names, " / ")
+}
@@ -1 +1,41 @@
 package unofficial
+
+import (
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestStatuses(test *testing.T) {
+	test.Parallel()
+
+	result := Statuses()
+	require.Len(test, result, 35)
+	require.True(test, sort.SliceIsSorted(result, func(i int, j int) bool {
+		return result[i].Code < result[j].Code
+	}))
+	for _, status := range result {
+		require.NotEmpty(test, status.Name)
+		require.NotEmpty(test, status.Origin)
+		require.NotEmpty(test, status.Description)
+	}
+	result[0].Name = "changed"
+	require.Equal(test, "This Is Fine", Statuses()[0].Name)
+}
+
+func TestStatusText(test *testing.T) {
+	test.Parallel()
+
+	require.Equal(test, "Web Server Is Down", StatusText(StatusWebServerIsDown))
+	require.Equal(test, "Token Required / Client Closed Request", StatusText(499))
+	require.Empty(test, StatusText(200))
+}
+
+func TestVendorStatuses(test *testing.T) {
+	test.Parallel()
+
+	require.Equal(test, []int{520, 521, 522, 523, 524, 525, 526, 527, 530}, CloudflareStatuses)
+	require.Equal(test, []int{460, 463, 464, 561}, AWSELBStatuses)
+	require.Equal(test, []int{444, 494, 495, 496, 497, 499}, NginxStatuses)
+}
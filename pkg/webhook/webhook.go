@@ -0,0 +1,198 @@
+// Package webhook provides a webhook sender built on a retryable HTTP client,
+// which signs payloads with HMAC-SHA256, retries failed deliveries, and hands
+// deliveries that ultimately failed to a dead-letter callback.
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cholland1989/go-retryable/pkg/retryable"
+)
+
+// DefaultSignatureHeader is the default name of the signature header.
+const DefaultSignatureHeader = "Webhook-Signature"
+
+// ErrInvalidSignature defines an error for signatures that do not match the
+// payload, or whose timestamp differs from the current time by more than the
+// tolerance.
+var ErrInvalidSignature = errors.New("webhook: invalid signature")
+
+// Delivery defines a webhook payload and its destination.
+type Delivery struct {
+	// ID specifies an optional unique identifier of the delivery, which is
+	// sent in the Webhook-Id header so that receivers can deduplicate
+	// deliveries that were retried.
+	ID string
+
+	// URL specifies the URL of the receiver.
+	URL string
+
+	// Payload specifies the body of the delivery.
+	Payload []byte
+
+	// Header specifies additional request headers.
+	Header http.Header
+}
+
+// Sender delivers signed webhooks with a retryable HTTP client.
+type Sender struct {
+	// Client specifies the retryable HTTP client. If the client is nil,
+	// [retryable.Default] is used.
+	Client *retryable.Client
+
+	// Secret specifies the key used to sign payloads with HMAC-SHA256.
+	Secret []byte
+
+	// SignatureHeader specifies the name of the signature header. If the name
+	// is empty, [DefaultSignatureHeader] is used.
+	SignatureHeader string
+
+	// ContentType specifies the content type of payloads. If the content type
+	// is empty, "application/json" is used.
+	ContentType string
+
+	// Clock specifies the time source for signature timestamps. If the clock
+	// is nil, the system time is used.
+	Clock retryable.Clock
+
+	// DeadLetter specifies an optional function that is called with each
+	// delivery that failed after all retries, such as to persist it for
+	// manual replay.
+	DeadLetter func(delivery Delivery, err error)
+}
+
+// Send signs the payload and posts it to the receiver, retrying failed
+// attempts with the policy of the retryable client. If the delivery fails,
+// the dead-letter callback is called and the error is returned. Each attempt
+// is signed with the current time, so that retries remain within the
+// tolerance of the receiver.
+func (sender *Sender) Send(ctx context.Context, delivery Delivery) (err error) {
+	// Hand failed deliveries to the dead-letter callback
+	defer func() {
+		if err != nil && sender.DeadLetter != nil {
+			sender.DeadLetter(delivery, err)
+		}
+	}()
+
+	// Construct signed request
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, delivery.URL, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		return fmt.Errorf("%w: unable to construct request: %w", retryable.ErrNonRetryable, err)
+	}
+	for name, values := range delivery.Header {
+		request.Header[name] = append([]string(nil), values...)
+	}
+	request.Header.Set("Content-Type", sender.contentType())
+	if delivery.ID != "" {
+		request.Header.Set("Webhook-Id", delivery.ID)
+	}
+	request = request.WithContext(retryable.WithSigner(ctx, retryable.SignerFunc(func(request *http.Request) error {
+		request.Header.Set(sender.signatureHeader(), Sign(sender.Secret, sender.now(), delivery.Payload))
+		return nil
+	})))
+
+	// Send request, discarding the response body
+	response, err := sender.client().Do(request)
+	if err != nil {
+		return err
+	}
+	_, _ = io.Copy(io.Discard, response.Body)
+	_ = response.Body.Close()
+	return nil
+}
+
+// client returns the retryable HTTP client.
+func (sender *Sender) client() *retryable.Client {
+	if sender.Client == nil {
+		return retryable.Default()
+	}
+	return sender.Client
+}
+
+// signatureHeader returns the name of the signature header.
+func (sender *Sender) signatureHeader() string {
+	if sender.SignatureHeader == "" {
+		return DefaultSignatureHeader
+	}
+	return sender.SignatureHeader
+}
+
+// contentType returns the content type of payloads.
+func (sender *Sender) contentType() string {
+	if sender.ContentType == "" {
+		return "application/json"
+	}
+	return sender.ContentType
+}
+
+// now returns the current time of the clock.
+func (sender *Sender) now() time.Time {
+	if sender.Clock == nil {
+		return time.Now()
+	}
+	return sender.Clock.Now()
+}
+
+// Sign returns the signature of the payload at the specified time, in the
+// format "t=<unix seconds>,v1=<hex HMAC-SHA256 of timestamp.payload>".
+func Sign(secret []byte, timestamp time.Time, payload []byte) string {
+	seconds := strconv.FormatInt(timestamp.Unix(), 10)
+	return "t=" + seconds + ",v1=" + hex.EncodeToString(signature(secret, seconds, payload))
+}
+
+// Verify checks the signature of the payload, and that its timestamp is
+// within the tolerance of the specified time, in either direction. If the
+// tolerance is zero, the timestamp is not checked.
+func Verify(secret []byte, header string, payload []byte, tolerance time.Duration, now time.Time) (err error) {
+	// Parse signature header
+	var seconds string
+	var signatures [][]byte
+	for _, part := range strings.Split(header, ",") {
+		key, value, _ := strings.Cut(strings.TrimSpace(part), "=")
+		switch key {
+		case "t":
+			seconds = value
+		case "v1":
+			decoded, err := hex.DecodeString(value)
+			if err == nil {
+				signatures = append(signatures, decoded)
+			}
+		}
+	}
+	unix, err := strconv.ParseInt(seconds, 10, 64)
+	if err != nil || len(signatures) == 0 {
+		return fmt.Errorf("%w: malformed header", ErrInvalidSignature)
+	}
+
+	// Check timestamp and signature
+	if age := now.Sub(time.Unix(unix, 0)); tolerance > 0 && (age > tolerance || age < -tolerance) {
+		return fmt.Errorf("%w: timestamp outside tolerance", ErrInvalidSignature)
+	}
+	expected := signature(secret, seconds, payload)
+	for _, candidate := range signatures {
+		if hmac.Equal(expected, candidate) {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: signature mismatch", ErrInvalidSignature)
+}
+
+// signature returns the HMAC-SHA256 of the timestamp and payload.
+func signature(secret []byte, seconds string, payload []byte) []byte {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(seconds))
+	mac.Write([]byte("."))
+	mac.Write(payload)
+	return mac.Sum(nil)
+}
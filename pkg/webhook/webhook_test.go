@@ -0,0 +1,123 @@
+package webhook
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/cholland1989/go-retryable/pkg/retryable"
+	"github.com/stretchr/testify/require"
+)
+
+type fixedClock struct {
+	now time.Time
+}
+
+func (clock fixedClock) Now() time.Time {
+	return clock.now
+}
+
+func (clock fixedClock) Sleep(context.Context, time.Duration) error {
+	return nil
+}
+
+func TestSender_Send(test *testing.T) {
+	test.Parallel()
+
+	secret := []byte("secret")
+	now := time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC)
+	var attempts atomic.Int32
+	verified := make(chan error, 4)
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		payload, _ := io.ReadAll(request.Body)
+		verified <- Verify(secret, request.Header.Get(DefaultSignatureHeader), payload, time.Minute, now)
+		if request.Header.Get("Webhook-Id") != "evt_1" || request.Header.Get("X-Tenant") != "acme" {
+			writer.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		if attempts.Add(1) == 1 {
+			writer.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer server.Close()
+
+	var dead []Delivery
+	sender := &Sender{
+		Client:     &retryable.Client{RetryCount: 2, RetryStatus: []int{http.StatusServiceUnavailable}},
+		Secret:     secret,
+		Clock:      fixedClock{now: now},
+		DeadLetter: func(delivery Delivery, err error) { dead = append(dead, delivery) },
+	}
+	delivery := Delivery{ID: "evt_1", URL: server.URL, Payload: []byte(`{"event":"created"}`), Header: http.Header{"X-Tenant": {"acme"}}}
+	require.NoError(test, sender.Send(context.Background(), delivery))
+	require.Equal(test, int32(2), attempts.Load())
+	require.NoError(test, <-verified)
+	require.NoError(test, <-verified)
+	require.Empty(test, dead)
+
+	delivery.ID = "evt_2"
+	err := sender.Send(context.Background(), delivery)
+	require.ErrorIs(test, err, retryable.ErrNonRetryable)
+	require.Equal(test, []Delivery{delivery}, dead)
+
+	delivery.URL = "://invalid"
+	require.ErrorIs(test, sender.Send(context.Background(), delivery), retryable.ErrNonRetryable)
+	require.Len(test, dead, 2)
+}
+
+type steppingClock struct {
+	start time.Time
+	calls atomic.Int64
+}
+
+func (clock *steppingClock) Now() time.Time {
+	return clock.start.Add(time.Duration(clock.calls.Add(1)-1) * time.Hour)
+}
+
+func (clock *steppingClock) Sleep(context.Context, time.Duration) error {
+	return nil
+}
+
+func TestSender_Send_Resign(test *testing.T) {
+	test.Parallel()
+
+	secret := []byte("secret")
+	start := time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC)
+	var timestamps []string
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		header := request.Header.Get(DefaultSignatureHeader)
+		timestamps = append(timestamps, header[:len("t=1704067200")])
+		writer.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	sender := &Sender{
+		Client: &retryable.Client{RetryCount: 2, RetryStatus: []int{http.StatusServiceUnavailable}},
+		Secret: secret,
+		Clock:  &steppingClock{start: start},
+	}
+	err := sender.Send(context.Background(), Delivery{URL: server.URL, Payload: []byte("{}")})
+	require.ErrorIs(test, err, retryable.ErrRetryable)
+	require.Equal(test, []string{"t=1704067200", "t=1704070800", "t=1704074400"}, timestamps)
+}
+
+func TestVerify(test *testing.T) {
+	test.Parallel()
+
+	secret := []byte("secret")
+	now := time.Unix(1700000000, 0)
+	header := Sign(secret, now, []byte("payload"))
+	require.Regexp(test, `^t=1700000000,v1=[0-9a-f]{64}$`, header)
+	require.NoError(test, Verify(secret, header, []byte("payload"), time.Minute, now.Add(time.Second)))
+	require.NoError(test, Verify(secret, header, []byte("payload"), 0, now.Add(time.Hour)))
+	require.ErrorIs(test, Verify(secret, header, []byte("tampered"), time.Minute, now), ErrInvalidSignature)
+	require.ErrorIs(test, Verify([]byte("other"), header, []byte("payload"), time.Minute, now), ErrInvalidSignature)
+	require.ErrorIs(test, Verify(secret, header, []byte("payload"), time.Minute, now.Add(time.Hour)), ErrInvalidSignature)
+	require.ErrorIs(test, Verify(secret, header, []byte("payload"), time.Minute, now.Add(-time.Hour)), ErrInvalidSignature)
+	require.NoError(test, Verify(secret, header, []byte("payload"), time.Minute, now.Add(-time.Second)))
+	require.ErrorIs(test, Verify(secret, "v1=abc", []byte("payload"), 0, now), ErrInvalidSignature)
+}